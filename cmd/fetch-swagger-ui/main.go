@@ -0,0 +1,129 @@
+// Command fetch-swagger-ui downloads the pinned version of swagger-ui-dist into v1/openapi/swagger-ui,
+// where it is embedded into the server, so the API docs do not load scripts from a CDN.
+//
+//	go generate ./v1/openapi
+//
+// The version is read from v1/openapi/swagger-ui/VERSION. The package is checked against the sha512
+// integrity of the npm registry before the assets are extracted.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const registryUrl = "https://registry.npmjs.org/swagger-ui-dist"
+
+// assets are the files of the package that the docs page needs
+var assets = []string{"swagger-ui.css", "swagger-ui-bundle.js", "LICENSE"}
+
+func main() {
+	dir := flag.String("dir", "v1/openapi/swagger-ui", "directory with the VERSION file to extract the assets to")
+	flag.Parse()
+
+	if err := fetch(*dir); err != nil {
+		slog.Error("Failed to fetch swagger-ui-dist", "error", err)
+		os.Exit(1)
+	}
+}
+
+func fetch(dir string) error {
+	version, err := os.ReadFile(filepath.Join(dir, "VERSION"))
+	if err != nil {
+		return err
+	}
+	pinned := strings.TrimSpace(string(version))
+
+	httpClient := &http.Client{Timeout: time.Minute}
+
+	var metadata struct {
+		Dist struct {
+			Tarball   string `json:"tarball"`
+			Integrity string `json:"integrity"`
+		} `json:"dist"`
+	}
+	body, err := get(httpClient, registryUrl+"/"+pinned)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return fmt.Errorf("invalid registry metadata of %s: %w", pinned, err)
+	}
+
+	tarball, err := get(httpClient, metadata.Dist.Tarball)
+	if err != nil {
+		return err
+	}
+
+	sum := sha512.Sum512(tarball)
+	if integrity := "sha512-" + base64.StdEncoding.EncodeToString(sum[:]); integrity != metadata.Dist.Integrity {
+		return fmt.Errorf("swagger-ui-dist %s has integrity %s, the registry has %s", pinned, integrity, metadata.Dist.Integrity)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return err
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	extracted := 0
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// The files of npm packages are below package/
+		name := path.Base(header.Name)
+		if header.Typeflag != tar.TypeReg || path.Dir(header.Name) != "package" || !slices.Contains(assets, name) {
+			continue
+		}
+
+		contents, err := io.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0o644); err != nil {
+			return err
+		}
+		extracted++
+	}
+
+	if extracted != len(assets) {
+		return fmt.Errorf("swagger-ui-dist %s contains %d of the %d assets", pinned, extracted, len(assets))
+	}
+
+	slog.Info("Fetched swagger-ui-dist", "version", pinned, "dir", dir)
+	return nil
+}
+
+func get(httpClient *http.Client, url string) ([]byte, error) {
+	response, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s answered %d", url, response.StatusCode)
+	}
+	return io.ReadAll(response.Body)
+}
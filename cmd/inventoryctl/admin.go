@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/maintenance"
+)
+
+func (c *cli) runPurge(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	retentionDays := flags.Int("retention-days", maintenance.DefaultRetentionDays, "purge the records older than this many days")
+	dryRun := flags.Bool("dry-run", false, "only count the records to purge")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *retentionDays < 0 {
+		return errors.New("-retention-days must be at least 0")
+	}
+
+	result, err := c.backend.Purge(ctx, *retentionDays, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	headers := []string{"RECORDS", "PURGED"}
+	if result.DryRun {
+		headers[1] = "TO PURGE"
+	}
+
+	return c.print(result, headers, [][]string{
+		{"items", strconv.FormatInt(result.Items, 10)},
+		{"suppliers", strconv.FormatInt(result.Suppliers, 10)},
+		{"outbox events", strconv.FormatInt(result.OutboxEvents, 10)},
+		{"webhook deliveries", strconv.FormatInt(result.WebhookDeliveries, 10)},
+	})
+}
+
+// runMigrate applies or lists the migrations of supabase/migrations with the Supabase CLI, against the linked
+// project or the database of -db-url. It does not use the API or the service layer.
+func (c *cli) runMigrate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dbUrl := flags.String("db-url", os.Getenv("DATABASE_URL"), "Postgres connection string, defaults to DATABASE_URL, the linked project when empty")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	action := "up"
+	if len(positional) > 0 {
+		action = positional[0]
+	}
+
+	var supabaseArgs []string
+	switch action {
+	case "up":
+		supabaseArgs = []string{"db", "push"}
+	case "status":
+		supabaseArgs = []string{"migration", "list"}
+	default:
+		return fmt.Errorf("unknown migrate action %q, must be up or status", action)
+	}
+
+	if *dbUrl != "" {
+		supabaseArgs = append(supabaseArgs, "--db-url", *dbUrl)
+	} else {
+		supabaseArgs = append(supabaseArgs, "--linked")
+	}
+
+	command := exec.CommandContext(ctx, "supabase", supabaseArgs...)
+	command.Stdin = os.Stdin
+	command.Stdout = c.stdout
+	command.Stderr = c.stderr
+
+	if err := command.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return errors.New("the Supabase CLI is needed to run migrations: https://supabase.com/docs/guides/cli")
+		}
+		return fmt.Errorf("supabase %s failed: %w", action, err)
+	}
+
+	return nil
+}
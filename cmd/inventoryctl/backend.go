@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/client"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/items"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/maintenance"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/suppliers"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+// backend runs the commands, either through the API or directly with the service layer
+type backend interface {
+	ListItems(ctx context.Context, options client.ItemListOptions) (client.ItemPage, error)
+	SearchItems(ctx context.Context, query string, options client.ItemSearchOptions) (client.ItemSearchPage, error)
+	GetItem(ctx context.Context, id int8) (schemas.Item, error)
+	CreateItem(ctx context.Context, item schemas.Item) (schemas.Item, error)
+	UpdateItem(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Item, error)
+
+	ListSuppliers(ctx context.Context, name string) ([]schemas.Supplier, error)
+	GetSupplier(ctx context.Context, id int8) (schemas.Supplier, error)
+	CreateSupplier(ctx context.Context, supplier schemas.Supplier) (schemas.Supplier, error)
+	UpdateSupplier(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Supplier, error)
+
+	Purge(ctx context.Context, retentionDays int, dryRun bool) (schemas.PurgeResult, error)
+}
+
+// allItems iterates over every item from the cursor of the options. The iteration stops after the first error.
+func allItems(ctx context.Context, b backend, options client.ItemListOptions) iter.Seq2[schemas.Item, error] {
+	return func(yield func(schemas.Item, error) bool) {
+		for {
+			page, err := b.ListItems(ctx, options)
+			if err != nil {
+				yield(schemas.Item{}, err)
+				return
+			}
+
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if page.NextCursor == nil {
+				return
+			}
+			options.Cursor = *page.NextCursor
+		}
+	}
+}
+
+// apiBackend sends the commands to the API
+type apiBackend struct {
+	api *client.Client
+}
+
+func (b apiBackend) ListItems(ctx context.Context, options client.ItemListOptions) (client.ItemPage, error) {
+	return b.api.Items.List(ctx, options)
+}
+
+func (b apiBackend) SearchItems(ctx context.Context, query string, options client.ItemSearchOptions) (client.ItemSearchPage, error) {
+	return b.api.Items.Search(ctx, query, options)
+}
+
+func (b apiBackend) GetItem(ctx context.Context, id int8) (schemas.Item, error) {
+	return b.api.Items.Get(ctx, id)
+}
+
+func (b apiBackend) CreateItem(ctx context.Context, item schemas.Item) (schemas.Item, error) {
+	return b.api.Items.Create(ctx, item)
+}
+
+func (b apiBackend) UpdateItem(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Item, error) {
+	return b.api.Items.Update(ctx, id, updates)
+}
+
+func (b apiBackend) ListSuppliers(ctx context.Context, name string) ([]schemas.Supplier, error) {
+	return b.api.Suppliers.List(ctx, name)
+}
+
+func (b apiBackend) GetSupplier(ctx context.Context, id int8) (schemas.Supplier, error) {
+	return b.api.Suppliers.Get(ctx, id)
+}
+
+func (b apiBackend) CreateSupplier(ctx context.Context, supplier schemas.Supplier) (schemas.Supplier, error) {
+	return b.api.Suppliers.Create(ctx, supplier)
+}
+
+func (b apiBackend) UpdateSupplier(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Supplier, error) {
+	return b.api.Suppliers.Update(ctx, id, updates)
+}
+
+func (b apiBackend) Purge(ctx context.Context, retentionDays int, dryRun bool) (schemas.PurgeResult, error) {
+	return b.api.Maintenance.Purge(ctx, retentionDays, dryRun)
+}
+
+// directBackend calls the service layer, which connects to the database with SUPABASE_URL and
+// SUPABASE_SECRET_KEY from the .env file. It applies the checks of the handlers that the services leave out.
+type directBackend struct{}
+
+func (directBackend) ListItems(ctx context.Context, options client.ItemListOptions) (client.ItemPage, error) {
+	limit := options.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	if !utils.InRange(limit, 1, 100) {
+		return client.ItemPage{}, &schemas.CustomError{Code: http.StatusBadRequest, Message: "Invalid limit, must be between 1 and 100"}
+	}
+
+	sortBy := options.SortBy
+	if sortBy == "" {
+		sortBy = "name"
+	}
+
+	foundItems, page, err := items.GetCursorItems(options.Cursor, limit, sortBy, !options.SortDesc, options.WithCount, options.Filters)
+	if err != nil {
+		return client.ItemPage{}, err
+	}
+
+	return client.ItemPage{CursorPage: page, Items: foundItems}, nil
+}
+
+func (directBackend) SearchItems(ctx context.Context, query string, options client.ItemSearchOptions) (client.ItemSearchPage, error) {
+	page := max(options.Page, 1)
+	pageSize := options.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	results, count, err := items.PagedItemSearch(query, options.Filters, page, pageSize)
+	if err != nil {
+		return client.ItemSearchPage{}, err
+	}
+
+	searchPage := client.ItemSearchPage{Page: page, PageSize: pageSize, Results: results}
+	if count != nil {
+		searchPage.Count = *count
+	}
+
+	if options.WithFacets {
+		facets, err := items.GetItemSearchFacets(query, options.Filters)
+		if err != nil {
+			return client.ItemSearchPage{}, err
+		}
+		searchPage.Facets = &facets
+	}
+
+	return searchPage, nil
+}
+
+func (directBackend) GetItem(ctx context.Context, id int8) (schemas.Item, error) {
+	return items.GetItem(id)
+}
+
+func (directBackend) CreateItem(ctx context.Context, item schemas.Item) (schemas.Item, error) {
+	return items.CreateItem(item)
+}
+
+func (directBackend) UpdateItem(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Item, error) {
+	utils.RemoveProtectedFields(updates, items.ProtectedFields)
+	return items.UpdateItem(id, updates)
+}
+
+func (directBackend) ListSuppliers(ctx context.Context, name string) ([]schemas.Supplier, error) {
+	return suppliers.GetSuppliers(name)
+}
+
+func (directBackend) GetSupplier(ctx context.Context, id int8) (schemas.Supplier, error) {
+	return suppliers.GetSupplier(id)
+}
+
+func (directBackend) CreateSupplier(ctx context.Context, supplier schemas.Supplier) (schemas.Supplier, error) {
+	return suppliers.CreateSupplier(supplier)
+}
+
+func (directBackend) UpdateSupplier(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Supplier, error) {
+	utils.RemoveProtectedFields(updates, suppliers.ProtectedFields)
+	if len(updates) == 0 {
+		return schemas.Supplier{}, &schemas.CustomError{Code: http.StatusBadRequest, Message: "No valid fields to update"}
+	}
+	return suppliers.UpdateSupplier(id, updates)
+}
+
+func (directBackend) Purge(ctx context.Context, retentionDays int, dryRun bool) (schemas.PurgeResult, error) {
+	return maintenance.Purge(retentionDays, dryRun)
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// The columns of the CSV files. Rows with an ID update that record, and rows without one create a record.
+// Empty cells are left unchanged when updating. Tags are separated by semicolons and attributes are a JSON object.
+// Text that a spreadsheet would run as a formula is exported with a leading apostrophe, which the import removes.
+// The quantity of an item is only imported when creating it, as stock is changed through stock movements.
+var (
+	itemCSVColumns     = []string{"id", "sku", "name", "description", "quantity", "purchase_price", "purchase_currency", "category_id", "supplier_id", "location", "base_unit", "tags", "attributes"}
+	supplierCSVColumns = []string{"id", "name", "website", "address", "vat_number"}
+)
+
+// tagSeparator separates the tags in the tags column
+const tagSeparator = ";"
+
+// formulaPrefixes start a formula when a cell is opened in a spreadsheet
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeCell prefixes text that a spreadsheet would run as a formula with an apostrophe, which spreadsheets
+// hide and which unescapeCell removes on import. Text that starts with an apostrophe gets one as well,
+// so it is imported unchanged.
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes+"'", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCell removes the apostrophe that escapeCell added
+func unescapeCell(value string) string {
+	return strings.TrimPrefix(value, "'")
+}
+
+func exportItemsCSV(output io.Writer, items iter.Seq2[schemas.Item, error]) (int, error) {
+	writer := csv.NewWriter(output)
+	if err := writer.Write(itemCSVColumns); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for item, err := range items {
+		if err != nil {
+			return count, err
+		}
+
+		attributes := ""
+		if len(item.Attributes) > 0 {
+			encoded, err := json.Marshal(item.Attributes)
+			if err != nil {
+				return count, fmt.Errorf("failed to encode the attributes of item %d: %w", item.Id, err)
+			}
+			attributes = string(encoded)
+		}
+
+		categoryId := ""
+		if item.CategoryId != nil {
+			categoryId = strconv.Itoa(int(*item.CategoryId))
+		}
+
+		err := writer.Write([]string{
+			strconv.Itoa(int(item.Id)),
+			escapeCell(stringOrEmpty(item.Sku)),
+			escapeCell(item.Name),
+			escapeCell(item.Description),
+			strconv.Itoa(item.Quantity),
+			strconv.FormatFloat(item.PurchasePrice, 'f', -1, 64),
+			item.PurchaseCurrency,
+			categoryId,
+			strconv.Itoa(int(item.SupplierId)),
+			escapeCell(stringOrEmpty(item.Location)),
+			escapeCell(item.BaseUnit),
+			escapeCell(strings.Join(item.Tags, tagSeparator)),
+			escapeCell(attributes),
+		})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+func exportSuppliersCSV(output io.Writer, suppliers []schemas.Supplier) error {
+	writer := csv.NewWriter(output)
+	if err := writer.Write(supplierCSVColumns); err != nil {
+		return err
+	}
+
+	for _, supplier := range suppliers {
+		err := writer.Write([]string{
+			strconv.Itoa(int(supplier.Id)),
+			escapeCell(supplier.Name),
+			escapeCell(supplier.Website),
+			escapeCell(supplier.Address),
+			escapeCell(supplier.VatNumber),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// importCSV applies every row of the CSV, with its ID when it has one. Failed rows are reported and skipped,
+// and an error is returned when any row failed.
+func (c *cli) importCSV(input io.Reader, columns []string, apply func(id *int8, fields map[string]interface{}) error) error {
+	reader := csv.NewReader(input)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read the header of the CSV: %w", err)
+	}
+
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(columns, header[i]) {
+			return fmt.Errorf("unknown column %q, the columns are %s", column, strings.Join(columns, ", "))
+		}
+	}
+
+	created, updated, failed := 0, 0, 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		id, fields, err := parseCSVRecord(header, record)
+		if err == nil {
+			err = apply(id, fields)
+		}
+
+		if err != nil {
+			failed++
+			var customErr *schemas.CustomError
+			if errors.As(err, &customErr) {
+				fmt.Fprintf(c.stderr, "line %d: %s (%d)\n", line, customErr.Message, customErr.Code)
+			} else {
+				fmt.Fprintf(c.stderr, "line %d: %v\n", line, err)
+			}
+		} else if id != nil {
+			updated++
+		} else {
+			created++
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "Created %d, updated %d, failed %d\n", created, updated, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d rows failed to import", failed)
+	}
+	return nil
+}
+
+// parseCSVRecord returns the ID and the fields of the non-empty cells of a record
+func parseCSVRecord(header []string, record []string) (*int8, map[string]interface{}, error) {
+	var id *int8
+	fields := map[string]interface{}{}
+
+	for i, value := range record {
+		value = strings.TrimSpace(unescapeCell(value))
+		if value == "" {
+			continue
+		}
+
+		column := header[i]
+		switch column {
+		case "id":
+			parsed, err := strconv.ParseInt(value, 10, 8)
+			if err != nil || parsed < 1 {
+				return nil, nil, fmt.Errorf("invalid id %q", value)
+			}
+			recordId := int8(parsed)
+			id = &recordId
+		case "category_id", "supplier_id":
+			parsed, err := strconv.ParseInt(value, 10, 8)
+			if err != nil || parsed < 1 {
+				return nil, nil, fmt.Errorf("invalid %s %q", column, value)
+			}
+			fields[column] = int8(parsed)
+		case "quantity":
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s %q", column, value)
+			}
+			fields[column] = parsed
+		case "purchase_price":
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s %q", column, value)
+			}
+			fields[column] = parsed
+		case "tags":
+			// The services take the tags as they are decoded from JSON
+			tags := []interface{}{}
+			for _, tag := range strings.Split(value, tagSeparator) {
+				tags = append(tags, tag)
+			}
+			fields[column] = tags
+		case "attributes":
+			var attributes map[string]interface{}
+			if err := json.Unmarshal([]byte(value), &attributes); err != nil {
+				return nil, nil, fmt.Errorf("invalid attributes, must be a JSON object: %v", err)
+			}
+			fields[column] = attributes
+		case "purchase_currency":
+			fields[column] = strings.ToUpper(value)
+		default:
+			fields[column] = value
+		}
+	}
+
+	return id, fields, nil
+}
+
+// newItemFromFields returns the item to create from the fields of a row, which need the fields the API requires
+func newItemFromFields(fields map[string]interface{}) (schemas.Item, error) {
+	for _, required := range []string{"name", "description", "quantity", "supplier_id", "purchase_price"} {
+		if _, exists := fields[required]; !exists {
+			return schemas.Item{}, fmt.Errorf("missing required column: %s", required)
+		}
+	}
+
+	var item schemas.Item
+	if err := convertFields(fields, &item); err != nil {
+		return schemas.Item{}, err
+	}
+
+	return item, nil
+}
+
+func newSupplierFromFields(fields map[string]interface{}) (schemas.Supplier, error) {
+	if _, exists := fields["name"]; !exists {
+		return schemas.Supplier{}, errors.New("missing required column: name")
+	}
+
+	var supplier schemas.Supplier
+	if err := convertFields(fields, &supplier); err != nil {
+		return schemas.Supplier{}, err
+	}
+
+	return supplier, nil
+}
+
+// convertFields sets the fields on the schema by their JSON names
+func convertFields(fields map[string]interface{}, out interface{}) error {
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(encoded, out); err != nil {
+		return fmt.Errorf("invalid row: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/client"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func (c *cli) runItems(ctx context.Context, args []string) error {
+	name, args, err := subcommand("items", args, "list", "search", "get", "create", "update", "export", "import")
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "list":
+		return c.listItems(ctx, args)
+	case "search":
+		return c.searchItems(ctx, args)
+	case "get":
+		return c.getItem(ctx, args)
+	case "create":
+		return c.createItem(ctx, args)
+	case "update":
+		return c.updateItem(ctx, args)
+	case "export":
+		return c.exportItems(ctx, args)
+	default:
+		return c.importItems(ctx, args)
+	}
+}
+
+func (c *cli) listItems(ctx context.Context, args []string) error {
+	var options client.ItemListOptions
+	var tags, attributes listFlag
+	var categoryId idFlag
+
+	flags := flag.NewFlagSet("items list", flag.ContinueOnError)
+	flags.StringVar(&options.Cursor, "cursor", "", "cursor of the page to list")
+	flags.IntVar(&options.Limit, "limit", 20, "items per page, between 1 and 100")
+	flags.StringVar(&options.SortBy, "sort-by", "name", "field to sort by")
+	flags.BoolVar(&options.SortDesc, "desc", false, "sort descending")
+	flags.Var(&categoryId, "category-id", "only items in this category or its subcategories")
+	flags.Var(&tags, "tag", "only items with this tag, can be repeated")
+	flags.Var(&attributes, "attr", "only items whose attribute KEY has VALUE, as KEY=VALUE, can be repeated")
+	all := flags.Bool("all", false, "list every page")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	options.Filters.Tags = tags
+	options.Filters.CategoryId = categoryId.id
+
+	options.Filters.Attributes = map[string]string{}
+	for _, attribute := range attributes {
+		key, value, found := strings.Cut(attribute, "=")
+		if !found {
+			return fmt.Errorf("invalid -attr %q, must be KEY=VALUE", attribute)
+		}
+		options.Filters.Attributes[key] = value
+	}
+
+	if !*all {
+		page, err := c.backend.ListItems(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		if page.NextCursor != nil && c.output == "table" {
+			defer fmt.Fprintf(c.stderr, "More items with -cursor %s\n", *page.NextCursor)
+		}
+
+		if c.output == "json" {
+			return c.print(page, nil, nil)
+		}
+		return c.printItems(page.Items)
+	}
+
+	items := []schemas.Item{}
+	for item, err := range allItems(ctx, c.backend, options) {
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	return c.printItems(items)
+}
+
+func (c *cli) searchItems(ctx context.Context, args []string) error {
+	var options client.ItemSearchOptions
+	var category, stockStatus string
+	var categoryId, supplierId idFlag
+	var minPrice, maxPrice float64
+
+	flags := flag.NewFlagSet("items search", flag.ContinueOnError)
+	flags.IntVar(&options.Page, "page", 1, "page number, starting at 1")
+	flags.IntVar(&options.PageSize, "page-size", 20, "results per page")
+	flags.BoolVar(&options.WithFacets, "facets", false, "include the facets of the results, only in json output")
+	flags.StringVar(&category, "category", "", "only items in this category or its subcategories, by name")
+	flags.Var(&categoryId, "category-id", "only items in this category or its subcategories")
+	flags.Var(&supplierId, "supplier-id", "only items of this supplier")
+	flags.Float64Var(&minPrice, "min-price", 0, "minimum purchase price in the base currency")
+	flags.Float64Var(&maxPrice, "max-price", 0, "maximum purchase price in the base currency")
+	flags.StringVar(&stockStatus, "stock-status", "", "in_stock, low_stock or out_of_stock")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	query := strings.Join(positional, " ")
+	if query == "" {
+		return errors.New("items search needs a query")
+	}
+
+	// Only the filters that are given are sent
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "category":
+			options.Filters.Category = &category
+		case "category-id":
+			options.Filters.CategoryId = categoryId.id
+		case "supplier-id":
+			options.Filters.SupplierId = supplierId.id
+		case "min-price":
+			options.Filters.MinPrice = &minPrice
+		case "max-price":
+			options.Filters.MaxPrice = &maxPrice
+		case "stock-status":
+			options.Filters.StockStatus = &stockStatus
+		}
+	})
+
+	page, err := c.backend.SearchItems(ctx, query, options)
+	if err != nil {
+		return err
+	}
+
+	if c.output == "json" {
+		return c.print(page, nil, nil)
+	}
+
+	rows := make([][]string, len(page.Results))
+	for i, result := range page.Results {
+		rows[i] = append(itemRow(result.Item), fmt.Sprintf("%.3f", result.Rank))
+	}
+
+	if err := c.print(page, append(itemHeaders, "RANK"), rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Page %d, %d results in total\n", page.Page, page.Count)
+	return nil
+}
+
+func (c *cli) getItem(ctx context.Context, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	item, err := c.backend.GetItem(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.printItem(item)
+}
+
+func (c *cli) createItem(ctx context.Context, args []string) error {
+	var item schemas.Item
+	var quantity int
+	var supplierId, categoryId idFlag
+	var price float64
+	var sku, location string
+	var tags listFlag
+
+	flags := flag.NewFlagSet("items create", flag.ContinueOnError)
+	flags.StringVar(&item.Name, "name", "", "name of the item")
+	flags.StringVar(&item.Description, "description", "", "description of the item")
+	flags.IntVar(&quantity, "quantity", 0, "stock on hand")
+	flags.Var(&supplierId, "supplier-id", "ID of the supplier")
+	flags.Var(&categoryId, "category-id", "ID of the category, optional")
+	flags.Float64Var(&price, "price", 0, "purchase price")
+	flags.StringVar(&item.PurchaseCurrency, "currency", "", "purchase currency, the database default when empty")
+	flags.StringVar(&sku, "sku", "", "SKU of the item")
+	flags.StringVar(&location, "location", "", "storage location")
+	flags.StringVar(&item.BaseUnit, "unit", "", "base unit, the database default when empty")
+	flags.Var(&tags, "tag", "tag of the item, can be repeated")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// The same fields are required as by the API
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, required := range []string{"name", "description", "quantity", "supplier-id", "price"} {
+		if !set[required] {
+			return fmt.Errorf("missing required flag: -%s", required)
+		}
+	}
+
+	item.Quantity = quantity
+	item.SupplierId = *supplierId.id
+	item.CategoryId = categoryId.id
+	item.PurchasePrice = price
+	item.PurchaseCurrency = strings.ToUpper(item.PurchaseCurrency)
+	item.Tags = tags
+
+	if sku != "" {
+		item.Sku = &sku
+	}
+	if location = strings.TrimSpace(location); location != "" {
+		item.Location = &location
+	}
+
+	created, err := c.backend.CreateItem(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	return c.printItem(created)
+}
+
+func (c *cli) updateItem(ctx context.Context, args []string) error {
+	var sets listFlag
+
+	flags := flag.NewFlagSet("items update", flag.ContinueOnError)
+	flags.Var(&sets, "set", "field to change, as FIELD=VALUE, can be repeated")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseId(positional)
+	if err != nil {
+		return err
+	}
+
+	updates, err := parseSetFlags(sets)
+	if err != nil {
+		return err
+	}
+
+	item, err := c.backend.UpdateItem(ctx, id, updates)
+	if err != nil {
+		return err
+	}
+
+	return c.printItem(item)
+}
+
+func (c *cli) exportItems(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("items export", flag.ContinueOnError)
+	file := flags.String("file", "", "file to write the CSV to, stdout when empty")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	output := c.stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+
+	count, err := exportItemsCSV(output, allItems(ctx, c.backend, client.ItemListOptions{Limit: 100, SortBy: "id"}))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Exported %d items\n", count)
+	return nil
+}
+
+func (c *cli) importItems(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("items import", flag.ContinueOnError)
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("items import needs the CSV file to import")
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.importCSV(f, itemCSVColumns, func(id *int8, fields map[string]interface{}) error {
+		if id != nil {
+			delete(fields, "quantity")
+			if len(fields) == 0 {
+				return nil
+			}
+			_, err := c.backend.UpdateItem(ctx, *id, fields)
+			return err
+		}
+
+		item, err := newItemFromFields(fields)
+		if err != nil {
+			return err
+		}
+		_, err = c.backend.CreateItem(ctx, item)
+		return err
+	})
+}
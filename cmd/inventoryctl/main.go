@@ -0,0 +1,229 @@
+// Command inventoryctl administers the inventory from the command line.
+// It talks to the API, or with -direct to the database through the service layer, which needs SUPABASE_URL
+// and SUPABASE_SECRET_KEY in the .env file.
+//
+//	inventoryctl [-api URL] [-direct] [-o table|json] <command> [flags] [arguments]
+//
+// Commands:
+//
+//	items list [-limit 20] [-all] [-sort-by name] [-desc] [-category-id ID] [-tag TAG]... [-attr KEY=VALUE]...
+//	items search [-page 1] [-page-size 20] [-category NAME] [-category-id ID] [-supplier-id ID] [-min-price P] [-max-price P] [-stock-status S] QUERY
+//	items get ID
+//	items create -name NAME -description TEXT -quantity N -supplier-id ID -price P [-category-id ID] [-currency EUR] [-sku SKU] [-location L] [-unit pcs] [-tag TAG]...
+//	items update ID -set FIELD=VALUE...
+//	items export [-file items.csv]
+//	items import FILE
+//	suppliers list [-name NAME]
+//	suppliers get ID
+//	suppliers create -name NAME [-website URL] [-address ADDRESS] [-vat-number VAT]
+//	suppliers update ID -set FIELD=VALUE...
+//	suppliers export [-file suppliers.csv]
+//	suppliers import FILE
+//	purge [-retention-days 90] [-dry-run]
+//	migrate [up|status] [-db-url URL]
+//
+// The API is http://localhost:8080/v1 unless -api or INVENTORY_API_URL is set.
+// Values of -set are JSON when they parse as JSON and strings otherwise, e.g. -set location=A1 -set 'tags=["a","b"]'.
+// Migrations are applied with the Supabase CLI from the supabase directory of this repository.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/client"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// defaultApiUrl is used when neither -api nor INVENTORY_API_URL is set
+const defaultApiUrl = "http://localhost:8080/v1"
+
+// cli holds the global options of a run
+type cli struct {
+	backend backend
+	output  string
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:])
+	stop()
+
+	if err != nil {
+		var customErr *schemas.CustomError
+		if errors.As(err, &customErr) {
+			fmt.Fprintf(os.Stderr, "inventoryctl: %s (%d)\n", customErr.Message, customErr.Code)
+		} else if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "inventoryctl: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	apiUrl := os.Getenv("INVENTORY_API_URL")
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	flags := flag.NewFlagSet("inventoryctl", flag.ContinueOnError)
+	flags.StringVar(&apiUrl, "api", apiUrl, "URL of the v1 API, defaults to INVENTORY_API_URL")
+	direct := flags.Bool("direct", false, "use the service layer instead of the API")
+	output := flags.String("o", "table", "output format: table or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: inventoryctl [-api URL] [-direct] [-o table|json] <items|suppliers|purge|migrate> ...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("invalid output %q, must be table or json", *output)
+	}
+
+	c := &cli{output: *output, stdout: os.Stdout, stderr: os.Stderr}
+	if *direct {
+		c.backend = directBackend{}
+	} else {
+		c.backend = apiBackend{api: client.New(apiUrl)}
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "items":
+		return c.runItems(ctx, commandArgs)
+	case "suppliers":
+		return c.runSuppliers(ctx, commandArgs)
+	case "purge":
+		return c.runPurge(ctx, commandArgs)
+	case "migrate":
+		return c.runMigrate(ctx, commandArgs)
+	}
+
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// subcommand splits the name of a subcommand from its arguments
+func subcommand(command string, args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s needs a subcommand: %s", command, strings.Join(names, ", "))
+	}
+
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("unknown subcommand %q of %s, must be one of %s", args[0], command, strings.Join(names, ", "))
+}
+
+// parseFlags parses the flags, which may come before or after the positional arguments, and returns the
+// positional arguments
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// listFlag is a flag that can be repeated
+type listFlag []string
+
+func (list *listFlag) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *listFlag) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+// idFlag is an optional flag with the ID of an item, supplier or category, nil when it is not given
+type idFlag struct {
+	id *int8
+}
+
+func (f *idFlag) String() string {
+	if f.id == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*f.id))
+}
+
+func (f *idFlag) Set(value string) error {
+	id, err := strconv.ParseInt(value, 10, 8)
+	if err != nil || id < 1 {
+		return fmt.Errorf("invalid ID %q, must be between 1 and %d", value, math.MaxInt8)
+	}
+
+	parsed := int8(id)
+	f.id = &parsed
+	return nil
+}
+
+// parseSetFlags returns the fields of the -set FIELD=VALUE flags. A value is JSON when it parses as JSON,
+// and a string otherwise.
+func parseSetFlags(sets listFlag) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+	for _, set := range sets {
+		field, value, found := strings.Cut(set, "=")
+		if !found || strings.TrimSpace(field) == "" {
+			return nil, fmt.Errorf("invalid -set %q, must be FIELD=VALUE", set)
+		}
+
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			parsed = value
+		}
+		updates[strings.TrimSpace(field)] = parsed
+	}
+
+	if len(updates) == 0 {
+		return nil, errors.New("nothing to update, set fields with -set FIELD=VALUE")
+	}
+
+	return updates, nil
+}
+
+// parseId parses the ID of an item or supplier
+func parseId(args []string) (int8, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected exactly one ID")
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 8)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid ID %q, must be between 1 and %d", args[0], math.MaxInt8)
+	}
+
+	return int8(id), nil
+}
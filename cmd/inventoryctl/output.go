@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// print writes the value as indented JSON, or the rows as a table with the headers
+func (c *cli) print(value interface{}, headers []string, rows [][]string) error {
+	if c.output == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+var itemHeaders = []string{"ID", "SKU", "NAME", "CATEGORY", "QUANTITY", "AVAILABLE", "PRICE", "CURRENCY", "SUPPLIER", "LOCATION"}
+
+func itemRow(item schemas.Item) []string {
+	available := ""
+	if item.AvailableQuantity != nil {
+		available = strconv.Itoa(*item.AvailableQuantity)
+	}
+
+	return []string{
+		strconv.Itoa(int(item.Id)),
+		stringOrEmpty(item.Sku),
+		item.Name,
+		item.Category,
+		strconv.Itoa(item.Quantity),
+		available,
+		strconv.FormatFloat(item.PurchasePrice, 'f', -1, 64),
+		item.PurchaseCurrency,
+		strconv.Itoa(int(item.SupplierId)),
+		stringOrEmpty(item.Location),
+	}
+}
+
+func (c *cli) printItems(items []schemas.Item) error {
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		rows[i] = itemRow(item)
+	}
+	return c.print(items, itemHeaders, rows)
+}
+
+func (c *cli) printItem(item schemas.Item) error {
+	return c.print(item, itemHeaders, [][]string{itemRow(item)})
+}
+
+var supplierHeaders = []string{"ID", "NAME", "WEBSITE", "ADDRESS", "VAT NUMBER", "CONTACTS"}
+
+func supplierRow(supplier schemas.Supplier) []string {
+	contacts := make([]string, len(supplier.ContactInfo))
+	for i, contact := range supplier.ContactInfo {
+		contacts[i] = contact.ContactName
+		if contact.Email != "" {
+			contacts[i] += " <" + contact.Email + ">"
+		}
+	}
+
+	return []string{
+		strconv.Itoa(int(supplier.Id)),
+		supplier.Name,
+		supplier.Website,
+		supplier.Address,
+		supplier.VatNumber,
+		strings.Join(contacts, ", "),
+	}
+}
+
+func (c *cli) printSuppliers(suppliers []schemas.Supplier) error {
+	rows := make([][]string, len(suppliers))
+	for i, supplier := range suppliers {
+		rows[i] = supplierRow(supplier)
+	}
+	return c.print(suppliers, supplierHeaders, rows)
+}
+
+func (c *cli) printSupplier(supplier schemas.Supplier) error {
+	return c.print(supplier, supplierHeaders, [][]string{supplierRow(supplier)})
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
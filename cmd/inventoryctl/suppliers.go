@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func (c *cli) runSuppliers(ctx context.Context, args []string) error {
+	name, args, err := subcommand("suppliers", args, "list", "get", "create", "update", "export", "import")
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "list":
+		return c.listSuppliers(ctx, args)
+	case "get":
+		return c.getSupplier(ctx, args)
+	case "create":
+		return c.createSupplier(ctx, args)
+	case "update":
+		return c.updateSupplier(ctx, args)
+	case "export":
+		return c.exportSuppliers(ctx, args)
+	default:
+		return c.importSuppliers(ctx, args)
+	}
+}
+
+func (c *cli) listSuppliers(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("suppliers list", flag.ContinueOnError)
+	name := flags.String("name", "", "only suppliers whose name contains this")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	suppliers, err := c.backend.ListSuppliers(ctx, *name)
+	if err != nil {
+		return err
+	}
+
+	return c.printSuppliers(suppliers)
+}
+
+func (c *cli) getSupplier(ctx context.Context, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	supplier, err := c.backend.GetSupplier(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.printSupplier(supplier)
+}
+
+func (c *cli) createSupplier(ctx context.Context, args []string) error {
+	var supplier schemas.Supplier
+
+	flags := flag.NewFlagSet("suppliers create", flag.ContinueOnError)
+	flags.StringVar(&supplier.Name, "name", "", "name of the supplier")
+	flags.StringVar(&supplier.Website, "website", "", "website of the supplier")
+	flags.StringVar(&supplier.Address, "address", "", "address of the supplier")
+	flags.StringVar(&supplier.VatNumber, "vat-number", "", "VAT number of the supplier")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if supplier.Name == "" {
+		return errors.New("missing required flag: -name")
+	}
+
+	created, err := c.backend.CreateSupplier(ctx, supplier)
+	if err != nil {
+		return err
+	}
+
+	return c.printSupplier(created)
+}
+
+func (c *cli) updateSupplier(ctx context.Context, args []string) error {
+	var sets listFlag
+
+	flags := flag.NewFlagSet("suppliers update", flag.ContinueOnError)
+	flags.Var(&sets, "set", "field to change, as FIELD=VALUE, can be repeated")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseId(positional)
+	if err != nil {
+		return err
+	}
+
+	updates, err := parseSetFlags(sets)
+	if err != nil {
+		return err
+	}
+
+	supplier, err := c.backend.UpdateSupplier(ctx, id, updates)
+	if err != nil {
+		return err
+	}
+
+	return c.printSupplier(supplier)
+}
+
+func (c *cli) exportSuppliers(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("suppliers export", flag.ContinueOnError)
+	file := flags.String("file", "", "file to write the CSV to, stdout when empty")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	suppliers, err := c.backend.ListSuppliers(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	output := c.stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+
+	if err := exportSuppliersCSV(output, suppliers); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Exported %d suppliers\n", len(suppliers))
+	return nil
+}
+
+func (c *cli) importSuppliers(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("suppliers import", flag.ContinueOnError)
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("suppliers import needs the CSV file to import")
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.importCSV(f, supplierCSVColumns, func(id *int8, fields map[string]interface{}) error {
+		if id != nil {
+			_, err := c.backend.UpdateSupplier(ctx, *id, fields)
+			return err
+		}
+
+		supplier, err := newSupplierFromFields(fields)
+		if err != nil {
+			return err
+		}
+		_, err = c.backend.CreateSupplier(ctx, supplier)
+		return err
+	})
+}
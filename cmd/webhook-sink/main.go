@@ -0,0 +1,70 @@
+// Command webhook-sink is a local HTTP receiver for testing webhook subscriptions.
+// It verifies the signature of every delivery with the secret of the subscription and logs the event.
+//
+//	go run ./cmd/webhook-sink -addr :9000 -secret whsec_...
+//
+// Subscribe http://localhost:9000/ and use -status 500 to test retries. Webhooks are only sent to public hosts,
+// so run the server with WEBHOOK_ALLOW_PRIVATE_URLS=true to reach the sink.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/webhooks"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "address to listen on")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "secret of the subscription, defaults to WEBHOOK_SECRET")
+	status := flag.Int("status", http.StatusOK, "status code to answer every delivery with")
+	flag.Parse()
+
+	http.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		verified := *secret != "" && webhooks.VerifySignature(
+			*secret,
+			request.Header.Get(webhooks.HeaderTimestamp),
+			request.Header.Get(webhooks.HeaderSignature),
+			body,
+			5*time.Minute,
+		)
+
+		var event schemas.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			slog.Warn("Received a delivery that is not an event", "error", err)
+		}
+
+		slog.Info("Received delivery",
+			"delivery", request.Header.Get(webhooks.HeaderDelivery),
+			"event_id", event.Id,
+			"type", event.Type,
+			"verified", verified,
+			"data", event.Data,
+		)
+
+		if *secret != "" && !verified {
+			http.Error(writer, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		writer.WriteHeader(*status)
+	})
+
+	slog.Info("Webhook sink listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		slog.Error("Webhook sink stopped", "error", err)
+		os.Exit(1)
+	}
+}
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+
 	v1 "github.com/MattyMcF4tty/InventoryManager-backend/v1"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/openapi"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/events"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/reservations"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/webhooks"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,6 +18,22 @@ func main() {
 	v1Routes := router.Group("/v1")
 	v1.RouteHandler(v1Routes)
 
+	// Describe the registered routes in the OpenAPI document
+	openapi.SetRoutes(router.Routes(), "/v1")
+
+	// Release expired reservations in the background
+	go reservations.StartExpiryWorker(context.Background(), reservations.GetExpiryInterval())
+
+	// Deliver the events of the outbox to the sinks in the background
+	events.RegisterSink(webhooks.Sink{})
+	go events.StartDispatcher(context.Background(), events.GetDispatchInterval())
+
+	// Push the new events to the open streams in the background
+	go events.StartStreamTailer(context.Background(), events.GetStreamPollInterval())
+
+	// Send the queued webhook deliveries in the background
+	go webhooks.StartDeliveryWorker(context.Background(), webhooks.GetDeliveryInterval())
+
 	// Start server on port 8080
 	router.Run("0.0.0.0:8080")
 }
@@ -0,0 +1,71 @@
+-- Full-text and fuzzy search for items.
+-- Items are matched on name, category, description and notes, either through
+-- the full-text search vector or through trigram word similarity to handle typos.
+
+create extension if not exists pg_trgm;
+
+alter table items
+  add column if not exists search_vector tsvector
+  generated always as (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(notes, '')), 'D')
+  ) stored;
+
+create index if not exists items_search_vector_idx on items using gin (search_vector);
+create index if not exists items_name_trgm_idx on items using gin (name gin_trgm_ops);
+create index if not exists items_category_trgm_idx on items using gin (category gin_trgm_ops);
+create index if not exists items_description_trgm_idx on items using gin (description gin_trgm_ops);
+create index if not exists items_notes_trgm_idx on items using gin (notes gin_trgm_ops);
+
+-- Returns the matching items ordered by relevance, with <mark> highlighted
+-- snippets for the fields that matched the full-text query.
+create or replace function search_items(search_query text, result_limit integer, result_offset integer)
+returns table (item jsonb, rank real, highlights jsonb, total_count bigint)
+language sql
+stable
+as $$
+  with query as (
+    select websearch_to_tsquery('english', search_query) as tsq
+  ),
+  matches as (
+    select
+      i.*,
+      query.tsq as tsq,
+      (
+        ts_rank(i.search_vector, query.tsq) * 2
+        + word_similarity(search_query, i.name)
+        + word_similarity(search_query, coalesce(i.category, '')) * 0.5
+        + word_similarity(search_query, coalesce(i.description, '')) * 0.5
+        + word_similarity(search_query, coalesce(i.notes, '')) * 0.25
+      )::real as search_rank
+    from items i, query
+    where i.deleted_at is null
+      and (
+        i.search_vector @@ query.tsq
+        or search_query <% i.name
+        or search_query <% coalesce(i.category, '')
+        or search_query <% coalesce(i.description, '')
+        or search_query <% coalesce(i.notes, '')
+      )
+  )
+  select
+    to_jsonb(m) - 'search_vector' - 'tsq' - 'search_rank' as item,
+    m.search_rank as rank,
+    jsonb_strip_nulls(jsonb_build_object(
+      'name', case when to_tsvector('english', coalesce(m.name, '')) @@ m.tsq
+        then ts_headline('english', m.name, m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'category', case when to_tsvector('english', coalesce(m.category, '')) @@ m.tsq
+        then ts_headline('english', m.category, m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'description', case when to_tsvector('english', coalesce(m.description, '')) @@ m.tsq
+        then ts_headline('english', m.description, m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end,
+      'notes', case when to_tsvector('english', coalesce(m.notes, '')) @@ m.tsq
+        then ts_headline('english', m.notes, m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end
+    )) as highlights,
+    count(*) over () as total_count
+  from matches m
+  order by m.search_rank desc, m.name asc, m.id asc
+  limit result_limit
+  offset result_offset;
+$$;
@@ -0,0 +1,174 @@
+-- Filters and facet counts for item search.
+-- search_items gains optional filters, and search_item_facets returns the counts
+-- per category, supplier, price band and stock status for the same query.
+
+-- item_search_matches returns the items matching the search query, or every item
+-- when the query is empty. Filters are applied by the callers.
+create or replace function item_search_matches(search_query text)
+returns setof items
+language sql
+stable
+as $$
+  select i.*
+  from items i
+  where i.deleted_at is null
+    and (
+      coalesce(trim(search_query), '') = ''
+      or i.search_vector @@ websearch_to_tsquery('english', search_query)
+      or search_query <% i.name
+      or search_query <% coalesce(i.category, '')
+      or search_query <% coalesce(i.description, '')
+      or search_query <% coalesce(i.notes, '')
+    );
+$$;
+
+-- item_stock_status classifies the quantity of an item
+create or replace function item_stock_status(quantity integer, low_stock_threshold integer)
+returns text
+language sql
+immutable
+as $$
+  select case
+    when quantity <= 0 then 'out_of_stock'
+    when quantity <= low_stock_threshold then 'low_stock'
+    else 'in_stock'
+  end;
+$$;
+
+drop function if exists search_items(text, integer, integer);
+
+create or replace function search_items(
+  search_query text,
+  result_limit integer,
+  result_offset integer,
+  filter_category text default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5
+)
+returns table (item jsonb, rank real, highlights jsonb, total_count bigint)
+language sql
+stable
+as $$
+  with query as (
+    select websearch_to_tsquery('english', coalesce(search_query, '')) as tsq
+  ),
+  matches as (
+    select
+      i.*,
+      query.tsq as tsq,
+      (
+        ts_rank(i.search_vector, query.tsq) * 2
+        + word_similarity(coalesce(search_query, ''), i.name)
+        + word_similarity(coalesce(search_query, ''), coalesce(i.category, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.description, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.notes, '')) * 0.25
+      )::real as search_rank
+    from item_search_matches(search_query) i, query
+    where (filter_category is null or i.category = filter_category)
+      and (filter_supplier_id is null or i.supplier_id = filter_supplier_id)
+      and (filter_min_price is null or i.purchase_price >= filter_min_price)
+      and (filter_max_price is null or i.purchase_price < filter_max_price)
+      and (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status)
+  )
+  select
+    to_jsonb(m) - 'search_vector' - 'tsq' - 'search_rank' as item,
+    m.search_rank as rank,
+    jsonb_strip_nulls(jsonb_build_object(
+      'name', case when to_tsvector('english', coalesce(m.name, '')) @@ m.tsq
+        then ts_headline('english', m.name, m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'category', case when to_tsvector('english', coalesce(m.category, '')) @@ m.tsq
+        then ts_headline('english', m.category, m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'description', case when to_tsvector('english', coalesce(m.description, '')) @@ m.tsq
+        then ts_headline('english', m.description, m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end,
+      'notes', case when to_tsvector('english', coalesce(m.notes, '')) @@ m.tsq
+        then ts_headline('english', m.notes, m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end
+    )) as highlights,
+    count(*) over () as total_count
+  from matches m
+  order by m.search_rank desc, m.name asc, m.id asc
+  limit result_limit
+  offset result_offset;
+$$;
+
+-- search_item_facets returns the facet counts for a search.
+-- Each facet ignores its own filter, so the UI can show the alternatives of a selected filter.
+create or replace function search_item_facets(
+  search_query text,
+  filter_category text default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5,
+  price_band_bounds numeric[] default '{10,50,100,500}'
+)
+returns jsonb
+language sql
+stable
+as $$
+  with matches as (
+    select
+      i.*,
+      item_stock_status(i.quantity, low_stock_threshold) as stock_status,
+      (filter_category is null or i.category = filter_category) as category_match,
+      (filter_supplier_id is null or i.supplier_id = filter_supplier_id) as supplier_match,
+      ((filter_min_price is null or i.purchase_price >= filter_min_price)
+        and (filter_max_price is null or i.purchase_price < filter_max_price)) as price_match,
+      (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status) as stock_match
+    from item_search_matches(search_query) i
+  ),
+  bands as (
+    select
+      band.index,
+      case when band.index = 1 then 0 else price_band_bounds[band.index - 1] end as min_price,
+      case when band.index <= cardinality(price_band_bounds) then price_band_bounds[band.index] end as max_price
+    from generate_series(1, cardinality(price_band_bounds) + 1) as band(index)
+  )
+  select jsonb_build_object(
+    'categories', coalesce((
+      select jsonb_agg(jsonb_build_object('value', c.category, 'count', c.count) order by c.count desc, c.category)
+      from (
+        select category, count(*) as count
+        from matches
+        where supplier_match and price_match and stock_match
+        group by category
+      ) c
+    ), '[]'::jsonb),
+    'suppliers', coalesce((
+      select jsonb_agg(jsonb_build_object('id', s.supplier_id, 'name', s.name, 'count', s.count) order by s.count desc, s.name)
+      from (
+        select m.supplier_id, sup.name, count(*) as count
+        from matches m
+        left join suppliers sup on sup.id = m.supplier_id
+        where m.category_match and m.price_match and m.stock_match
+        group by m.supplier_id, sup.name
+      ) s
+    ), '[]'::jsonb),
+    'price_bands', coalesce((
+      select jsonb_agg(jsonb_build_object('min', b.min_price, 'max', b.max_price, 'count', b.count) order by b.index)
+      from (
+        select bands.index, bands.min_price, bands.max_price, count(m.id) as count
+        from bands
+        left join matches m
+          on m.category_match and m.supplier_match and m.stock_match
+          and m.purchase_price >= bands.min_price
+          and (bands.max_price is null or m.purchase_price < bands.max_price)
+        group by bands.index, bands.min_price, bands.max_price
+      ) b
+    ), '[]'::jsonb),
+    'stock_status', coalesce((
+      select jsonb_agg(jsonb_build_object('value', st.status, 'count', st.count) order by st.sort_order)
+      from (
+        select statuses.status, statuses.sort_order, count(m.id) as count
+        from (values ('in_stock', 1), ('low_stock', 2), ('out_of_stock', 3)) as statuses(status, sort_order)
+        left join matches m
+          on m.stock_status = statuses.status
+          and m.category_match and m.supplier_match and m.price_match
+        group by statuses.status, statuses.sort_order
+      ) st
+    ), '[]'::jsonb)
+  );
+$$;
@@ -0,0 +1,147 @@
+-- Hierarchical categories.
+-- Items reference a category by id. The old free-text items.category column is kept
+-- as a read-only copy of the category name, so search and facets keep working.
+
+create table if not exists categories (
+  id bigint generated by default as identity primary key,
+  name text not null check (length(trim(name)) > 0),
+  description text not null default '',
+  parent_id bigint references categories (id) on delete restrict,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  deleted_at timestamptz
+);
+
+-- Category names are unique among their siblings, ignoring case
+create unique index if not exists categories_parent_name_key
+  on categories (coalesce(parent_id, 0), lower(name))
+  where deleted_at is null;
+
+create index if not exists categories_parent_id_idx on categories (parent_id);
+
+-- Migrate the existing category strings. Spellings that only differ in case or
+-- surrounding whitespace are merged, keeping the most used spelling.
+insert into categories (name)
+select distinct on (lower(trim(category))) trim(category)
+from (
+  select category, count(*) as usage
+  from items
+  where category is not null and trim(category) <> ''
+  group by category
+) used
+order by lower(trim(category)), usage desc, trim(category);
+
+alter table items
+  add column if not exists category_id bigint references categories (id) on delete restrict;
+
+update items i
+set category_id = c.id
+from categories c
+where c.parent_id is null
+  and lower(c.name) = lower(trim(i.category));
+
+create index if not exists items_category_id_idx on items (category_id);
+
+-- category_descendant_ids returns the id of the category and all of its descendants
+create or replace function category_descendant_ids(root_id bigint)
+returns setof bigint
+language sql
+stable
+as $$
+  with recursive tree as (
+    select id from categories where id = root_id and deleted_at is null
+    union
+    select c.id
+    from categories c
+    join tree t on c.parent_id = t.id
+    where c.deleted_at is null
+  )
+  select id from tree;
+$$;
+
+-- Keep items.category in sync with the name of the referenced category
+create or replace function sync_item_category_name()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.category_id is not null then
+    new.category := (select name from categories where id = new.category_id);
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists items_sync_category_name on items;
+create trigger items_sync_category_name
+  before insert or update on items
+  for each row execute function sync_item_category_name();
+
+create or replace function propagate_category_name()
+returns trigger
+language plpgsql
+as $$
+begin
+  update items set category = new.name where category_id = new.id;
+  return new;
+end;
+$$;
+
+drop trigger if exists categories_propagate_name on categories;
+create trigger categories_propagate_name
+  after update of name on categories
+  for each row
+  when (old.name is distinct from new.name)
+  execute function propagate_category_name();
+
+-- A category can not be moved below itself
+create or replace function prevent_category_cycles()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.parent_id is not null and new.parent_id in (select category_descendant_ids(new.id)) then
+    raise exception 'Category % can not be moved below itself', new.id using errcode = '23514';
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists categories_prevent_cycles on categories;
+create trigger categories_prevent_cycles
+  before update of parent_id on categories
+  for each row execute function prevent_category_cycles();
+
+-- merge_categories moves the items and children of the source categories to the
+-- target category and deletes the source categories. Used to clean up duplicates
+-- like "Tool" and "Tools" that the migration could not merge.
+create or replace function merge_categories(target_id bigint, source_ids bigint[])
+returns categories
+language plpgsql
+as $$
+declare
+  target categories;
+begin
+  select * into target from categories where id = target_id and deleted_at is null;
+  if not found then
+    raise exception 'Category % not found', target_id using errcode = 'P0002';
+  end if;
+
+  if target_id = any (source_ids) then
+    raise exception 'Category % can not be merged into itself', target_id using errcode = '22023';
+  end if;
+
+  if exists (
+    select 1 from unnest(source_ids) as source(id)
+    where target_id in (select category_descendant_ids(source.id))
+  ) then
+    raise exception 'Category % can not be merged into its own descendant', target_id using errcode = '22023';
+  end if;
+
+  update items set category_id = target_id, updated_at = now() where category_id = any (source_ids);
+  update categories set parent_id = target_id, updated_at = now() where parent_id = any (source_ids);
+  delete from categories where id = any (source_ids);
+
+  return target;
+end;
+$$;
@@ -0,0 +1,27 @@
+-- Free-form tags and custom attributes on items.
+-- Attribute values are stored in items.attributes and validated by the API
+-- against the attribute definitions.
+
+alter table items
+  add column if not exists tags text[] not null default '{}',
+  add column if not exists attributes jsonb not null default '{}';
+
+create index if not exists items_tags_idx on items using gin (tags);
+create index if not exists items_attributes_idx on items using gin (attributes jsonb_path_ops);
+
+create table if not exists attribute_definitions (
+  id bigint generated by default as identity primary key,
+  key text not null check (key ~ '^[a-z][a-z0-9_]*$'),
+  name text not null,
+  type text not null check (type in ('string', 'number', 'enum', 'date')),
+  options text[] not null default '{}',
+  required boolean not null default false,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  deleted_at timestamptz,
+  check (type <> 'enum' or cardinality(options) > 0)
+);
+
+create unique index if not exists attribute_definitions_key_key
+  on attribute_definitions (key)
+  where deleted_at is null;
@@ -0,0 +1,20 @@
+-- SKUs and barcodes for items.
+-- Barcodes are validated by the API, the symbology is stored so labels can be printed.
+
+alter table items
+  add column if not exists sku text;
+
+create unique index if not exists items_sku_key
+  on items (sku)
+  where sku is not null;
+
+create table if not exists item_barcodes (
+  id bigint generated by default as identity primary key,
+  item_id bigint not null references items (id) on delete cascade,
+  code text not null,
+  symbology text not null check (symbology in ('ean13', 'upca', 'code128')),
+  created_at timestamptz not null default now()
+);
+
+create unique index if not exists item_barcodes_code_key on item_barcodes (code);
+create index if not exists item_barcodes_item_id_idx on item_barcodes (item_id);
@@ -0,0 +1,37 @@
+-- Lot/batch tracking with expiry dates.
+-- For items with lots, items.quantity is kept as the sum of the lot quantities.
+
+create table if not exists item_lots (
+  id bigint generated by default as identity primary key,
+  item_id bigint not null references items (id) on delete cascade,
+  lot_number text not null check (length(trim(lot_number)) > 0),
+  expiry_date date,
+  quantity integer not null default 0 check (quantity >= 0),
+  received_at timestamptz not null default now(),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create unique index if not exists item_lots_item_lot_number_key on item_lots (item_id, lot_number);
+create index if not exists item_lots_expiry_date_idx on item_lots (expiry_date) where quantity > 0;
+
+create or replace function sync_item_quantity_from_lots()
+returns trigger
+language plpgsql
+as $$
+declare
+  changed_item_id bigint;
+begin
+  changed_item_id := coalesce(new.item_id, old.item_id);
+  update items
+  set quantity = (select coalesce(sum(quantity), 0) from item_lots where item_id = changed_item_id),
+      updated_at = now()
+  where id = changed_item_id;
+  return null;
+end;
+$$;
+
+drop trigger if exists item_lots_sync_item_quantity on item_lots;
+create trigger item_lots_sync_item_quantity
+  after insert or update of quantity or delete on item_lots
+  for each row execute function sync_item_quantity_from_lots();
@@ -0,0 +1,102 @@
+-- Serial-number tracking for individual units.
+-- For serialised items, items.quantity is kept as the number of serials in stock.
+
+alter table items add column if not exists serialized boolean not null default false;
+
+create table if not exists item_serials (
+  id bigint generated by default as identity primary key,
+  item_id bigint not null references items (id) on delete cascade,
+  serial_number text not null check (length(trim(serial_number)) > 0),
+  status text not null default 'in_stock'
+    check (status in ('in_stock', 'issued', 'in_repair', 'retired')),
+  location text,
+  note text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create unique index if not exists item_serials_item_serial_number_key on item_serials (item_id, serial_number);
+create index if not exists item_serials_serial_number_idx on item_serials (serial_number);
+create index if not exists item_serials_item_status_idx on item_serials (item_id, status);
+
+-- Every change of status or location is recorded in the history
+create table if not exists item_serial_events (
+  id bigint generated by default as identity primary key,
+  serial_id bigint not null references item_serials (id) on delete cascade,
+  status text not null,
+  previous_status text,
+  location text,
+  previous_location text,
+  note text,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists item_serial_events_serial_id_idx on item_serial_events (serial_id, created_at);
+
+create or replace function record_item_serial_event()
+returns trigger
+language plpgsql
+as $$
+begin
+  if tg_op = 'INSERT' then
+    insert into item_serial_events (serial_id, status, location, note)
+    values (new.id, new.status, new.location, new.note);
+  elsif new.status is distinct from old.status
+     or new.location is distinct from old.location
+     or new.note is distinct from old.note then
+    insert into item_serial_events (serial_id, status, previous_status, location, previous_location, note)
+    values (new.id, new.status, old.status, new.location, old.location, new.note);
+  end if;
+  return null;
+end;
+$$;
+
+drop trigger if exists item_serials_record_event on item_serials;
+create trigger item_serials_record_event
+  after insert or update on item_serials
+  for each row execute function record_item_serial_event();
+
+create or replace function sync_item_quantity_from_serials()
+returns trigger
+language plpgsql
+as $$
+declare
+  changed_item_id bigint;
+begin
+  changed_item_id := coalesce(new.item_id, old.item_id);
+  update items
+  set quantity = (
+        select count(*) from item_serials
+        where item_id = changed_item_id and status = 'in_stock'
+      ),
+      updated_at = now()
+  where id = changed_item_id and serialized;
+  return null;
+end;
+$$;
+
+drop trigger if exists item_serials_sync_item_quantity on item_serials;
+create trigger item_serials_sync_item_quantity
+  after insert or update of status, item_id or delete on item_serials
+  for each row execute function sync_item_quantity_from_serials();
+
+-- When an item becomes serialised its quantity is derived from its serials from then on
+create or replace function derive_serialized_item_quantity()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.serialized and (tg_op = 'INSERT' or not old.serialized) then
+    new.quantity := (
+      select count(*) from item_serials
+      where item_id = new.id and status = 'in_stock'
+    );
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists items_derive_serialized_quantity on items;
+create trigger items_derive_serialized_quantity
+  before insert or update of serialized on items
+  for each row execute function derive_serialized_item_quantity();
@@ -0,0 +1,153 @@
+-- Stock movements ledger and kits (bill of materials).
+-- Stock changes made through apply_stock_movements are recorded in stock_movements,
+-- and the item (or lot) quantities are updated in the same transaction.
+
+create table if not exists stock_movements (
+  id bigint generated by default as identity primary key,
+  item_id bigint not null references items (id) on delete cascade,
+  lot_id bigint references item_lots (id) on delete set null,
+  quantity integer not null check (quantity <> 0),
+  type text not null check (length(trim(type)) > 0),
+  unit_cost numeric(12, 2),
+  reference_type text,
+  reference_id bigint,
+  note text,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists stock_movements_item_id_idx on stock_movements (item_id, created_at desc);
+create index if not exists stock_movements_reference_idx on stock_movements (reference_type, reference_id);
+
+-- apply_stock_movements applies all movements or none of them.
+-- Each movement is an object with item_id, quantity (positive adds stock, negative removes it), type
+-- and optionally lot_id, unit_cost, reference_type, reference_id and note.
+-- Returns the recorded movements.
+create or replace function apply_stock_movements(movements jsonb)
+returns jsonb
+language plpgsql
+as $$
+declare
+  movement jsonb;
+  movement_quantity integer;
+  target items%rowtype;
+  target_lot item_lots%rowtype;
+  recorded stock_movements%rowtype;
+  result jsonb := '[]'::jsonb;
+begin
+  if jsonb_typeof(movements) is distinct from 'array' or jsonb_array_length(movements) = 0 then
+    raise exception 'At least one stock movement is required';
+  end if;
+
+  -- Lock the items in id order, so concurrent operations on the same items can not deadlock
+  perform 1 from items
+  where id in (select (value->>'item_id')::bigint from jsonb_array_elements(movements))
+  order by id
+  for update;
+
+  for movement in select value from jsonb_array_elements(movements) loop
+    movement_quantity := (movement->>'quantity')::integer;
+    if movement_quantity is null or movement_quantity = 0 then
+      raise exception 'Stock movement quantity must be a non-zero number';
+    end if;
+
+    if coalesce(trim(movement->>'type'), '') = '' then
+      raise exception 'Stock movement type is required';
+    end if;
+
+    select * into target from items
+    where id = (movement->>'item_id')::bigint and deleted_at is null;
+    if not found then
+      raise exception 'Item % not found', movement->>'item_id' using errcode = 'P0002';
+    end if;
+
+    if target.serialized then
+      raise exception 'The stock of serialised item % is changed through its serials', target.name;
+    end if;
+
+    if movement->>'lot_id' is not null then
+      select * into target_lot from item_lots
+      where id = (movement->>'lot_id')::bigint and item_id = target.id
+      for update;
+      if not found then
+        raise exception 'Lot % not found for item %', movement->>'lot_id', target.name using errcode = 'P0002';
+      end if;
+
+      if target_lot.quantity + movement_quantity < 0 then
+        raise exception 'Insufficient stock in lot % of item %: % on hand, % requested',
+          target_lot.lot_number, target.name, target_lot.quantity, -movement_quantity;
+      end if;
+
+      -- The item quantity follows from the lot quantities
+      update item_lots
+      set quantity = quantity + movement_quantity, updated_at = now()
+      where id = target_lot.id;
+    else
+      if target.quantity + movement_quantity < 0 then
+        raise exception 'Insufficient stock of item %: % on hand, % requested',
+          target.name, target.quantity, -movement_quantity;
+      end if;
+
+      update items
+      set quantity = quantity + movement_quantity, updated_at = now()
+      where id = target.id;
+    end if;
+
+    insert into stock_movements (item_id, lot_id, quantity, type, unit_cost, reference_type, reference_id, note)
+    values (
+      target.id,
+      (movement->>'lot_id')::bigint,
+      movement_quantity,
+      movement->>'type',
+      (movement->>'unit_cost')::numeric,
+      movement->>'reference_type',
+      (movement->>'reference_id')::bigint,
+      movement->>'note'
+    )
+    returning * into recorded;
+
+    result := result || jsonb_build_array(to_jsonb(recorded));
+  end loop;
+
+  return result;
+end;
+$$;
+
+create table if not exists kit_components (
+  id bigint generated by default as identity primary key,
+  kit_item_id bigint not null references items (id) on delete cascade,
+  component_item_id bigint not null references items (id) on delete restrict,
+  quantity integer not null check (quantity > 0),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  check (kit_item_id <> component_item_id)
+);
+
+create unique index if not exists kit_components_kit_component_key on kit_components (kit_item_id, component_item_id);
+create index if not exists kit_components_component_item_id_idx on kit_components (component_item_id);
+
+-- A kit can not contain itself, also not through the components of its components
+create or replace function prevent_kit_component_cycle()
+returns trigger
+language plpgsql
+as $$
+begin
+  if exists (
+    with recursive sub_components as (
+      select component_item_id from kit_components where kit_item_id = new.component_item_id
+      union
+      select kc.component_item_id
+      from kit_components kc
+      join sub_components sc on kc.kit_item_id = sc.component_item_id
+    )
+    select 1 from sub_components where component_item_id = new.kit_item_id
+  ) then
+    raise exception 'A kit can not contain itself as a component';
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists kit_components_prevent_cycle on kit_components;
+create trigger kit_components_prevent_cycle
+  before insert or update of kit_item_id, component_item_id on kit_components
+  for each row execute function prevent_kit_component_cycle();
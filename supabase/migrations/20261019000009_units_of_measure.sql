@@ -0,0 +1,184 @@
+-- Units of measure.
+-- Every item has a base unit in which its stock is kept. Other units are converted
+-- to the base unit with a factor per item, e.g. 1 box = 100 pcs for screws.
+
+create table if not exists units_of_measure (
+  id bigint generated by default as identity primary key,
+  code text not null unique check (code ~ '^[a-z][a-z0-9_]*$'),
+  name text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+insert into units_of_measure (code, name) values
+  ('pcs', 'Pieces'),
+  ('box', 'Box'),
+  ('pack', 'Pack'),
+  ('pair', 'Pair'),
+  ('kg', 'Kilogram'),
+  ('g', 'Gram'),
+  ('l', 'Litre'),
+  ('ml', 'Millilitre'),
+  ('m', 'Metre'),
+  ('cm', 'Centimetre')
+on conflict (code) do nothing;
+
+alter table items add column if not exists base_unit text not null default 'pcs'
+  references units_of_measure (code) on update cascade;
+
+create table if not exists item_units (
+  id bigint generated by default as identity primary key,
+  item_id bigint not null references items (id) on delete cascade,
+  unit_code text not null references units_of_measure (code) on update cascade,
+  -- The number of base units in one of this unit
+  factor numeric(14, 4) not null check (factor > 0),
+  is_purchase_unit boolean not null default false,
+  is_sales_unit boolean not null default false,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create unique index if not exists item_units_item_unit_key on item_units (item_id, unit_code);
+-- An item has at most one default purchase unit and one default sales unit
+create unique index if not exists item_units_purchase_unit_key on item_units (item_id) where is_purchase_unit;
+create unique index if not exists item_units_sales_unit_key on item_units (item_id) where is_sales_unit;
+
+-- item_unit_factor returns the number of base units in one of the unit for the item.
+-- The base unit itself has the factor 1.
+create or replace function item_unit_factor(target_item_id bigint, unit text)
+returns numeric
+language plpgsql
+stable
+as $$
+declare
+  item_base_unit text;
+  unit_factor numeric;
+begin
+  select base_unit into item_base_unit from items where id = target_item_id;
+  if unit is null or unit = item_base_unit then
+    return 1;
+  end if;
+
+  select factor into unit_factor from item_units where item_id = target_item_id and unit_code = unit;
+  if unit_factor is null then
+    raise exception 'Unit % is not defined for item %', unit, target_item_id using errcode = 'P0002';
+  end if;
+
+  return unit_factor;
+end;
+$$;
+
+alter table stock_movements add column if not exists unit text;
+alter table stock_movements add column if not exists unit_quantity numeric(14, 4);
+-- Unit costs per base unit can have more decimals than prices, e.g. a box of 100 at 3.33
+alter table stock_movements alter column unit_cost type numeric(14, 4);
+
+-- apply_stock_movements now also accepts a unit per movement.
+-- With a unit the quantity (or unit_quantity) and unit_cost are given in that unit,
+-- and are converted to the base unit of the item. The quantity in the base unit must be whole.
+create or replace function apply_stock_movements(movements jsonb)
+returns jsonb
+language plpgsql
+as $$
+declare
+  movement jsonb;
+  movement_unit text;
+  movement_unit_quantity numeric;
+  movement_factor numeric;
+  movement_quantity integer;
+  target items%rowtype;
+  target_lot item_lots%rowtype;
+  recorded stock_movements%rowtype;
+  result jsonb := '[]'::jsonb;
+begin
+  if jsonb_typeof(movements) is distinct from 'array' or jsonb_array_length(movements) = 0 then
+    raise exception 'At least one stock movement is required';
+  end if;
+
+  -- Lock the items in id order, so concurrent operations on the same items can not deadlock
+  perform 1 from items
+  where id in (select (value->>'item_id')::bigint from jsonb_array_elements(movements))
+  order by id
+  for update;
+
+  for movement in select value from jsonb_array_elements(movements) loop
+    if coalesce(trim(movement->>'type'), '') = '' then
+      raise exception 'Stock movement type is required';
+    end if;
+
+    select * into target from items
+    where id = (movement->>'item_id')::bigint and deleted_at is null;
+    if not found then
+      raise exception 'Item % not found', movement->>'item_id' using errcode = 'P0002';
+    end if;
+
+    if target.serialized then
+      raise exception 'The stock of serialised item % is changed through its serials', target.name;
+    end if;
+
+    movement_unit := coalesce(nullif(movement->>'unit', ''), target.base_unit);
+    movement_unit_quantity := coalesce((movement->>'unit_quantity')::numeric, (movement->>'quantity')::numeric);
+    movement_factor := item_unit_factor(target.id, movement_unit);
+
+    if movement_unit_quantity is null or movement_unit_quantity = 0 then
+      raise exception 'Stock movement quantity must be a non-zero number';
+    end if;
+
+    if movement_unit_quantity * movement_factor <> trunc(movement_unit_quantity * movement_factor) then
+      raise exception '% % of item % is not a whole number of %',
+        movement_unit_quantity, movement_unit, target.name, target.base_unit;
+    end if;
+    movement_quantity := (movement_unit_quantity * movement_factor)::integer;
+
+    if movement->>'lot_id' is not null then
+      select * into target_lot from item_lots
+      where id = (movement->>'lot_id')::bigint and item_id = target.id
+      for update;
+      if not found then
+        raise exception 'Lot % not found for item %', movement->>'lot_id', target.name using errcode = 'P0002';
+      end if;
+
+      if target_lot.quantity + movement_quantity < 0 then
+        raise exception 'Insufficient stock in lot % of item %: % on hand, % requested',
+          target_lot.lot_number, target.name, target_lot.quantity, -movement_quantity;
+      end if;
+
+      -- The item quantity follows from the lot quantities
+      update item_lots
+      set quantity = quantity + movement_quantity, updated_at = now()
+      where id = target_lot.id;
+    else
+      if target.quantity + movement_quantity < 0 then
+        raise exception 'Insufficient stock of item %: % on hand, % requested',
+          target.name, target.quantity, -movement_quantity;
+      end if;
+
+      update items
+      set quantity = quantity + movement_quantity, updated_at = now()
+      where id = target.id;
+    end if;
+
+    insert into stock_movements (
+      item_id, lot_id, quantity, unit, unit_quantity, type, unit_cost, reference_type, reference_id, note
+    )
+    values (
+      target.id,
+      (movement->>'lot_id')::bigint,
+      movement_quantity,
+      movement_unit,
+      movement_unit_quantity,
+      movement->>'type',
+      -- The unit cost is stored per base unit
+      round((movement->>'unit_cost')::numeric / movement_factor, 4),
+      movement->>'reference_type',
+      (movement->>'reference_id')::bigint,
+      movement->>'note'
+    )
+    returning * into recorded;
+
+    result := result || jsonb_build_array(to_jsonb(recorded));
+  end loop;
+
+  return result;
+end;
+$$;
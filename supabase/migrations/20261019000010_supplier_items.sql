@@ -0,0 +1,70 @@
+-- Supplier catalogue: the price and terms at which a supplier sells an item.
+-- The price is per unit of the row, which defaults to the base unit of the item.
+-- The preferred supplier of an item is mirrored to items.supplier_id and items.purchase_price.
+
+create table if not exists supplier_items (
+  id bigint generated by default as identity primary key,
+  supplier_id bigint not null references suppliers (id) on delete cascade,
+  item_id bigint not null references items (id) on delete cascade,
+  supplier_sku text,
+  price numeric(12, 2) not null check (price >= 0),
+  currency text not null default 'EUR' check (currency ~ '^[A-Z]{3}$'),
+  unit text references units_of_measure (code) on update cascade,
+  lead_time_days integer not null default 0 check (lead_time_days >= 0),
+  min_order_quantity integer not null default 1 check (min_order_quantity >= 1),
+  is_preferred boolean not null default false,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create unique index if not exists supplier_items_supplier_item_key on supplier_items (supplier_id, item_id);
+create unique index if not exists supplier_items_preferred_key on supplier_items (item_id) where is_preferred;
+create index if not exists supplier_items_item_id_idx on supplier_items (item_id);
+
+-- Existing item suppliers become the preferred catalogue entry of their items
+insert into supplier_items (supplier_id, item_id, price, is_preferred)
+select supplier_id, id, purchase_price, true
+from items
+where supplier_id is not null and deleted_at is null
+on conflict do nothing;
+
+-- Only one catalogue entry per item can be preferred, so marking one as preferred unmarks the others
+create or replace function unset_other_preferred_supplier_items()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.is_preferred then
+    update supplier_items
+    set is_preferred = false, updated_at = now()
+    where item_id = new.item_id and id <> new.id and is_preferred;
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists supplier_items_unset_other_preferred on supplier_items;
+create trigger supplier_items_unset_other_preferred
+  before insert or update of is_preferred on supplier_items
+  for each row execute function unset_other_preferred_supplier_items();
+
+create or replace function sync_item_preferred_supplier()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.is_preferred then
+    update items
+    set supplier_id = new.supplier_id,
+        purchase_price = round(new.price / item_unit_factor(new.item_id, new.unit), 2),
+        updated_at = now()
+    where id = new.item_id;
+  end if;
+  return null;
+end;
+$$;
+
+drop trigger if exists supplier_items_sync_item on supplier_items;
+create trigger supplier_items_sync_item
+  after insert or update on supplier_items
+  for each row execute function sync_item_preferred_supplier();
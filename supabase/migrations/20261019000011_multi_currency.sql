@@ -0,0 +1,168 @@
+-- Multi-currency pricing.
+-- Monetary amounts are stored as integer minor units (e.g. cents) with an ISO 4217 currency code.
+-- The old decimal columns (items.purchase_price, supplier_items.price) are kept, but are now
+-- derived from the minor units, so existing filters, search and facets keep working.
+-- Writing a decimal column still works and is converted to minor units.
+
+create table if not exists currencies (
+  code text primary key check (code ~ '^[A-Z]{3}$'),
+  name text not null,
+  -- The number of decimals of the minor unit, e.g. 2 for EUR and 0 for JPY
+  minor_unit smallint not null default 2 check (minor_unit between 0 and 4)
+);
+
+insert into currencies (code, name, minor_unit) values
+  ('EUR', 'Euro', 2),
+  ('USD', 'US Dollar', 2),
+  ('GBP', 'Pound Sterling', 2),
+  ('DKK', 'Danish Krone', 2),
+  ('SEK', 'Swedish Krona', 2),
+  ('NOK', 'Norwegian Krone', 2),
+  ('CHF', 'Swiss Franc', 2),
+  ('PLN', 'Zloty', 2),
+  ('CAD', 'Canadian Dollar', 2),
+  ('AUD', 'Australian Dollar', 2),
+  ('CNY', 'Yuan Renminbi', 2),
+  ('JPY', 'Yen', 0)
+on conflict (code) do nothing;
+
+-- One base_currency is worth rate quote_currency from the effective date on
+create table if not exists exchange_rates (
+  id bigint generated by default as identity primary key,
+  base_currency text not null references currencies (code),
+  quote_currency text not null references currencies (code),
+  rate numeric(18, 8) not null check (rate > 0),
+  effective_date date not null default current_date,
+  created_at timestamptz not null default now(),
+  check (base_currency <> quote_currency)
+);
+
+create unique index if not exists exchange_rates_pair_date_key on exchange_rates (base_currency, quote_currency, effective_date);
+
+-- Items
+
+alter table items add column if not exists purchase_price_minor bigint;
+alter table items add column if not exists purchase_currency text not null default 'EUR' references currencies (code);
+
+update items set purchase_price_minor = round(coalesce(purchase_price, 0) * 100)
+where purchase_price_minor is null;
+
+alter table items alter column purchase_price_minor set not null;
+
+create or replace function sync_item_purchase_price()
+returns trigger
+language plpgsql
+as $$
+declare
+  exponent smallint;
+begin
+  select minor_unit into exponent from currencies where code = new.purchase_currency;
+  if exponent is null then
+    raise exception 'Unknown currency %', new.purchase_currency using errcode = 'P0001';
+  end if;
+
+  -- The minor units are leading, unless only the decimal price was written
+  if tg_op = 'INSERT' then
+    if new.purchase_price_minor is null then
+      new.purchase_price_minor := round(coalesce(new.purchase_price, 0) * 10 ^ exponent);
+    end if;
+  elsif new.purchase_price_minor is not distinct from old.purchase_price_minor
+    and new.purchase_price is distinct from old.purchase_price then
+    new.purchase_price_minor := round(new.purchase_price * 10 ^ exponent);
+  end if;
+
+  new.purchase_price := new.purchase_price_minor / 10 ^ exponent;
+  return new;
+end;
+$$;
+
+drop trigger if exists items_sync_purchase_price on items;
+create trigger items_sync_purchase_price
+  before insert or update of purchase_price, purchase_price_minor, purchase_currency on items
+  for each row execute function sync_item_purchase_price();
+
+-- Supplier catalogue
+
+alter table supplier_items add column if not exists price_minor bigint;
+
+update supplier_items set price_minor = round(price * 100) where price_minor is null;
+
+alter table supplier_items alter column price_minor set not null;
+alter table supplier_items add constraint supplier_items_price_minor_check check (price_minor >= 0);
+alter table supplier_items add constraint supplier_items_currency_fkey foreign key (currency) references currencies (code);
+
+create or replace function sync_supplier_item_price()
+returns trigger
+language plpgsql
+as $$
+declare
+  exponent smallint;
+begin
+  select minor_unit into exponent from currencies where code = new.currency;
+  if exponent is null then
+    raise exception 'Unknown currency %', new.currency using errcode = 'P0001';
+  end if;
+
+  if tg_op = 'INSERT' then
+    if new.price_minor is null then
+      new.price_minor := round(coalesce(new.price, 0) * 10 ^ exponent);
+    end if;
+  elsif new.price_minor is not distinct from old.price_minor
+    and new.price is distinct from old.price then
+    new.price_minor := round(new.price * 10 ^ exponent);
+  end if;
+
+  new.price := new.price_minor / 10 ^ exponent;
+  return new;
+end;
+$$;
+
+drop trigger if exists supplier_items_sync_price on supplier_items;
+create trigger supplier_items_sync_price
+  before insert or update of price, price_minor, currency on supplier_items
+  for each row execute function sync_supplier_item_price();
+
+-- The preferred supplier price is mirrored to the item in minor units and currency
+create or replace function sync_item_preferred_supplier()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.is_preferred then
+    update items
+    set supplier_id = new.supplier_id,
+        purchase_currency = new.currency,
+        purchase_price_minor = round(new.price_minor / item_unit_factor(new.item_id, new.unit)),
+        updated_at = now()
+    where id = new.item_id;
+  end if;
+  return null;
+end;
+$$;
+
+-- Stock movements
+-- Unit costs per base unit can be fractions of a minor unit (a box of 100 at 3.33),
+-- so they stay decimal, but now carry the currency they are in.
+
+alter table stock_movements add column if not exists currency text references currencies (code);
+
+update stock_movements m set currency = i.purchase_currency
+from items i
+where i.id = m.item_id and m.currency is null;
+
+create or replace function default_stock_movement_currency()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.currency is null then
+    select purchase_currency into new.currency from items where id = new.item_id;
+  end if;
+  return new;
+end;
+$$;
+
+drop trigger if exists stock_movements_default_currency on stock_movements;
+create trigger stock_movements_default_currency
+  before insert on stock_movements
+  for each row execute function default_stock_movement_currency();
@@ -0,0 +1,125 @@
+-- Stocktakes (cycle counts).
+-- A stocktake snapshots the expected quantities of the items in its scope into count lines,
+-- counters enter what they counted, and approval posts the variances as adjustment movements.
+
+alter table items add column if not exists location text;
+
+create index if not exists items_location_idx on items (lower(location)) where deleted_at is null;
+
+create table if not exists stocktakes (
+  id bigint generated by default as identity primary key,
+  name text not null check (length(trim(name)) > 0),
+  scope text not null default 'full' check (scope in ('full', 'location', 'category')),
+  location text,
+  category_id bigint references categories (id) on delete restrict,
+  status text not null default 'counting' check (status in ('counting', 'review', 'approved', 'cancelled')),
+  note text,
+  approved_by text,
+  approved_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  check ((scope = 'location') = (location is not null)),
+  check ((scope = 'category') = (category_id is not null))
+);
+
+create index if not exists stocktakes_status_idx on stocktakes (status);
+
+-- A count line is an item, or a lot of an item for items with lots
+create table if not exists stocktake_lines (
+  id bigint generated by default as identity primary key,
+  stocktake_id bigint not null references stocktakes (id) on delete cascade,
+  item_id bigint not null references items (id) on delete cascade,
+  lot_id bigint references item_lots (id) on delete cascade,
+  expected_quantity integer not null,
+  -- Set during review, overrides the counts
+  counted_quantity integer check (counted_quantity >= 0),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create unique index if not exists stocktake_lines_item_lot_key on stocktake_lines (stocktake_id, item_id, coalesce(lot_id, 0));
+
+-- Every counter has one count per line, counting again replaces it
+create table if not exists stocktake_counts (
+  id bigint generated by default as identity primary key,
+  line_id bigint not null references stocktake_lines (id) on delete cascade,
+  counter text not null check (length(trim(counter)) > 0),
+  quantity integer not null check (quantity >= 0),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  unique (line_id, counter)
+);
+
+-- create_stocktake creates the stocktake and its count lines in one transaction.
+-- Serialised items are left out, as their stock follows from their serials.
+create or replace function create_stocktake(
+  stocktake_name text,
+  stocktake_scope text default 'full',
+  stocktake_location text default null,
+  stocktake_category_id bigint default null,
+  stocktake_note text default null
+)
+returns jsonb
+language plpgsql
+as $$
+declare
+  created stocktakes%rowtype;
+begin
+  insert into stocktakes (name, scope, location, category_id, note)
+  values (stocktake_name, stocktake_scope, stocktake_location, stocktake_category_id, stocktake_note)
+  returning * into created;
+
+  with scoped_items as (
+    select i.id, i.quantity
+    from items i
+    where i.deleted_at is null
+      and not i.serialized
+      and (
+        created.scope = 'full'
+        or (created.scope = 'location' and lower(i.location) = lower(created.location))
+        or (created.scope = 'category' and i.category_id in (select category_descendant_ids(created.category_id)))
+      )
+  )
+  insert into stocktake_lines (stocktake_id, item_id, lot_id, expected_quantity)
+  select created.id, l.item_id, l.id, l.quantity
+  from item_lots l
+  join scoped_items s on s.id = l.item_id
+  where l.quantity > 0
+  union all
+  select created.id, s.id, null, s.quantity
+  from scoped_items s
+  where not exists (select 1 from item_lots l where l.item_id = s.id and l.quantity > 0);
+
+  return to_jsonb(created);
+end;
+$$;
+
+-- approve_stocktake posts the adjustment movements and approves the stocktake in one transaction
+create or replace function approve_stocktake(stocktake_id bigint, approver text, movements jsonb)
+returns jsonb
+language plpgsql
+as $$
+declare
+  target stocktakes%rowtype;
+  recorded jsonb := '[]'::jsonb;
+begin
+  select * into target from stocktakes where id = stocktake_id for update;
+  if not found then
+    raise exception 'Stocktake % not found', stocktake_id using errcode = 'P0002';
+  end if;
+
+  if target.status <> 'review' then
+    raise exception 'Only stocktakes in review can be approved, stocktake % is %', target.name, target.status;
+  end if;
+
+  if jsonb_array_length(movements) > 0 then
+    recorded := apply_stock_movements(movements);
+  end if;
+
+  update stocktakes
+  set status = 'approved', approved_by = approver, approved_at = now(), updated_at = now()
+  where id = stocktake_id;
+
+  return recorded;
+end;
+$$;
@@ -0,0 +1,113 @@
+-- Stock reservations.
+-- Active reservations hold stock for an owner, so it is not promised twice.
+-- items.reserved_quantity is kept as the sum of the active reservations of the item,
+-- and items.available_quantity is what is left to promise.
+
+create table if not exists stock_reservations (
+  id bigint generated by default as identity primary key,
+  item_id bigint not null references items (id) on delete cascade,
+  quantity integer not null check (quantity > 0),
+  owner text not null check (length(trim(owner)) > 0),
+  reason text,
+  status text not null default 'active' check (status in ('active', 'released', 'expired', 'fulfilled')),
+  expires_at timestamptz,
+  reference_type text,
+  reference_id bigint,
+  released_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists stock_reservations_item_id_idx on stock_reservations (item_id) where status = 'active';
+create index if not exists stock_reservations_expires_at_idx on stock_reservations (expires_at) where status = 'active';
+create index if not exists stock_reservations_reference_idx on stock_reservations (reference_type, reference_id);
+
+alter table items add column if not exists reserved_quantity integer not null default 0;
+alter table items add column if not exists available_quantity integer generated always as (quantity - reserved_quantity) stored;
+
+-- A reservation can not hold more than is available.
+-- The item is locked, so concurrent reservations can not both take the last stock.
+create or replace function check_stock_reservation()
+returns trigger
+language plpgsql
+as $$
+declare
+  target items%rowtype;
+  reserved integer;
+begin
+  if new.status <> 'active' then
+    return new;
+  end if;
+
+  if tg_op = 'UPDATE' and old.status = 'active' and new.quantity <= old.quantity and new.item_id = old.item_id then
+    return new;
+  end if;
+
+  select * into target from items where id = new.item_id and deleted_at is null for update;
+  if not found then
+    raise exception 'Item % not found', new.item_id using errcode = 'P0002';
+  end if;
+
+  select coalesce(sum(quantity), 0) into reserved
+  from stock_reservations
+  where item_id = new.item_id and status = 'active' and id <> coalesce(new.id, 0);
+
+  if reserved + new.quantity > target.quantity then
+    raise exception 'Insufficient available stock of item %: % available, % requested',
+      target.name, greatest(target.quantity - reserved, 0), new.quantity;
+  end if;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists stock_reservations_check on stock_reservations;
+create trigger stock_reservations_check
+  before insert or update on stock_reservations
+  for each row execute function check_stock_reservation();
+
+create or replace function sync_item_reserved_quantity()
+returns trigger
+language plpgsql
+as $$
+declare
+  changed_item_id bigint;
+begin
+  for changed_item_id in
+    select distinct unnest(array[
+      case when tg_op <> 'DELETE' then new.item_id end,
+      case when tg_op <> 'INSERT' then old.item_id end
+    ])
+  loop
+    if changed_item_id is not null then
+      update items
+      set reserved_quantity = (
+        select coalesce(sum(quantity), 0) from stock_reservations
+        where item_id = changed_item_id and status = 'active'
+      )
+      where id = changed_item_id;
+    end if;
+  end loop;
+  return null;
+end;
+$$;
+
+drop trigger if exists stock_reservations_sync_item on stock_reservations;
+create trigger stock_reservations_sync_item
+  after insert or update of quantity, status, item_id or delete on stock_reservations
+  for each row execute function sync_item_reserved_quantity();
+
+-- expire_stock_reservations expires the active reservations that are past their expiry.
+-- Returns the expired reservations.
+create or replace function expire_stock_reservations()
+returns jsonb
+language sql
+as $$
+  with expired as (
+    update stock_reservations
+    set status = 'expired', released_at = now(), updated_at = now()
+    where status = 'active' and expires_at <= now()
+    returning *
+  )
+  select coalesce(jsonb_agg(to_jsonb(expired)), '[]'::jsonb) from expired;
+$$;
@@ -0,0 +1,166 @@
+-- Customers and sales orders.
+-- An order moves from draft to confirmed (the stock is reserved), picked and shipped (the stock is issued),
+-- and can be cancelled until it is shipped, which releases its reservations.
+
+create table if not exists customers (
+  id bigint generated by default as identity primary key,
+  name text not null check (length(trim(name)) > 0),
+  email text,
+  phone text,
+  address text,
+  vat_number text,
+  notes text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  deleted_at timestamptz
+);
+
+create index if not exists customers_name_idx on customers (lower(name)) where deleted_at is null;
+
+create table if not exists sales_orders (
+  id bigint generated by default as identity primary key,
+  customer_id bigint not null references customers (id) on delete restrict,
+  status text not null default 'draft' check (status in ('draft', 'confirmed', 'picked', 'shipped', 'cancelled')),
+  currency text not null default 'EUR' references currencies (code),
+  reference text,
+  notes text,
+  confirmed_at timestamptz,
+  picked_at timestamptz,
+  shipped_at timestamptz,
+  cancelled_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists sales_orders_customer_id_idx on sales_orders (customer_id);
+create index if not exists sales_orders_status_idx on sales_orders (status);
+
+create table if not exists sales_order_lines (
+  id bigint generated by default as identity primary key,
+  order_id bigint not null references sales_orders (id) on delete cascade,
+  item_id bigint not null references items (id) on delete restrict,
+  quantity integer not null check (quantity > 0),
+  -- In minor units of the order currency
+  unit_price_minor bigint not null default 0 check (unit_price_minor >= 0),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  unique (order_id, item_id)
+);
+
+-- lock_sales_order locks the order and checks that it has one of the statuses
+create or replace function lock_sales_order(target_order_id bigint, allowed_statuses text[], action text)
+returns sales_orders
+language plpgsql
+as $$
+declare
+  target sales_orders%rowtype;
+begin
+  select * into target from sales_orders where id = target_order_id for update;
+  if not found then
+    raise exception 'Sales order % not found', target_order_id using errcode = 'P0002';
+  end if;
+
+  if not target.status = any (allowed_statuses) then
+    raise exception 'Can not % sales order % while it is %', action, target_order_id, target.status;
+  end if;
+
+  return target;
+end;
+$$;
+
+-- confirm_sales_order reserves the stock of every line and confirms the order
+create or replace function confirm_sales_order(order_id bigint)
+returns jsonb
+language plpgsql
+as $$
+declare
+  target sales_orders%rowtype;
+begin
+  target := lock_sales_order(order_id, array['draft'], 'confirm');
+
+  if not exists (select 1 from sales_order_lines l where l.order_id = target.id) then
+    raise exception 'Sales order % has no lines', target.id;
+  end if;
+
+  -- The reservation trigger rejects lines with more than is available
+  insert into stock_reservations (item_id, quantity, owner, reason, reference_type, reference_id)
+  select l.item_id, l.quantity, 'Sales order ' || target.id, 'Sales order', 'sales_order_line', l.id
+  from sales_order_lines l
+  where l.order_id = target.id
+  order by l.item_id;
+
+  update sales_orders
+  set status = 'confirmed', confirmed_at = now(), updated_at = now()
+  where id = target.id
+  returning * into target;
+
+  return to_jsonb(target);
+end;
+$$;
+
+-- ship_sales_order fulfils the reservations and issues the stock of every line
+create or replace function ship_sales_order(order_id bigint)
+returns jsonb
+language plpgsql
+as $$
+declare
+  target sales_orders%rowtype;
+  movements jsonb;
+begin
+  target := lock_sales_order(order_id, array['picked'], 'ship');
+
+  update stock_reservations r
+  set status = 'fulfilled', released_at = now(), updated_at = now()
+  from sales_order_lines l
+  where l.order_id = target.id
+    and r.reference_type = 'sales_order_line' and r.reference_id = l.id
+    and r.status = 'active';
+
+  select jsonb_agg(jsonb_build_object(
+    'item_id', l.item_id,
+    'quantity', -l.quantity,
+    'type', 'issue',
+    'reference_type', 'sales_order',
+    'reference_id', target.id,
+    'note', 'Sales order ' || target.id
+  ) order by l.item_id)
+  into movements
+  from sales_order_lines l
+  where l.order_id = target.id;
+
+  perform apply_stock_movements(movements);
+
+  update sales_orders
+  set status = 'shipped', shipped_at = now(), updated_at = now()
+  where id = target.id
+  returning * into target;
+
+  return to_jsonb(target);
+end;
+$$;
+
+-- cancel_sales_order releases the reservations and cancels the order
+create or replace function cancel_sales_order(order_id bigint)
+returns jsonb
+language plpgsql
+as $$
+declare
+  target sales_orders%rowtype;
+begin
+  target := lock_sales_order(order_id, array['draft', 'confirmed', 'picked'], 'cancel');
+
+  update stock_reservations r
+  set status = 'released', released_at = now(), updated_at = now()
+  from sales_order_lines l
+  where l.order_id = target.id
+    and r.reference_type = 'sales_order_line' and r.reference_id = l.id
+    and r.status = 'active';
+
+  update sales_orders
+  set status = 'cancelled', cancelled_at = now(), updated_at = now()
+  where id = target.id
+  returning * into target;
+
+  return to_jsonb(target);
+end;
+$$;
@@ -0,0 +1,218 @@
+-- Return authorisations (RMA) from customers and to suppliers.
+-- A return is open while its lines are added and inspected. Completing it records the stock movements
+-- of the inspection outcomes in one transaction. Customer returns link to the original sales order and
+-- its lines. There are no purchase orders yet, so supplier returns keep the purchase order reference as text.
+
+create table if not exists returns (
+  id bigint generated by default as identity primary key,
+  type text not null check (type in ('customer', 'supplier')),
+  status text not null default 'open' check (status in ('open', 'completed', 'cancelled')),
+  customer_id bigint references customers (id) on delete restrict,
+  sales_order_id bigint references sales_orders (id) on delete restrict,
+  supplier_id bigint references suppliers (id) on delete restrict,
+  purchase_order_reference text,
+  reason text not null check (reason in ('damaged', 'defective', 'wrong_item', 'not_as_described', 'unwanted', 'expired', 'other')),
+  notes text,
+  completed_at timestamptz,
+  cancelled_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  check (
+    (type = 'customer' and customer_id is not null and supplier_id is null and purchase_order_reference is null)
+    or (type = 'supplier' and supplier_id is not null and customer_id is null and sales_order_id is null)
+  )
+);
+
+create index if not exists returns_status_idx on returns (status);
+create index if not exists returns_sales_order_id_idx on returns (sales_order_id);
+
+create table if not exists return_lines (
+  id bigint generated by default as identity primary key,
+  return_id bigint not null references returns (id) on delete cascade,
+  item_id bigint not null references items (id) on delete restrict,
+  lot_id bigint references item_lots (id) on delete restrict,
+  quantity integer not null check (quantity > 0),
+  sales_order_line_id bigint references sales_order_lines (id) on delete restrict,
+  -- Overrides the reason of the return for this line
+  reason text check (reason in ('damaged', 'defective', 'wrong_item', 'not_as_described', 'unwanted', 'expired', 'other')),
+  outcome text check (outcome in ('restock', 'scrap', 'return_to_supplier')),
+  inspection_note text,
+  inspected_by text,
+  inspected_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists return_lines_return_id_idx on return_lines (return_id);
+create index if not exists return_lines_sales_order_line_id_idx on return_lines (sales_order_line_id);
+
+-- check_return makes sure the sales order of a customer return belongs to the customer and has shipped
+create or replace function check_return()
+returns trigger
+language plpgsql
+as $$
+declare
+  source_order sales_orders%rowtype;
+begin
+  if new.sales_order_id is null then
+    return new;
+  end if;
+
+  select * into source_order from sales_orders where id = new.sales_order_id;
+  if not found then
+    raise exception 'Sales order % not found', new.sales_order_id using errcode = 'P0002';
+  end if;
+
+  if source_order.customer_id <> new.customer_id then
+    raise exception 'Sales order % does not belong to customer %', source_order.id, new.customer_id;
+  end if;
+
+  if source_order.status <> 'shipped' then
+    raise exception 'Sales order % has not been shipped', source_order.id;
+  end if;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists check_return on returns;
+create trigger check_return
+before insert or update of customer_id, sales_order_id on returns
+for each row execute function check_return();
+
+-- check_return_line makes sure a line of a sales order is not returned more often than it was shipped
+create or replace function check_return_line()
+returns trigger
+language plpgsql
+as $$
+declare
+  target returns%rowtype;
+  source_line sales_order_lines%rowtype;
+  already_returned integer;
+begin
+  select * into target from returns where id = new.return_id;
+
+  if new.sales_order_line_id is null then
+    return new;
+  end if;
+
+  if target.type <> 'customer' then
+    raise exception 'Only customer returns can return a sales order line';
+  end if;
+
+  -- Lock the sales order line, so concurrent returns of the same line are checked one after the other
+  select * into source_line from sales_order_lines where id = new.sales_order_line_id for update;
+  if not found then
+    raise exception 'Sales order line % not found', new.sales_order_line_id using errcode = 'P0002';
+  end if;
+
+  if target.sales_order_id is distinct from source_line.order_id then
+    raise exception 'Sales order line % does not belong to the sales order of return %', source_line.id, target.id;
+  end if;
+
+  if source_line.item_id <> new.item_id then
+    raise exception 'Sales order line % is for another item', source_line.id;
+  end if;
+
+  select coalesce(sum(l.quantity), 0) into already_returned
+  from return_lines l
+  join returns r on r.id = l.return_id
+  where l.sales_order_line_id = source_line.id
+    and l.id is distinct from new.id
+    and r.status <> 'cancelled';
+
+  if already_returned + new.quantity > source_line.quantity then
+    raise exception 'Can not return % of sales order line %: % shipped and % already returned',
+      new.quantity, source_line.id, source_line.quantity, already_returned;
+  end if;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists check_return_line on return_lines;
+create trigger check_return_line
+before insert or update of item_id, quantity, sales_order_line_id on return_lines
+for each row execute function check_return_line();
+
+-- complete_return applies the stock movements of the inspected lines and completes the return.
+-- Goods returned by a customer are received into stock, and scrapped or sent on to the supplier when
+-- that is the outcome. Goods returned to a supplier leave the stock unless the outcome is restock.
+-- Returns the completed return and the recorded movements.
+create or replace function complete_return(return_id bigint)
+returns jsonb
+language plpgsql
+as $$
+declare
+  target returns%rowtype;
+  line return_lines%rowtype;
+  uninspected integer;
+  movement_note text;
+  movements jsonb := '[]'::jsonb;
+  recorded jsonb := '[]'::jsonb;
+begin
+  select * into target from returns where id = return_id for update;
+  if not found then
+    raise exception 'Return % not found', return_id using errcode = 'P0002';
+  end if;
+
+  if target.status <> 'open' then
+    raise exception 'Can not complete return % while it is %', target.id, target.status;
+  end if;
+
+  if not exists (select 1 from return_lines l where l.return_id = target.id) then
+    raise exception 'Return % has no lines', target.id;
+  end if;
+
+  select count(*) into uninspected from return_lines l where l.return_id = target.id and l.outcome is null;
+  if uninspected > 0 then
+    raise exception 'Return % has % lines without an inspection outcome', target.id, uninspected;
+  end if;
+
+  if target.type = 'customer' then
+    movement_note := 'Customer return ' || target.id
+      || coalesce(' of sales order ' || target.sales_order_id, '');
+  else
+    movement_note := 'Supplier return ' || target.id
+      || coalesce(' of purchase order ' || target.purchase_order_reference, '');
+  end if;
+
+  for line in select * from return_lines l where l.return_id = target.id order by l.id loop
+    if target.type = 'customer' then
+      movements := movements || jsonb_build_array(jsonb_build_object(
+        'item_id', line.item_id,
+        'lot_id', line.lot_id,
+        'quantity', line.quantity,
+        'type', 'customer_return',
+        'reference_type', 'return',
+        'reference_id', target.id,
+        'note', movement_note
+      ));
+    end if;
+
+    if line.outcome in ('scrap', 'return_to_supplier') then
+      movements := movements || jsonb_build_array(jsonb_build_object(
+        'item_id', line.item_id,
+        'lot_id', line.lot_id,
+        'quantity', -line.quantity,
+        'type', case line.outcome when 'scrap' then 'scrap' else 'supplier_return' end,
+        'reference_type', 'return',
+        'reference_id', target.id,
+        'note', movement_note
+      ));
+    end if;
+  end loop;
+
+  -- A supplier return where everything is kept does not move any stock
+  if jsonb_array_length(movements) > 0 then
+    recorded := apply_stock_movements(movements);
+  end if;
+
+  update returns
+  set status = 'completed', completed_at = now(), updated_at = now()
+  where id = target.id
+  returning * into target;
+
+  return jsonb_build_object('return', to_jsonb(target), 'movements', recorded);
+end;
+$$;
@@ -0,0 +1,70 @@
+-- Webhook subscriptions and their delivery log.
+-- Every event is queued as a delivery per matching subscription. The delivery worker claims due deliveries,
+-- sends them signed with the secret of the subscription and retries failures with exponential backoff.
+
+create table if not exists webhook_subscriptions (
+  id bigint generated by default as identity primary key,
+  url text not null check (url ~ '^https?://'),
+  secret text not null check (length(secret) >= 16),
+  -- The event types to deliver, or '*' for all of them
+  event_types text[] not null check (cardinality(event_types) > 0),
+  active boolean not null default true,
+  description text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create table if not exists webhook_deliveries (
+  id bigint generated by default as identity primary key,
+  subscription_id bigint not null references webhook_subscriptions (id) on delete cascade,
+  event_id text not null,
+  event_type text not null,
+  payload jsonb not null,
+  status text not null default 'pending' check (status in ('pending', 'succeeded', 'failed')),
+  attempts integer not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_attempt_at timestamptz,
+  last_status_code integer,
+  last_error text,
+  delivered_at timestamptz,
+  -- The delivery this one replays
+  replay_of bigint references webhook_deliveries (id) on delete set null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists webhook_deliveries_due_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
+create index if not exists webhook_deliveries_subscription_idx on webhook_deliveries (subscription_id, created_at desc);
+
+-- claim_webhook_deliveries returns the due deliveries with the URL and secret of their subscription.
+-- The claimed deliveries are leased by moving their next attempt, so concurrent workers do not send them twice.
+-- A delivery whose worker stops before recording the result is sent again when the lease runs out.
+create or replace function claim_webhook_deliveries(batch_size integer, lease_seconds integer)
+returns jsonb
+language plpgsql
+as $$
+declare
+  claimed jsonb;
+begin
+  with due as (
+    select d.id
+    from webhook_deliveries d
+    where d.status = 'pending' and d.next_attempt_at <= now()
+    order by d.next_attempt_at, d.id
+    limit batch_size
+    for update skip locked
+  ), leased as (
+    update webhook_deliveries d
+    set next_attempt_at = now() + make_interval(secs => lease_seconds)
+    from due
+    where d.id = due.id
+    returning d.*
+  )
+  select coalesce(jsonb_agg(to_jsonb(leased) || jsonb_build_object('url', s.url, 'secret', s.secret) order by leased.id), '[]'::jsonb)
+  into claimed
+  from leased
+  join webhook_subscriptions s on s.id = leased.subscription_id;
+
+  return claimed;
+end;
+$$;
@@ -0,0 +1,175 @@
+-- Transactional outbox of domain events.
+-- Triggers on items and suppliers write the events in the same transaction as the change, so an event is
+-- recorded exactly when its change is committed. This includes stock changed by database functions, such as
+-- shipping a sales order. The dispatcher delivers every event at least once to each registered sink.
+--
+-- stock.low is recorded when the quantity of an item drops to or below the low stock threshold, which is 5
+-- unless set with: alter database postgres set inventory.low_stock_threshold = '10';
+
+create table if not exists outbox_events (
+  id bigint generated by default as identity primary key,
+  type text not null,
+  entity_type text not null,
+  entity_id bigint not null,
+  payload jsonb not null,
+  -- The sinks that received the event. It is dispatched when every registered sink received it.
+  delivered_to text[] not null default '{}',
+  dispatched_at timestamptz,
+  attempts integer not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_error text,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists outbox_events_pending_idx on outbox_events (next_attempt_at, id) where dispatched_at is null;
+create index if not exists outbox_events_entity_idx on outbox_events (entity_type, entity_id, id);
+
+-- record_event adds an event to the outbox
+create or replace function record_event(event_type text, event_entity_type text, event_entity_id bigint, event_payload jsonb)
+returns void
+language sql
+as $$
+  insert into outbox_events (type, entity_type, entity_id, payload)
+  values (event_type, event_entity_type, event_entity_id, event_payload);
+$$;
+
+-- item_event_payload returns the item as it is sent in events, without the search vector
+create or replace function item_event_payload(item items)
+returns jsonb
+language sql
+stable
+as $$
+  select to_jsonb(item) - 'search_vector';
+$$;
+
+create or replace function record_item_events()
+returns trigger
+language plpgsql
+as $$
+declare
+  threshold integer := coalesce(nullif(current_setting('inventory.low_stock_threshold', true), '')::integer, 5);
+  stock_level jsonb;
+  -- The columns that change with the stock, which are published as stock.changed instead of item.updated
+  stock_columns text[] := array['quantity', 'reserved_quantity', 'available_quantity', 'updated_at', 'search_vector'];
+begin
+  if tg_op = 'INSERT' then
+    perform record_event('item.created', 'item', new.id, item_event_payload(new));
+    return null;
+  end if;
+
+  if tg_op = 'DELETE' then
+    if old.deleted_at is null then
+      perform record_event('item.deleted', 'item', old.id, jsonb_build_object('id', old.id, 'name', old.name, 'sku', old.sku));
+    end if;
+    return null;
+  end if;
+
+  if old.deleted_at is null and new.deleted_at is not null then
+    perform record_event('item.deleted', 'item', new.id, jsonb_build_object('id', new.id, 'name', new.name, 'sku', new.sku));
+    return null;
+  end if;
+
+  -- Changes to deleted items are not published
+  if new.deleted_at is not null then
+    return null;
+  end if;
+
+  if (to_jsonb(new) - stock_columns) is distinct from (to_jsonb(old) - stock_columns) then
+    perform record_event('item.updated', 'item', new.id, item_event_payload(new));
+  end if;
+
+  if new.quantity is distinct from old.quantity or new.reserved_quantity is distinct from old.reserved_quantity then
+    stock_level := jsonb_build_object(
+      'item_id', new.id,
+      'name', new.name,
+      'sku', new.sku,
+      'category_id', new.category_id,
+      'quantity', new.quantity,
+      'previous_quantity', old.quantity,
+      'reserved_quantity', new.reserved_quantity,
+      'available_quantity', new.available_quantity,
+      'low_stock_threshold', threshold
+    );
+
+    perform record_event('stock.changed', 'item', new.id, stock_level);
+
+    if new.quantity <= threshold and old.quantity > threshold then
+      perform record_event('stock.low', 'item', new.id, stock_level);
+    end if;
+  end if;
+
+  return null;
+end;
+$$;
+
+drop trigger if exists record_item_events on items;
+create trigger record_item_events
+after insert or update or delete on items
+for each row execute function record_item_events();
+
+create or replace function record_supplier_events()
+returns trigger
+language plpgsql
+as $$
+begin
+  if tg_op = 'INSERT' then
+    perform record_event('supplier.created', 'supplier', new.id, to_jsonb(new));
+    return null;
+  end if;
+
+  if tg_op = 'DELETE' then
+    if old.deleted_at is null then
+      perform record_event('supplier.deleted', 'supplier', old.id, jsonb_build_object('id', old.id, 'name', old.name));
+    end if;
+    return null;
+  end if;
+
+  if old.deleted_at is null and new.deleted_at is not null then
+    perform record_event('supplier.deleted', 'supplier', new.id, jsonb_build_object('id', new.id, 'name', new.name));
+    return null;
+  end if;
+
+  if new.deleted_at is null and (to_jsonb(new) - 'updated_at') is distinct from (to_jsonb(old) - 'updated_at') then
+    perform record_event('supplier.updated', 'supplier', new.id, to_jsonb(new));
+  end if;
+
+  return null;
+end;
+$$;
+
+drop trigger if exists record_supplier_events on suppliers;
+create trigger record_supplier_events
+after insert or update or delete on suppliers
+for each row execute function record_supplier_events();
+
+-- claim_outbox_events returns the events that are due for dispatching.
+-- The claimed events are leased by moving their next attempt, so concurrent dispatchers do not take the same
+-- events. An event whose dispatcher stops before recording the result is dispatched again when the lease runs out.
+create or replace function claim_outbox_events(batch_size integer, lease_seconds integer)
+returns jsonb
+language plpgsql
+as $$
+declare
+  claimed jsonb;
+begin
+  with due as (
+    select e.id
+    from outbox_events e
+    where e.dispatched_at is null and e.next_attempt_at <= now()
+    order by e.id
+    limit batch_size
+    for update skip locked
+  ), leased as (
+    update outbox_events e
+    set next_attempt_at = now() + make_interval(secs => lease_seconds)
+    from due
+    where e.id = due.id
+    returning e.*
+  )
+  select coalesce(jsonb_agg(to_jsonb(leased) order by leased.id), '[]'::jsonb)
+  into claimed
+  from leased;
+
+  return claimed;
+end;
+$$;
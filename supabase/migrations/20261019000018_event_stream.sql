@@ -0,0 +1,65 @@
+-- Event stream filters.
+-- The stream can be filtered by category, so item.deleted events now include the category of the item,
+-- like the other item events.
+
+create or replace function record_item_events()
+returns trigger
+language plpgsql
+as $$
+declare
+  threshold integer := coalesce(nullif(current_setting('inventory.low_stock_threshold', true), '')::integer, 5);
+  stock_level jsonb;
+  -- The columns that change with the stock, which are published as stock.changed instead of item.updated
+  stock_columns text[] := array['quantity', 'reserved_quantity', 'available_quantity', 'updated_at', 'search_vector'];
+begin
+  if tg_op = 'INSERT' then
+    perform record_event('item.created', 'item', new.id, item_event_payload(new));
+    return null;
+  end if;
+
+  if tg_op = 'DELETE' then
+    if old.deleted_at is null then
+      perform record_event('item.deleted', 'item', old.id, jsonb_build_object('id', old.id, 'name', old.name, 'sku', old.sku, 'category_id', old.category_id));
+    end if;
+    return null;
+  end if;
+
+  if old.deleted_at is null and new.deleted_at is not null then
+    perform record_event('item.deleted', 'item', new.id, jsonb_build_object('id', new.id, 'name', new.name, 'sku', new.sku, 'category_id', new.category_id));
+    return null;
+  end if;
+
+  -- Changes to deleted items are not published
+  if new.deleted_at is not null then
+    return null;
+  end if;
+
+  if (to_jsonb(new) - stock_columns) is distinct from (to_jsonb(old) - stock_columns) then
+    perform record_event('item.updated', 'item', new.id, item_event_payload(new));
+  end if;
+
+  if new.quantity is distinct from old.quantity or new.reserved_quantity is distinct from old.reserved_quantity then
+    stock_level := jsonb_build_object(
+      'item_id', new.id,
+      'name', new.name,
+      'sku', new.sku,
+      'category_id', new.category_id,
+      'quantity', new.quantity,
+      'previous_quantity', old.quantity,
+      'reserved_quantity', new.reserved_quantity,
+      'available_quantity', new.available_quantity,
+      'low_stock_threshold', threshold
+    );
+
+    perform record_event('stock.changed', 'item', new.id, stock_level);
+
+    if new.quantity <= threshold and old.quantity > threshold then
+      perform record_event('stock.low', 'item', new.id, stock_level);
+    end if;
+  end if;
+
+  return null;
+end;
+$$;
+
+create index if not exists outbox_events_category_idx on outbox_events ((payload->>'category_id'), id) where entity_type = 'item';
@@ -0,0 +1,67 @@
+-- Purging of old records.
+-- Deleted items and suppliers are only marked with deleted_at, and the outbox and the webhook delivery log grow
+-- with every change. purge_records removes the records that are older than the retention period:
+--   * items and suppliers that were deleted before it, unless sales orders, returns or kits still refer to them
+--   * outbox events that were dispatched before it
+--   * webhook deliveries that succeeded or failed before it
+-- The history of a purged item, such as its stock movements and lots, is removed with it.
+
+create or replace function purge_records(retention_days integer, dry_run boolean default false)
+returns jsonb
+language plpgsql
+as $$
+declare
+  cutoff timestamptz;
+  purged_items bigint[];
+  purged_suppliers bigint[];
+  outbox_count bigint;
+  delivery_count bigint;
+begin
+  if retention_days is null or retention_days < 0 then
+    raise exception 'retention_days must be at least 0' using errcode = 'P0001';
+  end if;
+
+  cutoff := now() - make_interval(days => retention_days);
+
+  select coalesce(array_agg(i.id), '{}')
+  into purged_items
+  from items i
+  where i.deleted_at is not null and i.deleted_at < cutoff
+    and not exists (select 1 from sales_order_lines l where l.item_id = i.id)
+    and not exists (select 1 from return_lines l where l.item_id = i.id)
+    and not exists (select 1 from kit_components k where k.component_item_id = i.id);
+
+  -- Suppliers of items that are kept are kept as well
+  select coalesce(array_agg(s.id), '{}')
+  into purged_suppliers
+  from suppliers s
+  where s.deleted_at is not null and s.deleted_at < cutoff
+    and not exists (select 1 from items i where i.supplier_id = s.id and not (i.id = any (purged_items)))
+    and not exists (select 1 from returns r where r.supplier_id = s.id);
+
+  select count(*) into outbox_count
+  from outbox_events
+  where dispatched_at is not null and dispatched_at < cutoff;
+
+  select count(*) into delivery_count
+  from webhook_deliveries
+  where status in ('succeeded', 'failed') and updated_at < cutoff;
+
+  if not dry_run then
+    delete from items where id = any (purged_items);
+    delete from supplier_contact_information where supplier_id = any (purged_suppliers);
+    delete from suppliers where id = any (purged_suppliers);
+    delete from outbox_events where dispatched_at is not null and dispatched_at < cutoff;
+    delete from webhook_deliveries where status in ('succeeded', 'failed') and updated_at < cutoff;
+  end if;
+
+  return jsonb_build_object(
+    'cutoff', cutoff,
+    'dry_run', dry_run,
+    'items', cardinality(purged_items),
+    'suppliers', cardinality(purged_suppliers),
+    'outbox_events', outbox_count,
+    'webhook_deliveries', delivery_count
+  );
+end;
+$$;
@@ -0,0 +1,69 @@
+-- Escaping of search highlights.
+-- ts_headline copies the text around the matches as it is, so markup in an item, such as a name of
+-- <img src=x onerror=alert(1)>, was returned inside the highlights. The text is now HTML-escaped before the
+-- matches are wrapped in <mark>, so the highlights only contain the <mark> tags as markup.
+
+-- html_escape escapes the characters that are special in HTML
+create or replace function html_escape(value text)
+returns text
+language sql
+immutable
+as $$
+  select replace(replace(replace(replace(replace(value, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;');
+$$;
+
+create or replace function search_items(
+  search_query text,
+  result_limit integer,
+  result_offset integer,
+  filter_category text default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5
+)
+returns table (item jsonb, rank real, highlights jsonb, total_count bigint)
+language sql
+stable
+as $$
+  with query as (
+    select websearch_to_tsquery('english', coalesce(search_query, '')) as tsq
+  ),
+  matches as (
+    select
+      i.*,
+      query.tsq as tsq,
+      (
+        ts_rank(i.search_vector, query.tsq) * 2
+        + word_similarity(coalesce(search_query, ''), i.name)
+        + word_similarity(coalesce(search_query, ''), coalesce(i.category, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.description, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.notes, '')) * 0.25
+      )::real as search_rank
+    from item_search_matches(search_query) i, query
+    where (filter_category is null or i.category = filter_category)
+      and (filter_supplier_id is null or i.supplier_id = filter_supplier_id)
+      and (filter_min_price is null or i.purchase_price >= filter_min_price)
+      and (filter_max_price is null or i.purchase_price < filter_max_price)
+      and (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status)
+  )
+  select
+    to_jsonb(m) - 'search_vector' - 'tsq' - 'search_rank' as item,
+    m.search_rank as rank,
+    jsonb_strip_nulls(jsonb_build_object(
+      'name', case when to_tsvector('english', coalesce(m.name, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.name), m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'category', case when to_tsvector('english', coalesce(m.category, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.category), m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'description', case when to_tsvector('english', coalesce(m.description, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.description), m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end,
+      'notes', case when to_tsvector('english', coalesce(m.notes, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.notes), m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end
+    )) as highlights,
+    count(*) over () as total_count
+  from matches m
+  order by m.search_rank desc, m.name asc, m.id asc
+  limit result_limit
+  offset result_offset;
+$$;
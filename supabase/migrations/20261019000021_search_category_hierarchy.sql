@@ -0,0 +1,165 @@
+-- Category filters of search and facets.
+-- The search and facet filters compared the text of items.category with the filter, so a search in a parent
+-- category missed the items of its subcategories, unlike the list endpoint. They now match by category id
+-- and include the subcategories. filter_category is the name of a category, and filter_category_id its id.
+
+-- item_in_category_filter tells if an item of the category is in the categories of the filters. A name
+-- matches every category with that name, ignoring case, as names are only unique among siblings.
+create or replace function item_in_category_filter(item_category_id bigint, filter_category text, filter_category_id bigint)
+returns boolean
+language sql
+stable
+as $$
+  select (
+    filter_category is null
+    or item_category_id in (
+      select category_descendant_ids(c.id)
+      from categories c
+      where lower(c.name) = lower(trim(filter_category))
+        and c.deleted_at is null
+    )
+  ) and (
+    filter_category_id is null
+    or item_category_id in (select category_descendant_ids(filter_category_id))
+  );
+$$;
+
+-- The functions gain a parameter, so the old versions are dropped to keep the calls unambiguous
+drop function if exists search_items(text, integer, integer, text, bigint, numeric, numeric, text, integer);
+drop function if exists search_item_facets(text, text, bigint, numeric, numeric, text, integer, numeric[]);
+
+create or replace function search_items(
+  search_query text,
+  result_limit integer,
+  result_offset integer,
+  filter_category text default null,
+  filter_category_id bigint default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5
+)
+returns table (item jsonb, rank real, highlights jsonb, total_count bigint)
+language sql
+stable
+as $$
+  with query as (
+    select websearch_to_tsquery('english', coalesce(search_query, '')) as tsq
+  ),
+  matches as (
+    select
+      i.*,
+      query.tsq as tsq,
+      (
+        ts_rank(i.search_vector, query.tsq) * 2
+        + word_similarity(coalesce(search_query, ''), i.name)
+        + word_similarity(coalesce(search_query, ''), coalesce(i.category, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.description, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.notes, '')) * 0.25
+      )::real as search_rank
+    from item_search_matches(search_query) i, query
+    where item_in_category_filter(i.category_id, filter_category, filter_category_id)
+      and (filter_supplier_id is null or i.supplier_id = filter_supplier_id)
+      and (filter_min_price is null or i.purchase_price >= filter_min_price)
+      and (filter_max_price is null or i.purchase_price < filter_max_price)
+      and (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status)
+  )
+  select
+    to_jsonb(m) - 'search_vector' - 'tsq' - 'search_rank' as item,
+    m.search_rank as rank,
+    jsonb_strip_nulls(jsonb_build_object(
+      'name', case when to_tsvector('english', coalesce(m.name, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.name), m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'category', case when to_tsvector('english', coalesce(m.category, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.category), m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'description', case when to_tsvector('english', coalesce(m.description, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.description), m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end,
+      'notes', case when to_tsvector('english', coalesce(m.notes, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.notes), m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end
+    )) as highlights,
+    count(*) over () as total_count
+  from matches m
+  order by m.search_rank desc, m.name asc, m.id asc
+  limit result_limit
+  offset result_offset;
+$$;
+
+create or replace function search_item_facets(
+  search_query text,
+  filter_category text default null,
+  filter_category_id bigint default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5,
+  price_band_bounds numeric[] default '{10,50,100,500}'
+)
+returns jsonb
+language sql
+stable
+as $$
+  with matches as (
+    select
+      i.*,
+      item_stock_status(i.quantity, low_stock_threshold) as stock_status,
+      item_in_category_filter(i.category_id, filter_category, filter_category_id) as category_match,
+      (filter_supplier_id is null or i.supplier_id = filter_supplier_id) as supplier_match,
+      ((filter_min_price is null or i.purchase_price >= filter_min_price)
+        and (filter_max_price is null or i.purchase_price < filter_max_price)) as price_match,
+      (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status) as stock_match
+    from item_search_matches(search_query) i
+  ),
+  bands as (
+    select
+      band.index,
+      case when band.index = 1 then 0 else price_band_bounds[band.index - 1] end as min_price,
+      case when band.index <= cardinality(price_band_bounds) then price_band_bounds[band.index] end as max_price
+    from generate_series(1, cardinality(price_band_bounds) + 1) as band(index)
+  )
+  select jsonb_build_object(
+    'categories', coalesce((
+      select jsonb_agg(jsonb_build_object('value', c.category, 'count', c.count) order by c.count desc, c.category)
+      from (
+        select category, count(*) as count
+        from matches
+        where supplier_match and price_match and stock_match
+        group by category
+      ) c
+    ), '[]'::jsonb),
+    'suppliers', coalesce((
+      select jsonb_agg(jsonb_build_object('id', s.supplier_id, 'name', s.name, 'count', s.count) order by s.count desc, s.name)
+      from (
+        select m.supplier_id, sup.name, count(*) as count
+        from matches m
+        left join suppliers sup on sup.id = m.supplier_id
+        where m.category_match and m.price_match and m.stock_match
+        group by m.supplier_id, sup.name
+      ) s
+    ), '[]'::jsonb),
+    'price_bands', coalesce((
+      select jsonb_agg(jsonb_build_object('min', b.min_price, 'max', b.max_price, 'count', b.count) order by b.index)
+      from (
+        select bands.index, bands.min_price, bands.max_price, count(m.id) as count
+        from bands
+        left join matches m
+          on m.category_match and m.supplier_match and m.stock_match
+          and m.purchase_price >= bands.min_price
+          and (bands.max_price is null or m.purchase_price < bands.max_price)
+        group by bands.index, bands.min_price, bands.max_price
+      ) b
+    ), '[]'::jsonb),
+    'stock_status', coalesce((
+      select jsonb_agg(jsonb_build_object('value', st.status, 'count', st.count) order by st.sort_order)
+      from (
+        select statuses.status, statuses.sort_order, count(m.id) as count
+        from (values ('in_stock', 1), ('low_stock', 2), ('out_of_stock', 3)) as statuses(status, sort_order)
+        left join matches m
+          on m.stock_status = statuses.status
+          and m.category_match and m.supplier_match and m.price_match
+        group by statuses.status, statuses.sort_order
+      ) st
+    ), '[]'::jsonb)
+  );
+$$;
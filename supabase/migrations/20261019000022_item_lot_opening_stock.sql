@@ -0,0 +1,54 @@
+-- Opening lots and lot quantities.
+-- The quantity of an item with lots is the sum of its lots, so the stock an item had before its first lot was
+-- lost when that lot was created. The stock is now moved into an OPENING lot first, and the quantity of an item
+-- with lots can only be changed through its lots.
+
+-- create_opening_lot moves the stock of an item without lots into an OPENING lot before its first lot is created.
+-- The quantity of the item is set to 0 first, so the insert of the opening lot does not create another one,
+-- and the quantity is synced back from the lots afterwards.
+create or replace function create_opening_lot()
+returns trigger
+language plpgsql
+as $$
+declare
+  opening_quantity integer;
+begin
+  select quantity into opening_quantity from items where id = new.item_id for update;
+
+  if opening_quantity > 0 and not exists (select 1 from item_lots where item_id = new.item_id) then
+    update items set quantity = 0, updated_at = now() where id = new.item_id;
+
+    insert into item_lots (item_id, lot_number, quantity)
+    values (new.item_id, 'OPENING', opening_quantity);
+  end if;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists item_lots_create_opening_lot on item_lots;
+create trigger item_lots_create_opening_lot
+  before insert on item_lots
+  for each row execute function create_opening_lot();
+
+-- The quantity of an item with lots can only be set to the sum of its lots, as sync_item_quantity_from_lots does
+create or replace function prevent_item_quantity_change_with_lots()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.quantity is distinct from old.quantity
+    and exists (select 1 from item_lots where item_id = new.id)
+    and new.quantity <> (select coalesce(sum(quantity), 0) from item_lots where item_id = new.id)
+  then
+    raise exception 'The stock of item % is changed through its lots', new.name;
+  end if;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists items_prevent_quantity_change_with_lots on items;
+create trigger items_prevent_quantity_change_with_lots
+  before update of quantity on items
+  for each row execute function prevent_item_quantity_change_with_lots();
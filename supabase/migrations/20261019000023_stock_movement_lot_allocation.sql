@@ -0,0 +1,157 @@
+-- Stock movements of items with lots.
+-- A movement without a lot changed items.quantity directly, which sync_item_quantity_from_lots overwrote with the
+-- sum of the lots at the next lot change. Movements that remove stock without a lot are now allocated over the
+-- lots first expired first out, skipping expired lots, and recorded per lot. Adding stock needs a lot.
+
+create or replace function apply_stock_movements(movements jsonb)
+returns jsonb
+language plpgsql
+as $$
+declare
+  movement jsonb;
+  movement_unit text;
+  movement_unit_quantity numeric;
+  movement_factor numeric;
+  movement_quantity integer;
+  target items%rowtype;
+  target_lot item_lots%rowtype;
+  available integer;
+  remaining integer;
+  allocated integer;
+  allocations jsonb;
+  allocation jsonb;
+  recorded stock_movements%rowtype;
+  result jsonb := '[]'::jsonb;
+begin
+  if jsonb_typeof(movements) is distinct from 'array' or jsonb_array_length(movements) = 0 then
+    raise exception 'At least one stock movement is required';
+  end if;
+
+  -- Lock the items in id order, so concurrent operations on the same items can not deadlock
+  perform 1 from items
+  where id in (select (value->>'item_id')::bigint from jsonb_array_elements(movements))
+  order by id
+  for update;
+
+  for movement in select value from jsonb_array_elements(movements) loop
+    if coalesce(trim(movement->>'type'), '') = '' then
+      raise exception 'Stock movement type is required';
+    end if;
+
+    select * into target from items
+    where id = (movement->>'item_id')::bigint and deleted_at is null;
+    if not found then
+      raise exception 'Item % not found', movement->>'item_id' using errcode = 'P0002';
+    end if;
+
+    if target.serialized then
+      raise exception 'The stock of serialised item % is changed through its serials', target.name;
+    end if;
+
+    movement_unit := coalesce(nullif(movement->>'unit', ''), target.base_unit);
+    movement_unit_quantity := coalesce((movement->>'unit_quantity')::numeric, (movement->>'quantity')::numeric);
+    movement_factor := item_unit_factor(target.id, movement_unit);
+
+    if movement_unit_quantity is null or movement_unit_quantity = 0 then
+      raise exception 'Stock movement quantity must be a non-zero number';
+    end if;
+
+    if movement_unit_quantity * movement_factor <> trunc(movement_unit_quantity * movement_factor) then
+      raise exception '% % of item % is not a whole number of %',
+        movement_unit_quantity, movement_unit, target.name, target.base_unit;
+    end if;
+    movement_quantity := (movement_unit_quantity * movement_factor)::integer;
+
+    -- The lots the movement is recorded for, with the quantity per lot
+    allocations := '[]'::jsonb;
+
+    if movement->>'lot_id' is not null then
+      select * into target_lot from item_lots
+      where id = (movement->>'lot_id')::bigint and item_id = target.id
+      for update;
+      if not found then
+        raise exception 'Lot % not found for item %', movement->>'lot_id', target.name using errcode = 'P0002';
+      end if;
+
+      if target_lot.quantity + movement_quantity < 0 then
+        raise exception 'Insufficient stock in lot % of item %: % on hand, % requested',
+          target_lot.lot_number, target.name, target_lot.quantity, -movement_quantity;
+      end if;
+
+      allocations := jsonb_build_array(jsonb_build_object('lot_id', target_lot.id, 'quantity', movement_quantity));
+    elsif exists (select 1 from item_lots where item_id = target.id) then
+      if movement_quantity > 0 then
+        raise exception 'Stock of item % is added to a lot, the lot is required', target.name;
+      end if;
+
+      select coalesce(sum(quantity), 0) into available from item_lots
+      where item_id = target.id and (expiry_date is null or expiry_date >= current_date);
+
+      if available < -movement_quantity then
+        raise exception 'Insufficient unexpired stock of item %: % on hand, % requested. Expired stock is removed by lot',
+          target.name, available, -movement_quantity;
+      end if;
+
+      remaining := -movement_quantity;
+      for target_lot in
+        select * from item_lots
+        where item_id = target.id and quantity > 0 and (expiry_date is null or expiry_date >= current_date)
+        order by expiry_date asc nulls last, received_at asc, id asc
+        for update
+      loop
+        exit when remaining = 0;
+
+        allocated := least(target_lot.quantity, remaining);
+        allocations := allocations || jsonb_build_array(jsonb_build_object('lot_id', target_lot.id, 'quantity', -allocated));
+        remaining := remaining - allocated;
+      end loop;
+    else
+      if target.quantity + movement_quantity < 0 then
+        raise exception 'Insufficient stock of item %: % on hand, % requested',
+          target.name, target.quantity, -movement_quantity;
+      end if;
+
+      update items
+      set quantity = quantity + movement_quantity, updated_at = now()
+      where id = target.id;
+
+      allocations := jsonb_build_array(jsonb_build_object('lot_id', null, 'quantity', movement_quantity));
+    end if;
+
+    for allocation in select value from jsonb_array_elements(allocations) loop
+      -- The item quantity follows from the lot quantities
+      if allocation->>'lot_id' is not null then
+        update item_lots
+        set quantity = quantity + (allocation->>'quantity')::integer, updated_at = now()
+        where id = (allocation->>'lot_id')::bigint;
+      end if;
+
+      insert into stock_movements (
+        item_id, lot_id, quantity, unit, unit_quantity, type, unit_cost, reference_type, reference_id, note
+      )
+      values (
+        target.id,
+        (allocation->>'lot_id')::bigint,
+        (allocation->>'quantity')::integer,
+        movement_unit,
+        -- A movement split over lots keeps the unit, with the part of the quantity of each lot
+        case when (allocation->>'quantity')::integer = movement_quantity
+          then movement_unit_quantity
+          else round((allocation->>'quantity')::numeric / movement_factor, 4)
+        end,
+        movement->>'type',
+        -- The unit cost is stored per base unit
+        round((movement->>'unit_cost')::numeric / movement_factor, 4),
+        movement->>'reference_type',
+        (movement->>'reference_id')::bigint,
+        movement->>'note'
+      )
+      returning * into recorded;
+
+      result := result || jsonb_build_array(to_jsonb(recorded));
+    end loop;
+  end loop;
+
+  return result;
+end;
+$$;
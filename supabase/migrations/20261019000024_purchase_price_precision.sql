@@ -0,0 +1,96 @@
+-- Precision of purchase prices.
+-- supplier_items.price_minor was backfilled with two decimals for every currency, so prices in currencies such as
+-- JPY were 100 times too high. The rows that still have that value are converted with the minor unit of their
+-- currency. The price of a preferred supplier per base unit was also rounded to whole minor units on the item,
+-- so a box of 100 at 3.33 became 0.03 per piece. items.purchase_unit_cost now keeps the exact cost per base unit.
+
+alter table items add column if not exists purchase_unit_cost numeric(18, 6);
+
+-- purchase_unit_cost follows the purchase price, unless it is written together with it.
+-- A decimal purchase price keeps its own precision in the unit cost, before it is rounded to minor units.
+create or replace function sync_item_purchase_price()
+returns trigger
+language plpgsql
+as $$
+declare
+  exponent smallint;
+  written_price numeric;
+begin
+  select minor_unit into exponent from currencies where code = new.purchase_currency;
+  if exponent is null then
+    raise exception 'Unknown currency %', new.purchase_currency using errcode = 'P0001';
+  end if;
+
+  -- The minor units are leading, unless only the decimal price was written
+  if tg_op = 'INSERT' then
+    if new.purchase_price_minor is null then
+      written_price := coalesce(new.purchase_price, 0);
+      new.purchase_price_minor := round(written_price * 10 ^ exponent);
+    end if;
+  elsif new.purchase_price_minor is not distinct from old.purchase_price_minor
+    and new.purchase_price is distinct from old.purchase_price then
+    written_price := new.purchase_price;
+    new.purchase_price_minor := round(new.purchase_price * 10 ^ exponent);
+  end if;
+
+  new.purchase_price := new.purchase_price_minor / 10 ^ exponent;
+
+  if tg_op = 'INSERT' then
+    new.purchase_unit_cost := coalesce(new.purchase_unit_cost, written_price, new.purchase_price);
+  elsif new.purchase_unit_cost is not distinct from old.purchase_unit_cost
+    and (written_price is not null
+      or new.purchase_price_minor is distinct from old.purchase_price_minor
+      or new.purchase_currency is distinct from old.purchase_currency) then
+    new.purchase_unit_cost := coalesce(written_price, new.purchase_price);
+  end if;
+
+  return new;
+end;
+$$;
+
+-- The preferred supplier price is mirrored to the item in minor units and currency, with the exact cost per base unit
+create or replace function sync_item_preferred_supplier()
+returns trigger
+language plpgsql
+as $$
+declare
+  exponent smallint;
+  unit_cost numeric;
+begin
+  if new.is_preferred then
+    select minor_unit into exponent from currencies where code = new.currency;
+    unit_cost := new.price_minor / 10 ^ exponent / item_unit_factor(new.item_id, new.unit);
+
+    update items
+    set supplier_id = new.supplier_id,
+        purchase_currency = new.currency,
+        purchase_price_minor = round(unit_cost * 10 ^ exponent),
+        purchase_unit_cost = unit_cost,
+        updated_at = now()
+    where id = new.item_id;
+  end if;
+  return null;
+end;
+$$;
+
+-- Supplier prices that still have the two decimal backfill. Rows written since then have price_minor in the
+-- minor unit of their currency, so they do not match.
+update supplier_items s
+set price_minor = round(s.price * 10 ^ c.minor_unit)
+from currencies c
+where c.code = s.currency
+  and c.minor_unit <> 2
+  and s.price_minor <> 0
+  and s.price_minor = round(s.price * 100);
+
+update items set purchase_unit_cost = purchase_price where purchase_unit_cost is null;
+
+update items i
+set purchase_unit_cost = s.price_minor / 10 ^ c.minor_unit / item_unit_factor(s.item_id, s.unit)
+from supplier_items s
+join currencies c on c.code = s.currency
+where s.item_id = i.id
+  and s.is_preferred
+  and s.currency = i.purchase_currency;
+
+alter table items alter column purchase_unit_cost set not null;
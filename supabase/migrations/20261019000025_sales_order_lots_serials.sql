@@ -0,0 +1,105 @@
+-- Lots and serials of sales order lines.
+-- ship_sales_order issued every line as a stock movement without a lot, which failed for serialised items.
+-- A line can now name the lot to ship from, otherwise the stock is taken from the lots first expired first out.
+-- Serialised items are shipped by issuing their serials: the serial numbers of the line, or the serials that
+-- have been in stock the longest, which are then recorded on the line.
+
+alter table sales_order_lines add column if not exists lot_id bigint references item_lots (id) on delete restrict;
+alter table sales_order_lines add column if not exists serial_numbers text[];
+
+-- ship_sales_order fulfils the reservations and issues the stock of every line
+create or replace function ship_sales_order(order_id bigint)
+returns jsonb
+language plpgsql
+as $$
+declare
+  target sales_orders%rowtype;
+  line record;
+  shipped_serials bigint[];
+  movements jsonb;
+begin
+  target := lock_sales_order(order_id, array['picked'], 'ship');
+
+  update stock_reservations r
+  set status = 'fulfilled', released_at = now(), updated_at = now()
+  from sales_order_lines l
+  where l.order_id = target.id
+    and r.reference_type = 'sales_order_line' and r.reference_id = l.id
+    and r.status = 'active';
+
+  for line in
+    select l.*, i.name as item_name
+    from sales_order_lines l
+    join items i on i.id = l.item_id
+    where l.order_id = target.id and i.serialized
+    order by l.item_id
+  loop
+    if line.serial_numbers is not null and cardinality(line.serial_numbers) > 0 then
+      if cardinality(line.serial_numbers) <> line.quantity then
+        raise exception 'Line of item % has % serial numbers for a quantity of %',
+          line.item_name, cardinality(line.serial_numbers), line.quantity;
+      end if;
+
+      select array_agg(picked.id) into shipped_serials
+      from (
+        select s.id
+        from item_serials s
+        where s.item_id = line.item_id
+          and s.serial_number = any (line.serial_numbers)
+          and s.status = 'in_stock'
+        for update
+      ) picked;
+    else
+      select array_agg(picked.id) into shipped_serials
+      from (
+        select s.id
+        from item_serials s
+        where s.item_id = line.item_id and s.status = 'in_stock'
+        order by s.created_at, s.id
+        limit line.quantity
+        for update
+      ) picked;
+    end if;
+
+    if coalesce(cardinality(shipped_serials), 0) <> line.quantity then
+      raise exception 'Insufficient serials in stock of item %: % available, % requested',
+        line.item_name, coalesce(cardinality(shipped_serials), 0), line.quantity;
+    end if;
+
+    -- The item quantity follows from the serials in stock
+    update item_serials
+    set status = 'issued', note = 'Sales order ' || target.id, updated_at = now()
+    where id = any (shipped_serials);
+
+    update sales_order_lines
+    set serial_numbers = (select array_agg(serial_number order by serial_number) from item_serials where id = any (shipped_serials)),
+        updated_at = now()
+    where id = line.id;
+  end loop;
+
+  select jsonb_agg(jsonb_build_object(
+    'item_id', l.item_id,
+    'lot_id', l.lot_id,
+    'quantity', -l.quantity,
+    'type', 'issue',
+    'reference_type', 'sales_order',
+    'reference_id', target.id,
+    'note', 'Sales order ' || target.id
+  ) order by l.item_id)
+  into movements
+  from sales_order_lines l
+  join items i on i.id = l.item_id
+  where l.order_id = target.id and not i.serialized;
+
+  if movements is not null then
+    perform apply_stock_movements(movements);
+  end if;
+
+  update sales_orders
+  set status = 'shipped', shipped_at = now(), updated_at = now()
+  where id = target.id
+  returning * into target;
+
+  return to_jsonb(target);
+end;
+$$;
@@ -0,0 +1,60 @@
+-- Soft deletion of items and suppliers.
+-- purge_records removes the items and suppliers that were deleted before the retention period, but the API deleted
+-- items from the table and had no way to delete suppliers, so deleted_at was never set. Both are now deleted by
+-- setting deleted_at, which also records the deleted events of the outbox.
+-- A deleted item releases its SKU and barcodes, so they can be given to a new item before the purge.
+-- Items with active reservations and suppliers that items still have are not deleted.
+
+drop index if exists items_sku_key;
+create unique index if not exists items_sku_key
+  on items (sku)
+  where sku is not null and deleted_at is null;
+
+create or replace function prepare_item_soft_delete()
+returns trigger
+language plpgsql
+as $$
+begin
+  if old.deleted_at is not null or new.deleted_at is null then
+    return new;
+  end if;
+
+  if exists (select 1 from stock_reservations where item_id = new.id and status = 'active') then
+    raise exception 'Item % still has active reservations', new.name using errcode = 'P0001';
+  end if;
+
+  delete from item_barcodes where item_id = new.id;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists items_prepare_soft_delete on items;
+create trigger items_prepare_soft_delete
+  before update of deleted_at on items
+  for each row execute function prepare_item_soft_delete();
+
+-- A supplier is only deleted when no item has it as supplier anymore. Its prices for items are removed.
+create or replace function prepare_supplier_soft_delete()
+returns trigger
+language plpgsql
+as $$
+begin
+  if old.deleted_at is not null or new.deleted_at is null then
+    return new;
+  end if;
+
+  if exists (select 1 from items where supplier_id = new.id and deleted_at is null) then
+    raise exception 'Supplier % is still the supplier of items', new.name using errcode = 'P0001';
+  end if;
+
+  delete from supplier_items where supplier_id = new.id;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists suppliers_prepare_soft_delete on suppliers;
+create trigger suppliers_prepare_soft_delete
+  before update of deleted_at on suppliers
+  for each row execute function prepare_supplier_soft_delete();
@@ -0,0 +1,19 @@
+-- items.category is the name of items.category_id, so it is cleared with the category.
+-- sync_item_category_name only set the name when there was a category, so an item whose category was removed
+-- kept the old name, and search and facets still found it in that category.
+
+create or replace function sync_item_category_name()
+returns trigger
+language plpgsql
+as $$
+begin
+  if new.category_id is not null then
+    new.category := (select name from categories where id = new.category_id);
+  else
+    new.category := null;
+  end if;
+  return new;
+end;
+$$;
+
+update items set category = null where category_id is null and category is not null;
@@ -0,0 +1,24 @@
+-- Deletion of categories.
+-- DeleteCategory deleted the row from the API, so an unknown ID was reported as deleted, and items that were
+-- soft deleted kept their category, so a category whose items were all deleted could never be removed.
+-- delete_category drops the category of its deleted items first. Items that are not deleted, subcategories and
+-- stocktakes still keep a category from being deleted, through their foreign keys.
+
+create or replace function delete_category(target_id bigint)
+returns bigint
+language plpgsql
+as $$
+begin
+  perform 1 from categories where id = target_id and deleted_at is null for update;
+  if not found then
+    raise exception 'Category % not found', target_id using errcode = 'P0002';
+  end if;
+
+  update items set category_id = null, updated_at = now()
+  where category_id = target_id and deleted_at is not null;
+
+  delete from categories where id = target_id;
+
+  return target_id;
+end;
+$$;
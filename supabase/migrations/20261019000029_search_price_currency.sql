@@ -0,0 +1,206 @@
+-- Currency of search prices.
+-- The price filters and price bands of search compared purchase_price across items in different currencies,
+-- so 100 JPY and 100 EUR fell in the same band. Prices are now converted to price_currency, the base currency
+-- of the API, with the latest exchange rate before they are filtered and bucketed. Items whose price cannot be
+-- converted for lack of a rate are left out of the price filters and price bands.
+
+-- exchange_rate returns the latest rate from one currency to another, directly or by the inverse rate
+create or replace function exchange_rate(from_currency text, to_currency text)
+returns numeric
+language sql
+stable
+as $$
+  select r.rate
+  from (
+    select rate, effective_date, 1 as preference
+    from exchange_rates
+    where base_currency = from_currency and quote_currency = to_currency and effective_date <= current_date
+    union all
+    select 1 / rate, effective_date, 2 as preference
+    from exchange_rates
+    where base_currency = to_currency and quote_currency = from_currency and effective_date <= current_date
+  ) r
+  order by r.effective_date desc, r.preference
+  limit 1;
+$$;
+
+-- convert_amount converts an amount between currencies, through a third currency when there is no rate
+-- between them, like the rate table of the API. It returns null when no rate is known.
+create or replace function convert_amount(amount numeric, from_currency text, to_currency text)
+returns numeric
+language sql
+stable
+as $$
+  select case
+    when amount is null then null
+    when from_currency = to_currency then amount
+    else amount * coalesce(
+      exchange_rate(from_currency, to_currency),
+      (
+        select exchange_rate(from_currency, c.code) * exchange_rate(c.code, to_currency)
+        from currencies c
+        where c.code <> from_currency
+          and c.code <> to_currency
+          and exchange_rate(from_currency, c.code) is not null
+          and exchange_rate(c.code, to_currency) is not null
+        order by c.code
+        limit 1
+      )
+    )
+  end;
+$$;
+
+-- item_search_price is the purchase price of an item in price_currency, or in its own currency without one
+create or replace function item_search_price(purchase_price numeric, purchase_currency text, price_currency text)
+returns numeric
+language sql
+stable
+as $$
+  select convert_amount(purchase_price, purchase_currency, coalesce(price_currency, purchase_currency));
+$$;
+
+-- The functions gain a parameter, so the old versions are dropped to keep the calls unambiguous
+drop function if exists search_items(text, integer, integer, text, bigint, bigint, numeric, numeric, text, integer);
+drop function if exists search_item_facets(text, text, bigint, bigint, numeric, numeric, text, integer, numeric[]);
+
+create or replace function search_items(
+  search_query text,
+  result_limit integer,
+  result_offset integer,
+  filter_category text default null,
+  filter_category_id bigint default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5,
+  price_currency text default null
+)
+returns table (item jsonb, rank real, highlights jsonb, total_count bigint)
+language sql
+stable
+as $$
+  with query as (
+    select websearch_to_tsquery('english', coalesce(search_query, '')) as tsq
+  ),
+  matches as (
+    select
+      i.*,
+      query.tsq as tsq,
+      (
+        ts_rank(i.search_vector, query.tsq) * 2
+        + word_similarity(coalesce(search_query, ''), i.name)
+        + word_similarity(coalesce(search_query, ''), coalesce(i.category, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.description, '')) * 0.5
+        + word_similarity(coalesce(search_query, ''), coalesce(i.notes, '')) * 0.25
+      )::real as search_rank
+    from item_search_matches(search_query) i, query
+    where item_in_category_filter(i.category_id, filter_category, filter_category_id)
+      and (filter_supplier_id is null or i.supplier_id = filter_supplier_id)
+      and (filter_min_price is null or item_search_price(i.purchase_price, i.purchase_currency, price_currency) >= filter_min_price)
+      and (filter_max_price is null or item_search_price(i.purchase_price, i.purchase_currency, price_currency) < filter_max_price)
+      and (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status)
+  )
+  select
+    to_jsonb(m) - 'search_vector' - 'tsq' - 'search_rank' as item,
+    m.search_rank as rank,
+    jsonb_strip_nulls(jsonb_build_object(
+      'name', case when to_tsvector('english', coalesce(m.name, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.name), m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'category', case when to_tsvector('english', coalesce(m.category, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.category), m.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') end,
+      'description', case when to_tsvector('english', coalesce(m.description, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.description), m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end,
+      'notes', case when to_tsvector('english', coalesce(m.notes, '')) @@ m.tsq
+        then ts_headline('english', html_escape(m.notes), m.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') end
+    )) as highlights,
+    count(*) over () as total_count
+  from matches m
+  order by m.search_rank desc, m.name asc, m.id asc
+  limit result_limit
+  offset result_offset;
+$$;
+
+create or replace function search_item_facets(
+  search_query text,
+  filter_category text default null,
+  filter_category_id bigint default null,
+  filter_supplier_id bigint default null,
+  filter_min_price numeric default null,
+  filter_max_price numeric default null,
+  filter_stock_status text default null,
+  low_stock_threshold integer default 5,
+  price_band_bounds numeric[] default '{10,50,100,500}',
+  price_currency text default null
+)
+returns jsonb
+language sql
+stable
+as $$
+  with priced as (
+    select i.*, item_search_price(i.purchase_price, i.purchase_currency, price_currency) as search_price
+    from item_search_matches(search_query) i
+  ),
+  matches as (
+    select
+      i.*,
+      item_stock_status(i.quantity, low_stock_threshold) as stock_status,
+      item_in_category_filter(i.category_id, filter_category, filter_category_id) as category_match,
+      (filter_supplier_id is null or i.supplier_id = filter_supplier_id) as supplier_match,
+      ((filter_min_price is null or i.search_price >= filter_min_price)
+        and (filter_max_price is null or i.search_price < filter_max_price)) as price_match,
+      (filter_stock_status is null or item_stock_status(i.quantity, low_stock_threshold) = filter_stock_status) as stock_match
+    from priced i
+  ),
+  bands as (
+    select
+      band.index,
+      case when band.index = 1 then 0 else price_band_bounds[band.index - 1] end as min_price,
+      case when band.index <= cardinality(price_band_bounds) then price_band_bounds[band.index] end as max_price
+    from generate_series(1, cardinality(price_band_bounds) + 1) as band(index)
+  )
+  select jsonb_build_object(
+    'categories', coalesce((
+      select jsonb_agg(jsonb_build_object('value', c.category, 'count', c.count) order by c.count desc, c.category)
+      from (
+        select category, count(*) as count
+        from matches
+        where supplier_match and price_match and stock_match
+        group by category
+      ) c
+    ), '[]'::jsonb),
+    'suppliers', coalesce((
+      select jsonb_agg(jsonb_build_object('id', s.supplier_id, 'name', s.name, 'count', s.count) order by s.count desc, s.name)
+      from (
+        select m.supplier_id, sup.name, count(*) as count
+        from matches m
+        left join suppliers sup on sup.id = m.supplier_id
+        where m.category_match and m.price_match and m.stock_match
+        group by m.supplier_id, sup.name
+      ) s
+    ), '[]'::jsonb),
+    'price_bands', coalesce((
+      select jsonb_agg(jsonb_build_object('min', b.min_price, 'max', b.max_price, 'currency', price_currency, 'count', b.count) order by b.index)
+      from (
+        select bands.index, bands.min_price, bands.max_price, count(m.id) as count
+        from bands
+        left join matches m
+          on m.category_match and m.supplier_match and m.stock_match
+          and m.search_price >= bands.min_price
+          and (bands.max_price is null or m.search_price < bands.max_price)
+        group by bands.index, bands.min_price, bands.max_price
+      ) b
+    ), '[]'::jsonb),
+    'stock_status', coalesce((
+      select jsonb_agg(jsonb_build_object('value', st.status, 'count', st.count) order by st.sort_order)
+      from (
+        select statuses.status, statuses.sort_order, count(m.id) as count
+        from (values ('in_stock', 1), ('low_stock', 2), ('out_of_stock', 3)) as statuses(status, sort_order)
+        left join matches m
+          on m.stock_status = statuses.status
+          and m.category_match and m.supplier_match and m.price_match
+        group by statuses.status, statuses.sort_order
+      ) st
+    ), '[]'::jsonb)
+  );
+$$;
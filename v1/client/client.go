@@ -0,0 +1,255 @@
+// Package client is a typed Go client for the v1 API.
+// It decodes the ApiResponse envelope, returns failed requests as *schemas.CustomError and retries
+// requests that failed temporarily.
+//
+//	api := client.New("http://localhost:8080/v1")
+//	item, err := api.Items.Get(ctx, 1)
+//	if client.ErrorCode(err) == http.StatusNotFound {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries after the first attempt of a request
+	DefaultMaxRetries = 3
+	// DefaultRetryBase is the delay before the first retry, which doubles with every retry
+	DefaultRetryBase = 250 * time.Millisecond
+	// DefaultTimeout is the timeout of the default HTTP client
+	DefaultTimeout = 30 * time.Second
+	// maxRetryDelay caps the exponential backoff and the Retry-After of the API
+	maxRetryDelay = 30 * time.Second
+)
+
+// Client sends requests to the v1 API. It is safe for concurrent use.
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+	maxRetries int
+	retryBase  time.Duration
+
+	Items       *ItemsService
+	Suppliers   *SuppliersService
+	Contacts    *ContactsService
+	Maintenance *MaintenanceService
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client that sends the requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithRetries sets the number of retries and the delay before the first retry. Zero retries disables retrying.
+func WithRetries(maxRetries int, base time.Duration) Option {
+	return func(client *Client) {
+		client.maxRetries = maxRetries
+		client.retryBase = base
+	}
+}
+
+// New returns a client of the API at baseUrl, which includes the version, e.g. http://localhost:8080/v1
+func New(baseUrl string, options ...Option) *Client {
+	client := &Client{
+		baseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		maxRetries: DefaultMaxRetries,
+		retryBase:  DefaultRetryBase,
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	client.Items = &ItemsService{client: client}
+	client.Suppliers = &SuppliersService{client: client}
+	client.Contacts = &ContactsService{client: client}
+	client.Maintenance = &MaintenanceService{client: client}
+
+	return client
+}
+
+// envelope is the ApiResponse with the data left undecoded
+type envelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// do sends the request and decodes the data of the response into out, unless out is nil.
+// It retries transport errors and temporary failures with exponential backoff. POST and PATCH are not
+// idempotent, so they are only retried when the API did not handle them: 429, 503 and failed connections.
+func (client *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode the body of %s %s: %w", method, path, err)
+		}
+	}
+
+	requestUrl := client.baseUrl + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := client.send(ctx, method, requestUrl, payload)
+
+		retryAfter := time.Duration(0)
+		retry := false
+		if err != nil {
+			retry = ctx.Err() == nil && isRetryableError(method, err)
+		} else {
+			retry = isRetryable(method, response.StatusCode)
+			retryAfter = parseRetryAfter(response.Header.Get("Retry-After"))
+		}
+
+		if !retry || attempt >= client.maxRetries {
+			if err != nil {
+				return fmt.Errorf("%s %s failed: %w", method, path, err)
+			}
+			return decodeResponse(response, method, path, out)
+		}
+
+		if response != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		delay := max(client.retryDelay(attempt), retryAfter)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %s failed: %w", method, path, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method string, requestUrl string, payload []byte) (*http.Response, error) {
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestUrl, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	return client.httpClient.Do(request)
+}
+
+// retryDelay returns the delay before the given retry: base, 2*base, 4*base and so on, with up to 50% jitter
+func (client *Client) retryDelay(attempt int) time.Duration {
+	delay := client.retryBase
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryDelay)
+
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isIdempotent reports whether sending the request again has the same effect as sending it once
+func isIdempotent(method string) bool {
+	return method != http.MethodPost && method != http.MethodPatch
+}
+
+func isRetryable(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// isRetryableError reports whether a request that failed with a transport error can be sent again.
+// A POST or PATCH can have reached the API before the connection broke, so it is only sent again
+// when the connection failed.
+func isRetryableError(method string, err error) bool {
+	if isIdempotent(method) {
+		return true
+	}
+
+	var opError *net.OpError
+	return errors.As(err, &opError) && opError.Op == "dial"
+}
+
+// parseRetryAfter returns the delay of a Retry-After header in seconds or as a date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = time.Until(date)
+	}
+
+	return min(max(delay, 0), maxRetryDelay)
+}
+
+// decodeResponse decodes the data of a successful response into out, and returns the error of a failed one
+func decodeResponse(response *http.Response, method string, path string, out interface{}) error {
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response of %s %s: %w", method, path, err)
+	}
+
+	var result envelope
+	decodeErr := json.Unmarshal(body, &result)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 || (decodeErr == nil && !result.Success) {
+		return newAPIError(response.StatusCode, method, path, result.Message, body, decodeErr)
+	}
+
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode the response of %s %s: %w", method, path, decodeErr)
+	}
+
+	if out == nil || len(result.Data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("failed to decode the data of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
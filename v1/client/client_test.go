@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// newBrokenServer returns a server that drops the connection of every request after reading it,
+// and counts the requests
+func newBrokenServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		connection, _, err := writer.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack the connection: %v", err)
+			return
+		}
+		connection.Close()
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestTransportErrorRetries(t *testing.T) {
+	server, requests := newBrokenServer(t)
+	api := New(server.URL, WithRetries(2, time.Millisecond))
+
+	// The API may have created the item before the connection broke, so it is not created again
+	if _, err := api.Items.Create(context.Background(), schemas.Item{Name: "Bolt"}); err == nil {
+		t.Fatal("Create over a broken connection returned no error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("Create was sent %d times, want 1", got)
+	}
+
+	requests.Store(0)
+	if _, err := api.Items.Get(context.Background(), 1); err == nil {
+		t.Fatal("Get over a broken connection returned no error")
+	}
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("Get was sent %d times, want 3", got)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	dialError := &net.OpError{Op: "dial", Net: "tcp", Err: &net.AddrError{Err: "connection refused"}}
+	readError := &net.OpError{Op: "read", Net: "tcp", Err: &net.AddrError{Err: "connection reset by peer"}}
+
+	tests := []struct {
+		method string
+		err    error
+		want   bool
+	}{
+		{method: http.MethodGet, err: readError, want: true},
+		{method: http.MethodPut, err: readError, want: true},
+		{method: http.MethodDelete, err: readError, want: true},
+		{method: http.MethodPost, err: readError, want: false},
+		{method: http.MethodPatch, err: readError, want: false},
+		// The request never reached the API
+		{method: http.MethodPost, err: dialError, want: true},
+		{method: http.MethodPatch, err: dialError, want: true},
+	}
+
+	for _, test := range tests {
+		if got := isRetryableError(test.method, test.err); got != test.want {
+			t.Errorf("isRetryableError(%s, %v) = %v, want %v", test.method, test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// maxDetailsLength is the number of characters of an unexpected response body that is kept in the error
+const maxDetailsLength = 500
+
+// newAPIError returns the failed response as a CustomError, with the status code as Code and the message
+// of the API as Message, as it was returned by the service
+func newAPIError(statusCode int, method string, path string, message string, body []byte, decodeErr error) error {
+	details := fmt.Sprintf("%s %s answered %d", method, path, statusCode)
+
+	if decodeErr != nil {
+		// The response is not an ApiResponse, e.g. from a proxy
+		if len(body) > maxDetailsLength {
+			body = body[:maxDetailsLength]
+		}
+		details = fmt.Sprintf("%s: %s", details, body)
+	}
+
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+
+	return &schemas.CustomError{
+		Code:    statusCode,
+		Message: message,
+		Details: details,
+	}
+}
+
+// ErrorCode returns the status code of an error returned by the API, and 0 for other errors such as
+// transport errors
+func ErrorCode(err error) int {
+	var customErr *schemas.CustomError
+	if errors.As(err, &customErr) {
+		return customErr.Code
+	}
+	return 0
+}
+
+// IsNotFound reports whether the API answered that the resource does not exist
+func IsNotFound(err error) bool {
+	return ErrorCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether the API rejected the request because it conflicts with existing data,
+// e.g. a duplicate SKU
+func IsConflict(err error) bool {
+	return ErrorCode(err) == http.StatusConflict
+}
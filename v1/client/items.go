@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// ItemsService sends the requests of /items
+type ItemsService struct {
+	client *Client
+}
+
+// ItemListOptions are the query parameters of a cursor page of items.
+// The zero value lists the first 20 items by name.
+type ItemListOptions struct {
+	Cursor string
+	// Limit is between 1 and 100, 20 when it is not set
+	Limit     int
+	SortBy    string
+	SortDesc  bool
+	WithCount bool
+	Filters   schemas.ItemListFilters
+}
+
+// ItemPage is a cursor page of items
+type ItemPage struct {
+	schemas.CursorPage
+	Items []schemas.Item `json:"data"`
+}
+
+// ItemSearchOptions are the query parameters of a page of search results.
+// Page and PageSize are 1 and 20 when they are not set.
+type ItemSearchOptions struct {
+	Page       int
+	PageSize   int
+	WithFacets bool
+	Filters    schemas.ItemSearchFilters
+}
+
+// ItemSearchPage is a page of search results, with the facets when they are asked for
+type ItemSearchPage struct {
+	Count    int64                      `json:"count"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"pageSize"`
+	Results  []schemas.ItemSearchResult `json:"data"`
+	Facets   *schemas.ItemFacets        `json:"facets,omitempty"`
+}
+
+func (service *ItemsService) Get(ctx context.Context, id int8) (schemas.Item, error) {
+	var item schemas.Item
+	err := service.client.do(ctx, http.MethodGet, fmt.Sprintf("/items/%d", id), nil, nil, &item)
+	return item, err
+}
+
+func (service *ItemsService) GetByBarcode(ctx context.Context, code string) (schemas.Item, error) {
+	var item schemas.Item
+	err := service.client.do(ctx, http.MethodGet, "/items/by-barcode/"+url.PathEscape(code), nil, nil, &item)
+	return item, err
+}
+
+// List returns a cursor page of items. Pass the NextCursor of the page as Cursor to get the next one.
+func (service *ItemsService) List(ctx context.Context, options ItemListOptions) (ItemPage, error) {
+	query := url.Values{}
+	if options.Cursor != "" {
+		query.Set("cursor", options.Cursor)
+	}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.SortBy != "" {
+		query.Set("sort-by", options.SortBy)
+	}
+	if options.SortDesc {
+		query.Set("sort-order", "desc")
+	}
+	if options.WithCount {
+		query.Set("include-count", "true")
+	}
+	if options.Filters.CategoryId != nil {
+		query.Set("category-id", strconv.Itoa(int(*options.Filters.CategoryId)))
+	}
+	for _, tag := range options.Filters.Tags {
+		query.Add("tag", tag)
+	}
+	for key, value := range options.Filters.Attributes {
+		query.Set("attr."+key, value)
+	}
+
+	var page ItemPage
+	err := service.client.do(ctx, http.MethodGet, "/items", query, nil, &page)
+	return page, err
+}
+
+// All iterates over every item from the cursor of the options, fetching the pages as they are needed.
+// The iteration stops after the first error.
+//
+//	for item, err := range api.Items.All(ctx, client.ItemListOptions{Limit: 100}) {
+func (service *ItemsService) All(ctx context.Context, options ItemListOptions) iter.Seq2[schemas.Item, error] {
+	return func(yield func(schemas.Item, error) bool) {
+		for {
+			page, err := service.List(ctx, options)
+			if err != nil {
+				yield(schemas.Item{}, err)
+				return
+			}
+
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if page.NextCursor == nil {
+				return
+			}
+			options.Cursor = *page.NextCursor
+			// The count does not change between pages
+			options.WithCount = false
+		}
+	}
+}
+
+// Search returns a page of the items matching the query, ranked by relevance
+func (service *ItemsService) Search(ctx context.Context, searchQuery string, options ItemSearchOptions) (ItemSearchPage, error) {
+	query := url.Values{}
+	query.Set("q", searchQuery)
+	query.Set("page", strconv.Itoa(max(options.Page, 1)))
+	if options.PageSize > 0 {
+		query.Set("page-size", strconv.Itoa(options.PageSize))
+	} else {
+		query.Set("page-size", "20")
+	}
+	if options.WithFacets {
+		query.Set("facets", "true")
+	}
+
+	filters := options.Filters
+	if filters.Category != nil {
+		query.Set("category", *filters.Category)
+	}
+	if filters.CategoryId != nil {
+		query.Set("category-id", strconv.Itoa(int(*filters.CategoryId)))
+	}
+	if filters.SupplierId != nil {
+		query.Set("supplier-id", strconv.Itoa(int(*filters.SupplierId)))
+	}
+	if filters.MinPrice != nil {
+		query.Set("min-price", strconv.FormatFloat(*filters.MinPrice, 'f', -1, 64))
+	}
+	if filters.MaxPrice != nil {
+		query.Set("max-price", strconv.FormatFloat(*filters.MaxPrice, 'f', -1, 64))
+	}
+	if filters.StockStatus != nil {
+		query.Set("stock-status", *filters.StockStatus)
+	}
+
+	var page ItemSearchPage
+	err := service.client.do(ctx, http.MethodGet, "/items/search", query, nil, &page)
+	return page, err
+}
+
+// SearchAll iterates over every search result from the page of the options.
+// The facets are only fetched for the first page. The iteration stops after the first error.
+func (service *ItemsService) SearchAll(ctx context.Context, searchQuery string, options ItemSearchOptions) iter.Seq2[schemas.ItemSearchResult, error] {
+	return func(yield func(schemas.ItemSearchResult, error) bool) {
+		options.Page = max(options.Page, 1)
+		for {
+			page, err := service.Search(ctx, searchQuery, options)
+			if err != nil {
+				yield(schemas.ItemSearchResult{}, err)
+				return
+			}
+
+			for _, result := range page.Results {
+				if !yield(result, nil) {
+					return
+				}
+			}
+
+			if len(page.Results) == 0 || int64(page.Page*page.PageSize) >= page.Count {
+				return
+			}
+			options.Page++
+			options.WithFacets = false
+		}
+	}
+}
+
+// Create creates the item. It needs a name, description, quantity, supplier, category and purchase price.
+func (service *ItemsService) Create(ctx context.Context, item schemas.Item) (schemas.Item, error) {
+	var created schemas.Item
+	err := service.client.do(ctx, http.MethodPost, "/items/", nil, item, &created)
+	return created, err
+}
+
+// Update changes the given fields of the item, e.g. map[string]interface{}{"name": "Bolt"}
+func (service *ItemsService) Update(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Item, error) {
+	var item schemas.Item
+	err := service.client.do(ctx, http.MethodPatch, fmt.Sprintf("/items/%d", id), nil, updates, &item)
+	return item, err
+}
+
+func (service *ItemsService) Delete(ctx context.Context, id int8) error {
+	return service.client.do(ctx, http.MethodDelete, fmt.Sprintf("/items/%d", id), nil, nil, nil)
+}
+
+// StockMovements returns the latest stock movements of the item, newest first. The limit is between 1 and 500.
+func (service *ItemsService) StockMovements(ctx context.Context, id int8, limit int) ([]schemas.StockMovement, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	movements := []schemas.StockMovement{}
+	err := service.client.do(ctx, http.MethodGet, fmt.Sprintf("/items/%d/movements", id), query, nil, &movements)
+	return movements, err
+}
+
+// CreateStockMovement records a receipt, issue or adjustment of the stock of the item.
+// An issue without a lot of an item with lots is allocated over its lots and recorded per lot.
+func (service *ItemsService) CreateStockMovement(ctx context.Context, id int8, movement schemas.StockMovement) ([]schemas.StockMovement, error) {
+	created := []schemas.StockMovement{}
+	err := service.client.do(ctx, http.MethodPost, fmt.Sprintf("/items/%d/movements", id), nil, movement, &created)
+	return created, err
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// MaintenanceService sends the requests of /maintenance
+type MaintenanceService struct {
+	client *Client
+}
+
+// Purge removes the deleted items and suppliers, dispatched outbox events and finished webhook deliveries
+// that are older than the retention. A dry run only counts them.
+func (service *MaintenanceService) Purge(ctx context.Context, retentionDays int, dryRun bool) (schemas.PurgeResult, error) {
+	body := map[string]interface{}{
+		"retention_days": retentionDays,
+		"dry_run":        dryRun,
+	}
+
+	var result schemas.PurgeResult
+	err := service.client.do(ctx, http.MethodPost, "/maintenance/purge", nil, body, &result)
+	return result, err
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// SuppliersService sends the requests of /suppliers
+type SuppliersService struct {
+	client *Client
+}
+
+// List returns the suppliers with their contacts by name. An empty name returns all suppliers.
+func (service *SuppliersService) List(ctx context.Context, name string) ([]schemas.Supplier, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+
+	suppliers := []schemas.Supplier{}
+	err := service.client.do(ctx, http.MethodGet, "/suppliers", query, nil, &suppliers)
+	return suppliers, err
+}
+
+func (service *SuppliersService) Get(ctx context.Context, id int8) (schemas.Supplier, error) {
+	var supplier schemas.Supplier
+	err := service.client.do(ctx, http.MethodGet, fmt.Sprintf("/suppliers/%d", id), nil, nil, &supplier)
+	return supplier, err
+}
+
+// Create creates the supplier from its name, website, address and VAT number
+func (service *SuppliersService) Create(ctx context.Context, supplier schemas.Supplier) (schemas.Supplier, error) {
+	var created schemas.Supplier
+	err := service.client.do(ctx, http.MethodPost, "/suppliers/", nil, supplier, &created)
+	return created, err
+}
+
+// Update changes the given fields of the supplier, e.g. map[string]interface{}{"website": "https://example.com"}
+func (service *SuppliersService) Update(ctx context.Context, id int8, updates map[string]interface{}) (schemas.Supplier, error) {
+	var supplier schemas.Supplier
+	err := service.client.do(ctx, http.MethodPatch, fmt.Sprintf("/suppliers/%d", id), nil, updates, &supplier)
+	return supplier, err
+}
+
+// Delete soft deletes the supplier. It fails while items still have the supplier.
+func (service *SuppliersService) Delete(ctx context.Context, id int8) error {
+	return service.client.do(ctx, http.MethodDelete, fmt.Sprintf("/suppliers/%d", id), nil, nil, nil)
+}
+
+// Items returns the catalogue of the supplier: the items it sells, with their price and terms
+func (service *SuppliersService) Items(ctx context.Context, id int8) ([]schemas.SupplierItem, error) {
+	supplierItems := []schemas.SupplierItem{}
+	err := service.client.do(ctx, http.MethodGet, fmt.Sprintf("/suppliers/%d/items", id), nil, nil, &supplierItems)
+	return supplierItems, err
+}
+
+// ContactsService reads the contacts of suppliers.
+// The API returns the contacts as part of their supplier and has no routes to change them.
+type ContactsService struct {
+	client *Client
+}
+
+// List returns the contacts of the supplier
+func (service *ContactsService) List(ctx context.Context, supplierId int8) ([]schemas.SupplierContactInfo, error) {
+	supplier, err := service.client.Suppliers.Get(ctx, supplierId)
+	if err != nil {
+		return nil, err
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if supplier.ContactInfo == nil {
+		return []schemas.SupplierContactInfo{}, nil
+	}
+
+	return supplier.ContactInfo, nil
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	supabase "github.com/supabase-community/supabase-go"
+)
+
+// Rpc calls a Postgres function and returns the raw JSON result.
+// The supabase client does not return errors for RPC calls, so we detect them from the response body.
+// Postgres errors are returned as the raw JSON error body, which utils.ParsePostgresError understands.
+func Rpc(client *supabase.Client, name string, body interface{}) ([]byte, error) {
+	result := strings.TrimSpace(client.Rpc(name, "", body))
+	if result == "" {
+		return nil, fmt.Errorf("rpc %s returned an empty response", name)
+	}
+
+	if strings.HasPrefix(result, "{") {
+		var rpcErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(result), &rpcErr) == nil && rpcErr.Code != "" && rpcErr.Message != "" {
+			return nil, errors.New(result)
+		}
+	}
+
+	return []byte(result), nil
+}
@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// binaryContent are the content types of files, the others are text
+var binaryContent = []string{contentPDF, contentPNG, contentZIP}
+
+// Build returns the OpenAPI document of the routes below basePath.
+// Routes missing from the operations are still described, from their path and handler name.
+func Build(routes gin.RoutesInfo, basePath string) Document {
+	generator := newSchemaGenerator()
+	generator.schemaOf(schemas.ApiResponse{})
+	generator.schemaOf(ErrorResponse{})
+
+	documented := map[string]operation{}
+	for _, op := range operations {
+		documented[op.method+" "+op.path] = op
+	}
+
+	document := Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "InventoryManager API",
+			Description: "Every JSON response is an ApiResponse. Failed requests have success set to false and a message for the user.",
+			Version:     "1",
+		},
+		Servers: []Server{{Url: basePath}},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Responses: map[string]*Response{
+				"BadRequest": errorResponse("The request is invalid"),
+				"NotFound":   errorResponse("The resource does not exist"),
+				"Error":      errorResponse("The request failed"),
+			},
+		},
+	}
+
+	tags := map[string]struct{}{}
+	for _, route := range routes {
+		path, found := strings.CutPrefix(route.Path, basePath)
+		// Files served below a wildcard, such as the Swagger UI assets, are not operations
+		if !found || strings.Contains(path, "*") {
+			continue
+		}
+
+		op, exists := documented[route.Method+" "+path]
+		if !exists {
+			op = undocumentedOperation(route.Method, path, route.Handler)
+		}
+
+		tag := strings.Split(strings.Trim(path, "/"), "/")[0]
+		tags[tag] = struct{}{}
+
+		openAPIPath := toOpenAPIPath(path)
+		if document.Paths[openAPIPath] == nil {
+			document.Paths[openAPIPath] = PathItem{}
+		}
+		document.Paths[openAPIPath][strings.ToLower(route.Method)] = buildOperation(generator, op, tag)
+	}
+
+	for tag := range tags {
+		document.Tags = append(document.Tags, Tag{Name: tag})
+	}
+	sort.Slice(document.Tags, func(i, j int) bool { return document.Tags[i].Name < document.Tags[j].Name })
+
+	document.Components.Schemas = generator.components
+	return document
+}
+
+func errorResponse(description string) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]MediaType{
+			"application/json": {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}},
+		},
+	}
+}
+
+// toOpenAPIPath replaces the gin path parameters, e.g. /items/:id becomes /items/{id}
+func toOpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, found := strings.CutPrefix(segment, ":"); found {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParameters returns the parameters in the path. They are IDs, apart from barcodes.
+func pathParameters(path string) []Parameter {
+	parameters := []Parameter{}
+	for _, segment := range strings.Split(path, "/") {
+		name, found := strings.CutPrefix(segment, ":")
+		if !found {
+			continue
+		}
+
+		schema := &Schema{Type: "integer"}
+		if name == "code" {
+			schema = &Schema{Type: "string"}
+		}
+		parameters = append(parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	return parameters
+}
+
+func buildOperation(generator *schemaGenerator, op operation, tag string) *Operation {
+	parameters := append(pathParameters(op.path), op.query...)
+
+	built := &Operation{
+		Tags:        []string{tag},
+		Summary:     op.summary,
+		Description: op.description,
+		OperationId: op.id,
+		Parameters:  parameters,
+		Responses:   map[string]*Response{},
+	}
+
+	if op.request != nil {
+		description := ""
+		if op.method == http.MethodPatch {
+			description = "Only the given fields are updated"
+		}
+		built.RequestBody = &RequestBody{
+			Description: description,
+			Required:    true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: generator.schemaOf(op.request)},
+			},
+		}
+	}
+
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := &Response{Description: http.StatusText(status), Content: map[string]MediaType{}}
+	if status == http.StatusSwitchingProtocols {
+		success.Content = nil
+	} else if op.response != nil || len(op.content) == 0 {
+		// The data of the ApiResponse is the response of the operation
+		envelope := &Schema{Ref: "#/components/schemas/ApiResponse"}
+		if data := generator.schemaOf(op.response); data != nil {
+			envelope = &Schema{AllOf: []*Schema{
+				envelope,
+				{Type: "object", Properties: map[string]*Schema{"data": data}},
+			}}
+		}
+		success.Content["application/json"] = MediaType{Schema: envelope}
+	}
+	for _, contentType := range op.content {
+		schema := &Schema{Type: "string"}
+		if contentType == "application/json" {
+			schema = &Schema{Type: "object"}
+		}
+		if slices.Contains(binaryContent, contentType) {
+			schema = &Schema{Type: "string", Format: "binary"}
+		}
+		success.Content[contentType] = MediaType{Schema: schema}
+	}
+	built.Responses[strconv.Itoa(status)] = success
+
+	if len(parameters) > 0 || op.request != nil {
+		built.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+	}
+	if strings.Contains(op.path, ":") {
+		built.Responses["404"] = &Response{Ref: "#/components/responses/NotFound"}
+	}
+	built.Responses["default"] = &Response{Ref: "#/components/responses/Error"}
+
+	return built
+}
+
+// undocumentedOperation describes a route that is missing from the operations by its handler name,
+// e.g. github.com/.../items.GetItemHandler becomes "Get item"
+func undocumentedOperation(method string, path string, handler string) operation {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	name = strings.TrimSuffix(name, "Handler")
+
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, strings.ToLower(name[start:i]))
+			start = i
+		}
+	}
+	words = append(words, strings.ToLower(name[start:]))
+
+	summary := strings.Join(words, " ")
+	if summary != "" {
+		summary = strings.ToUpper(summary[:1]) + summary[1:]
+	}
+
+	return operation{
+		method:  method,
+		path:    path,
+		id:      strings.ToLower(method) + strings.ReplaceAll(toOpenAPIPath(path), "/", "_"),
+		summary: summary,
+	}
+}
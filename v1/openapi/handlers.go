@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate go run ../../cmd/fetch-swagger-ui -dir swagger-ui
+
+// swaggerUIAssetsPath is where the embedded Swagger UI assets are served, relative to the docs page
+const swaggerUIAssetsPath = "docs/assets"
+
+//go:embed swagger-ui.html
+var swaggerUIPage string
+
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(swaggerUIPage))
+
+// swaggerUIAssets holds the pinned swagger-ui-dist, which go generate fetches next to its VERSION file
+//
+//go:embed swagger-ui
+var swaggerUIAssets embed.FS
+
+// SwaggerUIVersion is the pinned version of swagger-ui-dist
+//
+//go:embed swagger-ui/VERSION
+var SwaggerUIVersion string
+
+// DefaultSwaggerUIAssetsUrl is used when the assets are not embedded and SWAGGER_UI_ASSETS_URL is not set
+var DefaultSwaggerUIAssetsUrl = "https://unpkg.com/swagger-ui-dist@" + strings.TrimSpace(SwaggerUIVersion)
+
+var (
+	documentMutex sync.RWMutex
+	document      *Document
+)
+
+// SetRoutes builds the OpenAPI document of the routes below basePath, once every route is registered
+func SetRoutes(routes gin.RoutesInfo, basePath string) {
+	built := Build(routes, basePath)
+
+	documentMutex.Lock()
+	defer documentMutex.Unlock()
+	document = &built
+}
+
+// swaggerUIAssetsEmbedded reports whether go generate fetched the assets before the build
+func swaggerUIAssetsEmbedded() bool {
+	_, err := fs.Stat(swaggerUIAssets, "swagger-ui/swagger-ui-bundle.js")
+	return err == nil
+}
+
+// GetSwaggerUIAssetsUrl returns where the Swagger UI scripts and styles are loaded from: SWAGGER_UI_ASSETS_URL,
+// the embedded assets, or the pinned version on the CDN when the binary was built without them.
+func GetSwaggerUIAssetsUrl() string {
+	if assetsUrl := os.Getenv("SWAGGER_UI_ASSETS_URL"); assetsUrl != "" {
+		return strings.TrimSuffix(assetsUrl, "/")
+	}
+	if swaggerUIAssetsEmbedded() {
+		return swaggerUIAssetsPath
+	}
+	return DefaultSwaggerUIAssetsUrl
+}
+
+func GetDocumentHandler(context *gin.Context) {
+	documentMutex.RLock()
+	defer documentMutex.RUnlock()
+
+	if document == nil {
+		slog.Error("The OpenAPI document is requested before the routes are set")
+		context.JSON(http.StatusServiceUnavailable, schemas.ApiResponse{
+			Success: false,
+			Message: "The OpenAPI document is not available yet",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, document)
+}
+
+func SwaggerUIHandler(context *gin.Context) {
+	context.Header("Content-Type", "text/html; charset=utf-8")
+	err := swaggerUITemplate.Execute(context.Writer, map[string]string{
+		"AssetsUrl": GetSwaggerUIAssetsUrl(),
+	})
+	if err != nil {
+		slog.Error("Failed to render Swagger UI", "error", err)
+	}
+}
+
+func SwaggerUIAssetsHandler(context *gin.Context) {
+	assets, _ := fs.Sub(swaggerUIAssets, "swagger-ui")
+	name := strings.TrimPrefix(context.Param("filepath"), "/")
+
+	// Only the files of swagger-ui-dist, not the directory or the VERSION file
+	info, err := fs.Stat(assets, name)
+	if err != nil || info.IsDir() || name == "VERSION" {
+		context.JSON(http.StatusNotFound, schemas.ApiResponse{
+			Success: false,
+			Message: "Swagger UI asset not found",
+		})
+		return
+	}
+
+	context.Header("Cache-Control", "public, max-age=86400")
+	context.FileFromFS(name, http.FS(assets))
+}
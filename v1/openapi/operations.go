@@ -0,0 +1,422 @@
+package openapi
+
+import (
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// operation documents a route registered in RouteHandler.
+// request is a value of the type of the JSON body, and response a value of the type of the data in the ApiResponse.
+type operation struct {
+	method   string
+	path     string
+	id       string
+	summary  string
+	request  interface{}
+	response interface{}
+	query    []Parameter
+	// status is the status of a successful response, 200 when it is not set
+	status int
+	// content replaces the JSON response, e.g. for files
+	content []string
+	// description explains the operation further
+	description string
+}
+
+// The request and response types below only exist to document bodies that are not a schema of their own
+
+// ItemPage is a page of items, by page number or by cursor
+type ItemPage struct {
+	Count      *int64         `json:"count"`
+	Page       int            `json:"page,omitempty"`
+	PageSize   int            `json:"pageSize,omitempty"`
+	Limit      int            `json:"limit,omitempty"`
+	NextCursor *string        `json:"nextCursor,omitempty"`
+	PrevCursor *string        `json:"prevCursor,omitempty"`
+	Data       []schemas.Item `json:"data"`
+}
+
+// ItemSearchPage is a page of search results, with the facets when they are asked for
+type ItemSearchPage struct {
+	Count    int64                      `json:"count"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"pageSize"`
+	Data     []schemas.ItemSearchResult `json:"data"`
+	Facets   *schemas.ItemFacets        `json:"facets,omitempty"`
+}
+
+type BarcodeRequest struct {
+	Code      string `json:"code"`
+	Symbology string `json:"symbology,omitempty"`
+}
+
+type CategoryMergeRequest struct {
+	SourceIds []int8 `json:"source_ids"`
+}
+
+type KitComponentRequest struct {
+	ComponentItemId int8 `json:"component_item_id,omitempty"`
+	Quantity        int  `json:"quantity"`
+}
+
+type KitOperationRequest struct {
+	Quantity int     `json:"quantity"`
+	Note     *string `json:"note,omitempty"`
+}
+
+type LabelRequest struct {
+	ItemIds  []int8  `json:"item_ids"`
+	Format   string  `json:"format,omitempty"`
+	Size     string  `json:"size,omitempty"`
+	WidthMm  float64 `json:"width_mm,omitempty"`
+	HeightMm float64 `json:"height_mm,omitempty"`
+	Code     string  `json:"code,omitempty"`
+	Content  string  `json:"content,omitempty"`
+}
+
+type UnitRequest struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type StocktakeRequest struct {
+	Name       string  `json:"name"`
+	Scope      string  `json:"scope,omitempty"`
+	Location   *string `json:"location,omitempty"`
+	CategoryId *int8   `json:"category_id,omitempty"`
+	Note       *string `json:"note,omitempty"`
+}
+
+type StocktakeCountsRequest struct {
+	Counter string                        `json:"counter"`
+	Counts  []schemas.StocktakeCountEntry `json:"counts"`
+}
+
+type StocktakeReviewRequest struct {
+	CountedQuantity *int `json:"counted_quantity"`
+}
+
+type StocktakeApprovalRequest struct {
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+type PurgeRequest struct {
+	RetentionDays *int `json:"retention_days,omitempty"`
+	DryRun        bool `json:"dry_run,omitempty"`
+}
+
+func queryParameter(name string, schemaType string, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: schemaType}}
+}
+
+var limitParameter = queryParameter("limit", "integer", "Number of results, between 1 and 500 (default 50)")
+
+// Query parameters of the item listing
+var itemFilterParameters = []Parameter{
+	queryParameter("tag", "string", "Only items with this tag, can be repeated"),
+	queryParameter("category-id", "integer", "Only items in this category or its subcategories"),
+	queryParameter("attr.{key}", "string", "Only items whose attribute key has this value"),
+}
+
+const (
+	contentCSV = "text/csv"
+	contentPDF = "application/pdf"
+	contentPNG = "image/png"
+	contentZIP = "application/zip"
+	contentSSE = "text/event-stream"
+)
+
+var operations = []operation{
+	// Items
+	{method: "GET", path: "/items", id: "listItems", summary: "List items", response: ItemPage{},
+		description: "Pages by page number when page is set, otherwise by cursor.",
+		query: append([]Parameter{
+			queryParameter("page", "integer", "Page number, starting at 1"),
+			queryParameter("page-size", "integer", "Items per page"),
+			queryParameter("cursor", "string", "Cursor of the page to return"),
+			queryParameter("limit", "integer", "Items per cursor page (default 20)"),
+			queryParameter("sort-by", "string", "Field to sort by (default name)"),
+			queryParameter("sort-order", "string", "asc or desc"),
+			queryParameter("include-count", "boolean", "Include the total count"),
+		}, itemFilterParameters...)},
+	{method: "GET", path: "/items/:id", id: "getItem", summary: "Get an item", response: schemas.Item{}},
+	{method: "GET", path: "/items/search", id: "searchItems", summary: "Search items", response: ItemSearchPage{},
+		description: "Ranked full-text and fuzzy search across the name, category, description, SKU and tags.",
+		query: []Parameter{
+			queryParameter("q", "string", "Search query"),
+			queryParameter("page", "integer", "Page number, starting at 1"),
+			queryParameter("page-size", "integer", "Results per page"),
+			queryParameter("facets", "boolean", "Include the facets of the results"),
+			queryParameter("category", "string", "Only items in the categories with this name or their subcategories"),
+			queryParameter("category-id", "integer", "Only items in this category or its subcategories"),
+			queryParameter("supplier-id", "integer", "Only items of this supplier"),
+			queryParameter("min-price", "number", "Minimum purchase price in the base currency"),
+			queryParameter("max-price", "number", "Maximum purchase price in the base currency"),
+			queryParameter("stock-status", "string", "in_stock, low_stock or out_of_stock"),
+		}},
+	{method: "GET", path: "/items/by-barcode/:code", id: "getItemByBarcode", summary: "Get an item by barcode", response: schemas.Item{}},
+	{method: "GET", path: "/items/:id/barcodes", id: "listItemBarcodes", summary: "List the barcodes of an item", response: []schemas.ItemBarcode{}},
+	{method: "GET", path: "/items/:id/movements", id: "listItemStockMovements", summary: "List the stock movements of an item", response: []schemas.StockMovement{},
+		query: []Parameter{limitParameter}},
+	{method: "PATCH", path: "/items/:id", id: "updateItem", summary: "Update an item", request: schemas.Item{}, response: schemas.Item{}},
+	{method: "POST", path: "/items/", id: "createItem", summary: "Create an item", request: schemas.Item{}, response: schemas.Item{}, status: 201},
+	{method: "DELETE", path: "/items/:id", id: "deleteItem", summary: "Delete an item",
+		description: "Fails while the item has active reservations. The SKU and barcodes of the item can be used again."},
+	{method: "POST", path: "/items/:id/barcodes", id: "createItemBarcode", summary: "Add a barcode to an item", request: BarcodeRequest{}, response: schemas.ItemBarcode{}, status: 201},
+	{method: "POST", path: "/items/:id/movements", id: "createItemStockMovement", summary: "Change the stock of an item", request: schemas.StockMovement{}, response: []schemas.StockMovement{}, status: 201,
+		description: "An issue without a lot of an item with lots is taken from its unexpired lots, first expired first out, and recorded per lot."},
+	{method: "DELETE", path: "/items/:id/barcodes/:barcodeId", id: "deleteItemBarcode", summary: "Remove a barcode from an item"},
+
+	// Item lots
+	{method: "GET", path: "/items/:id/lots", id: "listItemLots", summary: "List the lots of an item", response: []schemas.ItemLot{},
+		query: []Parameter{queryParameter("include-empty", "boolean", "Include lots without stock")}},
+	{method: "GET", path: "/items/:id/lots/fefo", id: "suggestLotIssue", summary: "Suggest the lots to issue, first expiry first out", response: schemas.LotIssueSuggestion{},
+		query: []Parameter{
+			queryParameter("quantity", "integer", "Quantity to issue"),
+			queryParameter("include-expired", "boolean", "Include expired lots"),
+		}},
+	{method: "POST", path: "/items/:id/lots/", id: "createItemLot", summary: "Create a lot of an item", request: schemas.ItemLot{}, response: schemas.ItemLot{}, status: 201},
+	{method: "PATCH", path: "/items/:id/lots/:lotId", id: "updateItemLot", summary: "Update a lot of an item", request: schemas.ItemLot{}, response: schemas.ItemLot{}},
+
+	// Item serials
+	{method: "GET", path: "/items/:id/serials", id: "listItemSerials", summary: "List the serials of an item", response: []schemas.ItemSerial{},
+		query: []Parameter{queryParameter("status", "string", "Only serials with this status")}},
+	{method: "POST", path: "/items/:id/serials/", id: "createItemSerial", summary: "Register a serial of an item", request: schemas.ItemSerial{}, response: schemas.ItemSerial{}, status: 201},
+
+	// Kits
+	{method: "GET", path: "/items/:id/components", id: "listKitComponents", summary: "List the components of a kit", response: []schemas.KitComponent{}},
+	{method: "GET", path: "/items/:id/available-to-build", id: "getKitAvailability", summary: "Get how many kits can be built", response: schemas.KitAvailability{}},
+	{method: "POST", path: "/items/:id/components", id: "addKitComponent", summary: "Add a component to a kit", request: KitComponentRequest{}, response: schemas.KitComponent{}, status: 201},
+	{method: "PATCH", path: "/items/:id/components/:componentId", id: "updateKitComponent", summary: "Update a component of a kit", request: KitComponentRequest{}, response: schemas.KitComponent{}},
+	{method: "DELETE", path: "/items/:id/components/:componentId", id: "deleteKitComponent", summary: "Remove a component from a kit"},
+	{method: "POST", path: "/items/:id/assemble", id: "assembleKits", summary: "Assemble kits from their components", request: KitOperationRequest{}, response: schemas.KitOperation{}, status: 201},
+	{method: "POST", path: "/items/:id/disassemble", id: "disassembleKits", summary: "Disassemble kits into their components", request: KitOperationRequest{}, response: schemas.KitOperation{}, status: 201},
+
+	// Item units
+	{method: "GET", path: "/items/:id/units", id: "listItemUnits", summary: "List the units of an item", response: []schemas.ItemUnit{}},
+	{method: "GET", path: "/items/:id/units/convert", id: "convertItemQuantity", summary: "Convert a quantity between units of an item", response: schemas.UnitConversion{},
+		query: []Parameter{
+			queryParameter("quantity", "number", "Quantity to convert"),
+			queryParameter("from", "string", "Unit code to convert from"),
+			queryParameter("to", "string", "Unit code to convert to"),
+		}},
+	{method: "POST", path: "/items/:id/units/", id: "createItemUnit", summary: "Add a unit to an item", request: schemas.ItemUnit{}, response: schemas.ItemUnit{}, status: 201},
+	{method: "PATCH", path: "/items/:id/units/:unitId", id: "updateItemUnit", summary: "Update a unit of an item", request: schemas.ItemUnit{}, response: schemas.ItemUnit{}},
+	{method: "DELETE", path: "/items/:id/units/:unitId", id: "deleteItemUnit", summary: "Remove a unit from an item"},
+
+	// Item suppliers
+	{method: "GET", path: "/items/:id/suppliers", id: "listItemSuppliers", summary: "List the suppliers of an item", response: []schemas.SupplierItem{}},
+	{method: "POST", path: "/items/:id/suppliers/", id: "createItemSupplier", summary: "Add a supplier to an item", request: schemas.SupplierItem{}, response: schemas.SupplierItem{}, status: 201},
+	{method: "PATCH", path: "/items/:id/suppliers/:supplierId", id: "updateItemSupplier", summary: "Update a supplier of an item", request: schemas.SupplierItem{}, response: schemas.SupplierItem{}},
+	{method: "DELETE", path: "/items/:id/suppliers/:supplierId", id: "deleteItemSupplier", summary: "Remove a supplier from an item"},
+
+	// Item reservations
+	{method: "GET", path: "/items/:id/reservations", id: "listItemReservations", summary: "List the reservations of an item", response: []schemas.StockReservation{},
+		query: []Parameter{queryParameter("status", "string", "Only reservations with this status")}},
+	{method: "POST", path: "/items/:id/reservations/", id: "createItemReservation", summary: "Reserve stock of an item", request: schemas.StockReservation{}, response: schemas.StockReservation{}, status: 201},
+
+	// Suppliers
+	{method: "GET", path: "/suppliers", id: "listSuppliers", summary: "List suppliers with their contacts", response: []schemas.Supplier{},
+		query: []Parameter{queryParameter("name", "string", "Only suppliers whose name contains this")}},
+	{method: "GET", path: "/suppliers/:id", id: "getSupplier", summary: "Get a supplier with its contacts", response: schemas.Supplier{}},
+	{method: "PATCH", path: "/suppliers/:id", id: "updateSupplier", summary: "Update a supplier", request: schemas.Supplier{}, response: schemas.Supplier{}},
+	{method: "POST", path: "/suppliers/", id: "createSupplier", summary: "Create a supplier", request: schemas.Supplier{}, response: schemas.Supplier{}, status: 201},
+	{method: "DELETE", path: "/suppliers/:id", id: "deleteSupplier", summary: "Delete a supplier",
+		description: "Fails while items still have the supplier. Its prices for items are removed."},
+	{method: "GET", path: "/suppliers/:id/items", id: "listSupplierItems", summary: "List the items of a supplier", response: []schemas.SupplierItem{}},
+	{method: "POST", path: "/suppliers/:id/items/", id: "createSupplierItem", summary: "Add an item to a supplier", request: schemas.SupplierItem{}, response: schemas.SupplierItem{}, status: 201},
+	{method: "PATCH", path: "/suppliers/:id/items/:itemId", id: "updateSupplierItem", summary: "Update an item of a supplier", request: schemas.SupplierItem{}, response: schemas.SupplierItem{}},
+	{method: "DELETE", path: "/suppliers/:id/items/:itemId", id: "deleteSupplierItem", summary: "Remove an item from a supplier"},
+
+	// Categories
+	{method: "GET", path: "/categories", id: "listCategories", summary: "List categories", response: []schemas.Category{},
+		query: []Parameter{queryParameter("tree", "boolean", "Nest the subcategories in their parents")}},
+	{method: "GET", path: "/categories/:id", id: "getCategory", summary: "Get a category", response: schemas.Category{}},
+	{method: "PATCH", path: "/categories/:id", id: "updateCategory", summary: "Update a category", request: schemas.Category{}, response: schemas.Category{}},
+	{method: "POST", path: "/categories/", id: "createCategory", summary: "Create a category", request: schemas.Category{}, response: schemas.Category{}, status: 201},
+	{method: "POST", path: "/categories/:id/merge", id: "mergeCategories", summary: "Merge categories into a category", request: CategoryMergeRequest{}, response: schemas.Category{}},
+	{method: "DELETE", path: "/categories/:id", id: "deleteCategory", summary: "Delete a category"},
+
+	// Attributes
+	{method: "GET", path: "/attributes", id: "listAttributeDefinitions", summary: "List attribute definitions", response: []schemas.AttributeDefinition{}},
+	{method: "GET", path: "/attributes/:id", id: "getAttributeDefinition", summary: "Get an attribute definition", response: schemas.AttributeDefinition{}},
+	{method: "PATCH", path: "/attributes/:id", id: "updateAttributeDefinition", summary: "Update an attribute definition", request: schemas.AttributeDefinition{}, response: schemas.AttributeDefinition{}},
+	{method: "POST", path: "/attributes/", id: "createAttributeDefinition", summary: "Create an attribute definition", request: schemas.AttributeDefinition{}, response: schemas.AttributeDefinition{}, status: 201},
+	{method: "DELETE", path: "/attributes/:id", id: "deleteAttributeDefinition", summary: "Delete an attribute definition"},
+
+	// Labels
+	{method: "GET", path: "/labels/items/:id", id: "getItemLabel", summary: "Render the label of an item", content: []string{contentPNG, contentPDF},
+		query: []Parameter{
+			queryParameter("format", "string", "png or pdf"),
+			queryParameter("size", "string", "Predefined label size: small, medium or large"),
+			queryParameter("code", "string", "qr or code128"),
+			queryParameter("content", "string", "Value encoded in the code"),
+			queryParameter("width-mm", "number", "Label width in millimetres"),
+			queryParameter("height-mm", "number", "Label height in millimetres"),
+		}},
+	{method: "POST", path: "/labels/", id: "createLabels", summary: "Render the labels of items", request: LabelRequest{}, content: []string{contentPDF, contentPNG, contentZIP}},
+
+	// Lots
+	{method: "GET", path: "/lots/expiring", id: "listExpiringLots", summary: "List lots that expire soon", response: []schemas.ItemLot{},
+		query: []Parameter{
+			queryParameter("days", "integer", "Days ahead to look"),
+			queryParameter("include-expired", "boolean", "Include expired lots"),
+		}},
+
+	// Serials
+	{method: "GET", path: "/serials", id: "findSerials", summary: "Find serials", response: []schemas.ItemSerial{},
+		query: []Parameter{
+			queryParameter("serial-number", "string", "Serial number to look for"),
+			queryParameter("status", "string", "Only serials with this status"),
+		}},
+	{method: "GET", path: "/serials/:id", id: "getSerial", summary: "Get a serial", response: schemas.ItemSerial{}},
+	{method: "GET", path: "/serials/:id/history", id: "getSerialHistory", summary: "Get the history of a serial", response: []schemas.ItemSerialEvent{}},
+	{method: "PATCH", path: "/serials/:id", id: "updateSerial", summary: "Update a serial", request: schemas.ItemSerial{}, response: schemas.ItemSerial{}},
+
+	// Units
+	{method: "GET", path: "/units", id: "listUnits", summary: "List units of measure", response: []schemas.UnitOfMeasure{}},
+	{method: "PATCH", path: "/units/:id", id: "updateUnit", summary: "Update a unit of measure", request: UnitRequest{}, response: schemas.UnitOfMeasure{}},
+	{method: "POST", path: "/units/", id: "createUnit", summary: "Create a unit of measure", request: UnitRequest{}, response: schemas.UnitOfMeasure{}, status: 201},
+	{method: "DELETE", path: "/units/:id", id: "deleteUnit", summary: "Delete a unit of measure"},
+
+	// Currencies
+	{method: "GET", path: "/currencies", id: "listCurrencies", summary: "List currencies", response: []schemas.Currency{}},
+	{method: "GET", path: "/exchange-rates", id: "listExchangeRates", summary: "List exchange rates", response: []schemas.ExchangeRate{},
+		query: []Parameter{
+			queryParameter("base", "string", "Only rates from this currency"),
+			queryParameter("quote", "string", "Only rates to this currency"),
+		}},
+	{method: "GET", path: "/exchange-rates/convert", id: "convertMoney", summary: "Convert an amount between currencies", response: schemas.CurrencyConversion{},
+		query: []Parameter{
+			queryParameter("amount-minor", "integer", "Amount in minor units"),
+			queryParameter("from", "string", "Currency to convert from"),
+			queryParameter("to", "string", "Currency to convert to"),
+			queryParameter("date", "string", "Date of the rate to use, YYYY-MM-DD"),
+		}},
+	{method: "POST", path: "/exchange-rates/", id: "createExchangeRate", summary: "Add an exchange rate", request: schemas.ExchangeRate{}, response: schemas.ExchangeRate{}, status: 201},
+	{method: "DELETE", path: "/exchange-rates/:id", id: "deleteExchangeRate", summary: "Delete an exchange rate"},
+
+	// Reports
+	{method: "GET", path: "/reports/valuation", id: "getValuationReport", summary: "Get the stock valuation report", response: schemas.ValuationReport{}, content: []string{contentCSV},
+		query: []Parameter{
+			queryParameter("as_of", "string", "Date to value the stock at, YYYY-MM-DD"),
+			queryParameter("method", "string", "fifo or weighted_average"),
+			queryParameter("format", "string", "json or csv"),
+		}},
+
+	// Stocktakes
+	{method: "GET", path: "/stocktakes", id: "listStocktakes", summary: "List stocktakes", response: []schemas.Stocktake{},
+		query: []Parameter{queryParameter("status", "string", "Only stocktakes with this status")}},
+	{method: "GET", path: "/stocktakes/:id", id: "getStocktake", summary: "Get a stocktake", response: schemas.Stocktake{}},
+	{method: "GET", path: "/stocktakes/:id/sheet", id: "getStocktakeSheet", summary: "Get the count sheet of a stocktake", response: []schemas.StocktakeLine{}, content: []string{contentCSV},
+		query: []Parameter{queryParameter("format", "string", "json or csv")}},
+	{method: "GET", path: "/stocktakes/:id/lines", id: "listStocktakeLines", summary: "List the lines of a stocktake", response: []schemas.StocktakeLine{},
+		query: []Parameter{
+			queryParameter("status", "string", "Only lines with this status"),
+			queryParameter("only-variances", "boolean", "Only lines whose count differs from the stock"),
+		}},
+	{method: "POST", path: "/stocktakes/", id: "createStocktake", summary: "Start a stocktake", request: StocktakeRequest{}, response: schemas.Stocktake{}, status: 201},
+	{method: "POST", path: "/stocktakes/:id/counts", id: "enterStocktakeCounts", summary: "Enter counts of a stocktake", request: StocktakeCountsRequest{}, response: []schemas.StocktakeCount{}, status: 201},
+	{method: "PATCH", path: "/stocktakes/:id/lines/:lineId", id: "reviewStocktakeLine", summary: "Set the reviewed count of a stocktake line", request: StocktakeReviewRequest{}, response: schemas.StocktakeLine{}},
+	{method: "POST", path: "/stocktakes/:id/submit", id: "submitStocktake", summary: "Submit a stocktake for review", response: schemas.Stocktake{}},
+	{method: "POST", path: "/stocktakes/:id/reopen", id: "reopenStocktake", summary: "Reopen a stocktake for counting", response: schemas.Stocktake{}},
+	{method: "POST", path: "/stocktakes/:id/approve", id: "approveStocktake", summary: "Approve a stocktake and adjust the stock", request: StocktakeApprovalRequest{}, response: schemas.StocktakeApproval{}},
+	{method: "POST", path: "/stocktakes/:id/cancel", id: "cancelStocktake", summary: "Cancel a stocktake", response: schemas.Stocktake{}},
+
+	// Reservations
+	{method: "GET", path: "/reservations", id: "listReservations", summary: "List reservations", response: []schemas.StockReservation{},
+		query: []Parameter{
+			queryParameter("status", "string", "Only reservations with this status"),
+			queryParameter("owner", "string", "Only reservations of this owner"),
+		}},
+	{method: "GET", path: "/reservations/:id", id: "getReservation", summary: "Get a reservation", response: schemas.StockReservation{}},
+	{method: "PATCH", path: "/reservations/:id", id: "updateReservation", summary: "Update a reservation", request: schemas.StockReservation{}, response: schemas.StockReservation{}},
+	{method: "POST", path: "/reservations/:id/release", id: "releaseReservation", summary: "Release a reservation", response: schemas.StockReservation{}},
+	{method: "POST", path: "/reservations/:id/fulfill", id: "fulfillReservation", summary: "Fulfill a reservation", response: schemas.StockReservation{}},
+
+	// Customers
+	{method: "GET", path: "/customers", id: "listCustomers", summary: "List customers", response: []schemas.Customer{},
+		query: []Parameter{queryParameter("name", "string", "Only customers whose name contains this")}},
+	{method: "GET", path: "/customers/:id", id: "getCustomer", summary: "Get a customer", response: schemas.Customer{}},
+	{method: "PATCH", path: "/customers/:id", id: "updateCustomer", summary: "Update a customer", request: schemas.Customer{}, response: schemas.Customer{}},
+	{method: "POST", path: "/customers/", id: "createCustomer", summary: "Create a customer", request: schemas.Customer{}, response: schemas.Customer{}, status: 201},
+	{method: "DELETE", path: "/customers/:id", id: "deleteCustomer", summary: "Delete a customer"},
+
+	// Sales orders
+	{method: "GET", path: "/sales-orders", id: "listSalesOrders", summary: "List sales orders", response: []schemas.SalesOrder{},
+		query: []Parameter{
+			queryParameter("status", "string", "Only orders with this status"),
+			queryParameter("customer-id", "integer", "Only orders of this customer"),
+		}},
+	{method: "GET", path: "/sales-orders/:id", id: "getSalesOrder", summary: "Get a sales order", response: schemas.SalesOrder{}},
+	{method: "PATCH", path: "/sales-orders/:id", id: "updateSalesOrder", summary: "Update a draft sales order", request: schemas.SalesOrder{}, response: schemas.SalesOrder{}},
+	{method: "POST", path: "/sales-orders/", id: "createSalesOrder", summary: "Create a sales order", request: schemas.SalesOrder{}, response: schemas.SalesOrder{}, status: 201},
+	{method: "DELETE", path: "/sales-orders/:id", id: "deleteSalesOrder", summary: "Delete a draft sales order"},
+	{method: "POST", path: "/sales-orders/:id/lines", id: "addSalesOrderLine", summary: "Add a line to a sales order", request: schemas.SalesOrderLine{}, response: schemas.SalesOrder{}, status: 201},
+	{method: "PATCH", path: "/sales-orders/:id/lines/:lineId", id: "updateSalesOrderLine", summary: "Update a line of a sales order", request: schemas.SalesOrderLine{}, response: schemas.SalesOrder{}},
+	{method: "DELETE", path: "/sales-orders/:id/lines/:lineId", id: "deleteSalesOrderLine", summary: "Remove a line from a sales order", response: schemas.SalesOrder{}},
+	{method: "POST", path: "/sales-orders/:id/confirm", id: "confirmSalesOrder", summary: "Confirm a sales order and reserve its stock", response: schemas.SalesOrder{}},
+	{method: "POST", path: "/sales-orders/:id/pick", id: "pickSalesOrder", summary: "Mark a sales order as picked", response: schemas.SalesOrder{}},
+	{method: "POST", path: "/sales-orders/:id/ship", id: "shipSalesOrder", summary: "Ship a sales order and issue its stock", response: schemas.SalesOrder{},
+		description: "Lines are shipped from their lot, or from the lots that expire first. Serialised items ship the serial numbers of their line, or the serials in stock the longest."},
+	{method: "POST", path: "/sales-orders/:id/cancel", id: "cancelSalesOrder", summary: "Cancel a sales order", response: schemas.SalesOrder{}},
+
+	// Returns
+	{method: "GET", path: "/returns", id: "listReturns", summary: "List returns", response: []schemas.Return{},
+		query: []Parameter{
+			queryParameter("type", "string", "customer or supplier"),
+			queryParameter("status", "string", "Only returns with this status"),
+			queryParameter("sales-order-id", "integer", "Only returns of this sales order"),
+		}},
+	{method: "GET", path: "/returns/:id", id: "getReturn", summary: "Get a return", response: schemas.Return{}},
+	{method: "PATCH", path: "/returns/:id", id: "updateReturn", summary: "Update an open return", request: schemas.Return{}, response: schemas.Return{}},
+	{method: "POST", path: "/returns/", id: "createReturn", summary: "Create a return", request: schemas.Return{}, response: schemas.Return{}, status: 201},
+	{method: "POST", path: "/returns/:id/lines", id: "addReturnLine", summary: "Add a line to a return", request: schemas.ReturnLine{}, response: schemas.Return{}, status: 201},
+	{method: "PATCH", path: "/returns/:id/lines/:lineId", id: "updateReturnLine", summary: "Update a line of a return", request: schemas.ReturnLine{}, response: schemas.Return{}},
+	{method: "DELETE", path: "/returns/:id/lines/:lineId", id: "deleteReturnLine", summary: "Remove a line from a return", response: schemas.Return{}},
+	{method: "POST", path: "/returns/:id/lines/:lineId/inspect", id: "inspectReturnLine", summary: "Record the inspection of a return line", request: schemas.ReturnInspection{}, response: schemas.Return{}},
+	{method: "POST", path: "/returns/:id/complete", id: "completeReturn", summary: "Complete a return and move its stock", response: schemas.ReturnCompletion{}},
+	{method: "POST", path: "/returns/:id/cancel", id: "cancelReturn", summary: "Cancel a return", response: schemas.Return{}},
+
+	// Webhooks
+	{method: "GET", path: "/webhooks", id: "listWebhookSubscriptions", summary: "List webhook subscriptions", response: []schemas.WebhookSubscription{}},
+	{method: "GET", path: "/webhooks/:id", id: "getWebhookSubscription", summary: "Get a webhook subscription", response: schemas.WebhookSubscription{}},
+	{method: "PATCH", path: "/webhooks/:id", id: "updateWebhookSubscription", summary: "Update a webhook subscription", request: schemas.WebhookSubscription{}, response: schemas.WebhookSubscription{}},
+	{method: "POST", path: "/webhooks/", id: "createWebhookSubscription", summary: "Create a webhook subscription", request: schemas.WebhookSubscription{}, response: schemas.WebhookSubscription{}, status: 201,
+		description: "The secret to verify the signatures is only returned here. The URL must be http or https and point to a public host, unless WEBHOOK_ALLOW_PRIVATE_URLS is set."},
+	{method: "DELETE", path: "/webhooks/:id", id: "deleteWebhookSubscription", summary: "Delete a webhook subscription"},
+	{method: "POST", path: "/webhooks/:id/ping", id: "pingWebhookSubscription", summary: "Send a test event to a webhook subscription", response: schemas.WebhookDelivery{}, status: 201},
+	{method: "GET", path: "/webhooks/:id/deliveries", id: "listWebhookDeliveries", summary: "List the deliveries of a webhook subscription", response: []schemas.WebhookDelivery{},
+		query: []Parameter{limitParameter, queryParameter("status", "string", "pending, succeeded or failed")}},
+	{method: "GET", path: "/webhooks/:id/deliveries/:deliveryId", id: "getWebhookDelivery", summary: "Get a webhook delivery", response: schemas.WebhookDelivery{}},
+	{method: "POST", path: "/webhooks/:id/deliveries/:deliveryId/replay", id: "replayWebhookDelivery", summary: "Send a webhook delivery again", response: schemas.WebhookDelivery{}, status: 201},
+
+	// Events
+	{method: "GET", path: "/events", id: "listEvents", summary: "List the events of the outbox", response: []schemas.Event{},
+		query: []Parameter{
+			limitParameter,
+			queryParameter("after", "integer", "Only events after this event ID"),
+			queryParameter("type", "string", "Only events of these types, comma separated"),
+			queryParameter("entity-type", "string", "item or supplier"),
+			queryParameter("entity-id", "integer", "Only events of this entity, requires entity-type"),
+			queryParameter("category-id", "integer", "Only item events in this category or its subcategories"),
+		}},
+	{method: "GET", path: "/stream", id: "streamEvents", summary: "Stream the events as Server-Sent Events", content: []string{contentSSE},
+		description: "Every event is sent with its ID, type and the Event as data. Send Last-Event-ID to resume. Takes the filters of listEvents.",
+		query: []Parameter{
+			queryParameter("type", "string", "Only events of these types, comma separated"),
+			queryParameter("entity-type", "string", "item or supplier"),
+			queryParameter("entity-id", "integer", "Only events of this entity, requires entity-type"),
+			queryParameter("category-id", "integer", "Only item events in this category or its subcategories"),
+			queryParameter("last-event-id", "integer", "Resume after this event ID, when the Last-Event-ID header can not be sent"),
+		}},
+	{method: "GET", path: "/stream/ws", id: "streamEventsWebSocket", summary: "Stream the events over a WebSocket", status: 101,
+		description: "Every event is sent as a JSON text message. Takes the query parameters of streamEvents."},
+
+	// Maintenance
+	{method: "POST", path: "/maintenance/purge", id: "purgeRecords", summary: "Purge old deleted records and event logs", request: PurgeRequest{}, response: schemas.PurgeResult{},
+		description: "Removes items and suppliers deleted before the retention (90 days by default), dispatched outbox events and finished webhook deliveries. A dry run only counts them."},
+
+	// Documentation
+	{method: "GET", path: "/openapi.json", id: "getOpenAPIDocument", summary: "Get this OpenAPI document", content: []string{"application/json"}},
+	{method: "GET", path: "/docs", id: "getAPIDocs", summary: "Browse this OpenAPI document in Swagger UI", content: []string{"text/html"}},
+}
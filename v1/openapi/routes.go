@@ -0,0 +1,11 @@
+package openapi
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupOpenAPIRoutes(routes *gin.RouterGroup) {
+	routes.GET("/openapi.json", GetDocumentHandler)
+	routes.GET("/docs", SwaggerUIHandler)
+	routes.GET("/docs/assets/*filepath", SwaggerUIAssetsHandler)
+}
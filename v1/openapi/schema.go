@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// readOnlyFields are set by the API and ignored in request bodies
+var readOnlyFields = []string{"id", "created_at", "updated_at", "deleted_at"}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// schemaGenerator derives schemas from Go types by their JSON encoding.
+// Named structs become components, which are referenced by their type name.
+type schemaGenerator struct {
+	components map[string]*Schema
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{components: map[string]*Schema{}}
+}
+
+// schemaOf returns the schema of the type of value, or nil when value is nil
+func (generator *schemaGenerator) schemaOf(value interface{}) *Schema {
+	if value == nil {
+		return nil
+	}
+	return generator.schemaFor(reflect.TypeOf(value))
+}
+
+func (generator *schemaGenerator) schemaFor(t reflect.Type) *Schema {
+	if t == rawMessageType {
+		return &Schema{Description: "Any JSON value"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := generator.schemaFor(t.Elem())
+		// A reference can not have siblings in OpenAPI 3.0, so a nullable reference is wrapped
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: generator.schemaFor(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return &Schema{Type: "object", AdditionalProperties: true}
+		}
+		return &Schema{Type: "object", AdditionalProperties: generator.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return generator.structSchema(t)
+		}
+
+		if _, exists := generator.components[t.Name()]; !exists {
+			// The placeholder stops recursive types from being generated forever
+			generator.components[t.Name()] = &Schema{}
+			*generator.components[t.Name()] = *generator.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		// Interfaces can hold any value
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of the exported fields of the struct.
+// Fields without omitempty are always present, so they are required.
+func (generator *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a name are flattened, like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := generator.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := generator.schemaFor(field.Type)
+		if slices.Contains(readOnlyFields, name) {
+			if fieldSchema.Ref != "" {
+				fieldSchema = &Schema{AllOf: []*Schema{fieldSchema}}
+			}
+			fieldSchema.ReadOnly = true
+		}
+		schema.Properties[name] = fieldSchema
+
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
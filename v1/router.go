@@ -1,8 +1,27 @@
 package v1
 
 import (
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/openapi"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/attributes"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/categories"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/currencies"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/customers"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/events"
 	items "github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/items"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/kits"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/labels"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/lots"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/maintenance"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/reports"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/reservations"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/returns"
+	salesorders "github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/sales-orders"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/serials"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/stocktakes"
+	supplieritems "github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/supplier-items"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/suppliers"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/units"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/webhooks"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,7 +29,70 @@ func RouteHandler(v1Routes *gin.RouterGroup) {
 
 	itemRoutes := v1Routes.Group("/items")
 	items.SetupItemRoutes(itemRoutes)
+	lots.SetupItemLotRoutes(itemRoutes.Group("/:id/lots"))
+	serials.SetupItemSerialRoutes(itemRoutes.Group("/:id/serials"))
+	kits.SetupItemKitRoutes(itemRoutes.Group("/:id"))
+	units.SetupItemUnitRoutes(itemRoutes.Group("/:id/units"))
+	supplieritems.SetupItemSupplierRoutes(itemRoutes.Group("/:id/suppliers"))
+	reservations.SetupItemReservationRoutes(itemRoutes.Group("/:id/reservations"))
 
 	supplierRoutes := v1Routes.Group("/suppliers")
 	suppliers.SetupSupplierRoutes(supplierRoutes)
+	supplieritems.SetupSupplierItemRoutes(supplierRoutes.Group("/:id/items"))
+
+	categoryRoutes := v1Routes.Group("/categories")
+	categories.SetupCategoryRoutes(categoryRoutes)
+
+	attributeRoutes := v1Routes.Group("/attributes")
+	attributes.SetupAttributeRoutes(attributeRoutes)
+
+	labelRoutes := v1Routes.Group("/labels")
+	labels.SetupLabelRoutes(labelRoutes)
+
+	lotRoutes := v1Routes.Group("/lots")
+	lots.SetupLotRoutes(lotRoutes)
+
+	serialRoutes := v1Routes.Group("/serials")
+	serials.SetupSerialRoutes(serialRoutes)
+
+	unitRoutes := v1Routes.Group("/units")
+	units.SetupUnitRoutes(unitRoutes)
+
+	currencyRoutes := v1Routes.Group("/currencies")
+	currencies.SetupCurrencyRoutes(currencyRoutes)
+
+	exchangeRateRoutes := v1Routes.Group("/exchange-rates")
+	currencies.SetupExchangeRateRoutes(exchangeRateRoutes)
+
+	reportRoutes := v1Routes.Group("/reports")
+	reports.SetupReportRoutes(reportRoutes)
+
+	stocktakeRoutes := v1Routes.Group("/stocktakes")
+	stocktakes.SetupStocktakeRoutes(stocktakeRoutes)
+
+	reservationRoutes := v1Routes.Group("/reservations")
+	reservations.SetupReservationRoutes(reservationRoutes)
+
+	customerRoutes := v1Routes.Group("/customers")
+	customers.SetupCustomerRoutes(customerRoutes)
+
+	salesOrderRoutes := v1Routes.Group("/sales-orders")
+	salesorders.SetupSalesOrderRoutes(salesOrderRoutes)
+
+	returnRoutes := v1Routes.Group("/returns")
+	returns.SetupReturnRoutes(returnRoutes)
+
+	webhookRoutes := v1Routes.Group("/webhooks")
+	webhooks.SetupWebhookRoutes(webhookRoutes)
+
+	eventRoutes := v1Routes.Group("/events")
+	events.SetupEventRoutes(eventRoutes)
+
+	streamRoutes := v1Routes.Group("/stream")
+	events.SetupStreamRoutes(streamRoutes)
+
+	maintenanceRoutes := v1Routes.Group("/maintenance")
+	maintenance.SetupMaintenanceRoutes(maintenanceRoutes)
+
+	openapi.SetupOpenAPIRoutes(v1Routes)
 }
@@ -0,0 +1,213 @@
+package attributes
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify.
+// The key and type can not change, as items already store values for them.
+var protectedFields = []string{"id", "key", "type", "created_at", "updated_at", "deleted_at"}
+
+func GetAttributeDefinitionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	definition, err := GetAttributeDefinition(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve attribute definition", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving attribute definition", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve attribute definition",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Attribute definition retrieved successfully",
+		Data:    definition,
+	})
+}
+
+func GetAttributeDefinitionsHandler(context *gin.Context) {
+	definitions, err := GetAttributeDefinitions()
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve attribute definitions", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving attribute definitions", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve attribute definitions",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Attribute definitions retrieved successfully",
+		Data:    definitions,
+	})
+}
+
+func CreateAttributeDefinitionHandler(context *gin.Context) {
+	var newDefinition schemas.AttributeDefinition
+	if err := context.ShouldBindJSON(&newDefinition); err != nil {
+		slog.Error("Failed to parse JSON of new attribute definition", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	if newDefinition.Key == "" || newDefinition.Name == "" || newDefinition.Type == "" {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "missing required field: key, name and type are required",
+		})
+		return
+	}
+
+	definition, err := CreateAttributeDefinition(newDefinition)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create attribute definition", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to create attribute definition", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create attribute definition",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Attribute definition created successfully",
+		Data:    definition,
+	})
+}
+
+func UpdateAttributeDefinitionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of updated attribute definition", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+
+	definition, err := UpdateAttributeDefinition(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update attribute definition", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to update attribute definition", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update attribute definition",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Attribute definition updated successfully",
+		Data:    definition,
+	})
+}
+
+func DeleteAttributeDefinitionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteAttributeDefinition(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete attribute definition", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to delete attribute definition", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete attribute definition",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Attribute definition deleted successfully",
+	})
+}
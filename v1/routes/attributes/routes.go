@@ -0,0 +1,14 @@
+package attributes
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAttributeRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetAttributeDefinitionsHandler)
+	routes.GET("/:id", GetAttributeDefinitionHandler)
+
+	routes.PATCH("/:id", UpdateAttributeDefinitionHandler)
+	routes.POST("/", CreateAttributeDefinitionHandler)
+	routes.DELETE("/:id", DeleteAttributeDefinitionHandler)
+}
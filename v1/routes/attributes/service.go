@@ -0,0 +1,399 @@
+package attributes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// KeyRegex matches valid attribute keys. Keys are used in filters, so they are kept simple.
+var KeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var attributeTypes = map[string]struct{}{
+	schemas.AttributeTypeString: {},
+	schemas.AttributeTypeNumber: {},
+	schemas.AttributeTypeEnum:   {},
+	schemas.AttributeTypeDate:   {},
+}
+
+func GetAttributeDefinition(id int8) (schemas.AttributeDefinition, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("attribute_definitions").
+		Select("*", "", false).
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the attribute definition"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Attribute definition not found"
+			}
+		}
+
+		return schemas.AttributeDefinition{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving attribute definition with ID %d: %v", id, err),
+		}
+	}
+
+	var definition schemas.AttributeDefinition
+	err = json.Unmarshal(data, &definition)
+	if err != nil {
+		return schemas.AttributeDefinition{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse attribute definition data",
+			Details: fmt.Sprintf("Error parsing attribute definition data for ID %d: %v", id, err),
+		}
+	}
+
+	return definition, nil
+}
+
+func GetAttributeDefinitions() ([]schemas.AttributeDefinition, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("attribute_definitions").
+		Select("*", "", false).
+		Is("deleted_at", "null").
+		Order("key", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving attribute definitions"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving attribute definitions: %v", err),
+		}
+	}
+
+	var definitions []schemas.AttributeDefinition
+	err = json.Unmarshal(data, &definitions)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse attribute definitions data",
+			Details: fmt.Sprintf("Error parsing attribute definitions data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if definitions == nil {
+		definitions = []schemas.AttributeDefinition{}
+	}
+
+	return definitions, nil
+}
+
+// validateDefinition checks that the key, type and options of a definition fit together
+func validateDefinition(definition schemas.AttributeDefinition) error {
+	if !KeyRegex.MatchString(definition.Key) {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid attribute key, must be lowercase letters, digits and underscores",
+			Details: fmt.Sprintf("Attribute key %q does not match %s", definition.Key, KeyRegex.String()),
+		}
+	}
+
+	if _, valid := attributeTypes[definition.Type]; !valid {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid attribute type: %s", definition.Type),
+			Details: fmt.Sprintf("Attribute %q has unknown type %q", definition.Key, definition.Type),
+		}
+	}
+
+	if definition.Type == schemas.AttributeTypeEnum && len(definition.Options) == 0 {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Enum attributes must have at least one option",
+			Details: fmt.Sprintf("Enum attribute %q has no options", definition.Key),
+		}
+	}
+
+	return nil
+}
+
+func CreateAttributeDefinition(definition schemas.AttributeDefinition) (schemas.AttributeDefinition, error) {
+	client := db.Connect()
+
+	if err := validateDefinition(definition); err != nil {
+		return schemas.AttributeDefinition{}, err
+	}
+
+	if definition.Options == nil {
+		definition.Options = []string{}
+	}
+
+	data, _, err := client.
+		From("attribute_definitions").
+		Insert(map[string]interface{}{
+			"key":        definition.Key,
+			"name":       definition.Name,
+			"type":       definition.Type,
+			"options":    definition.Options,
+			"required":   definition.Required,
+			"created_at": utils.GetCurrentISODate(),
+			"updated_at": utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the attribute definition"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = fmt.Sprintf("An attribute with key %s already exists", definition.Key)
+			}
+		}
+
+		return schemas.AttributeDefinition{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating attribute definition: %v", err),
+		}
+	}
+
+	var createdDefinition schemas.AttributeDefinition
+	err = json.Unmarshal(data, &createdDefinition)
+	if err != nil {
+		return schemas.AttributeDefinition{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse attribute definition data",
+			Details: fmt.Sprintf("Error parsing attribute definition data while creating attribute definition: %v", err),
+		}
+	}
+
+	return createdDefinition, nil
+}
+
+func UpdateAttributeDefinition(id int8, updates map[string]interface{}) (schemas.AttributeDefinition, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	// Enum attributes must keep at least one option
+	if options, exists := updates["options"]; exists {
+		current, err := GetAttributeDefinition(id)
+		if err != nil {
+			return schemas.AttributeDefinition{}, err
+		}
+
+		optionList, ok := options.([]interface{})
+		if !ok || (current.Type == schemas.AttributeTypeEnum && len(optionList) == 0) {
+			return schemas.AttributeDefinition{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Enum attributes must have at least one option",
+				Details: fmt.Sprintf("Invalid options for attribute definition %d: %v", id, options),
+			}
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("attribute_definitions").
+		Update(updates, "", "").
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the attribute definition"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Attribute definition not found"
+			}
+		}
+
+		return schemas.AttributeDefinition{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating attribute definition with ID %d: %v", id, err),
+		}
+	}
+
+	var updatedDefinition schemas.AttributeDefinition
+	err = json.Unmarshal(data, &updatedDefinition)
+	if err != nil {
+		return schemas.AttributeDefinition{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse attribute definition data",
+			Details: fmt.Sprintf("Error parsing attribute definition data for ID %d: %v", id, err),
+		}
+	}
+
+	return updatedDefinition, nil
+}
+
+// DeleteAttributeDefinition soft deletes the definition, so the values stored on items are kept
+func DeleteAttributeDefinition(id int8) error {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	_, _, err := client.
+		From("attribute_definitions").
+		Update(map[string]interface{}{
+			"deleted_at": utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the attribute definition"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Attribute definition not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting attribute definition with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+// ValidateAttributes checks the attribute values of an item against the attribute definitions.
+// Every key must be defined, every value must match the type of its definition,
+// and every required attribute must be present.
+func ValidateAttributes(values map[string]interface{}) error {
+	definitions, err := GetAttributeDefinitions()
+	if err != nil {
+		return err
+	}
+
+	definitionsByKey := make(map[string]schemas.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		definitionsByKey[definition.Key] = definition
+	}
+
+	for key, value := range values {
+		definition, exists := definitionsByKey[key]
+		if !exists {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Unknown attribute: %s", key),
+				Details: fmt.Sprintf("Item attribute %q has no attribute definition", key),
+			}
+		}
+
+		if !validValue(definition, value) {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid value for attribute %s, expected %s", key, describeType(definition)),
+				Details: fmt.Sprintf("Item attribute %q of type %s got value %v", key, definition.Type, value),
+			}
+		}
+	}
+
+	for _, definition := range definitions {
+		if _, exists := values[definition.Key]; definition.Required && !exists {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Missing required attribute: %s", definition.Key),
+				Details: fmt.Sprintf("Item is missing required attribute %q", definition.Key),
+			}
+		}
+	}
+
+	return nil
+}
+
+func validValue(definition schemas.AttributeDefinition, value interface{}) bool {
+	switch definition.Type {
+	case schemas.AttributeTypeString:
+		_, ok := value.(string)
+		return ok
+	case schemas.AttributeTypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case schemas.AttributeTypeEnum:
+		str, ok := value.(string)
+		return ok && slices.Contains(definition.Options, str)
+	case schemas.AttributeTypeDate:
+		str, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.Parse(time.DateOnly, str)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func describeType(definition schemas.AttributeDefinition) string {
+	switch definition.Type {
+	case schemas.AttributeTypeEnum:
+		return fmt.Sprintf("one of %v", definition.Options)
+	case schemas.AttributeTypeDate:
+		return "a date (YYYY-MM-DD)"
+	default:
+		return "a " + definition.Type
+	}
+}
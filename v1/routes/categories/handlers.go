@@ -0,0 +1,305 @@
+package categories
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify
+var protectedFields = []string{"id", "created_at", "updated_at", "deleted_at", "children"}
+
+func GetCategoryHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	category, err := GetCategory(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve category", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving category", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve category",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Category retrieved successfully",
+		Data:    category,
+	})
+}
+
+func GetCategoriesHandler(context *gin.Context) {
+	categories, err := GetCategories()
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve categories", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving categories", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve categories",
+		})
+		return
+	}
+
+	// With tree=true the categories are nested below their parents
+	if context.Query("tree") == "true" {
+		categories = BuildCategoryTree(categories)
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Categories retrieved successfully",
+		Data:    categories,
+	})
+}
+
+func CreateCategoryHandler(context *gin.Context) {
+	var categoryData map[string]interface{}
+	if err := context.ShouldBindJSON(&categoryData); err != nil {
+		slog.Error("Failed to parse JSON of new category", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(categoryData, []string{"name"})
+	if err != nil {
+		slog.Error("Missing required fields in category data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	name, ok := categoryData["name"].(string)
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid name",
+		})
+		return
+	}
+
+	newCategory := schemas.Category{Name: name}
+
+	if description, exists := categoryData["description"]; exists {
+		descriptionStr, ok := description.(string)
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid description",
+			})
+			return
+		}
+		newCategory.Description = descriptionStr
+	}
+
+	if parent, exists := categoryData["parent_id"]; exists && parent != nil {
+		parentId, ok := parent.(float64)
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid parent_id",
+			})
+			return
+		}
+		id := int8(parentId)
+		newCategory.ParentId = &id
+	}
+
+	category, err := CreateCategory(newCategory)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create category", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to create category", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create category",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Category created successfully",
+		Data:    category,
+	})
+}
+
+func UpdateCategoryHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of updated category", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+
+	category, err := UpdateCategory(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update category", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to update category", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update category",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Category updated successfully",
+		Data:    category,
+	})
+}
+
+func DeleteCategoryHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteCategory(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete category", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to delete category", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete category",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Category deleted successfully",
+	})
+}
+
+func MergeCategoriesHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var body struct {
+		SourceIds []int8 `json:"source_ids"`
+	}
+	if err := context.ShouldBindJSON(&body); err != nil || len(body.SourceIds) == 0 {
+		slog.Error("Failed to parse JSON of category merge", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Body must contain a non-empty source_ids array.",
+		})
+		return
+	}
+
+	category, err := MergeCategories(id, body.SourceIds)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to merge categories", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to merge categories", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to merge categories",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Categories merged successfully",
+		Data:    category,
+	})
+}
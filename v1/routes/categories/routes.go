@@ -0,0 +1,15 @@
+package categories
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCategoryRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetCategoriesHandler)
+	routes.GET("/:id", GetCategoryHandler)
+
+	routes.PATCH("/:id", UpdateCategoryHandler)
+	routes.POST("/", CreateCategoryHandler)
+	routes.POST("/:id/merge", MergeCategoriesHandler)
+	routes.DELETE("/:id", DeleteCategoryHandler)
+}
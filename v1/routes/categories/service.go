@@ -0,0 +1,398 @@
+package categories
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+func GetCategory(id int8) (schemas.Category, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("categories").
+		Select("*", "", false).
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the category"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Category not found"
+			}
+		}
+
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving category with ID %d: %v", id, err),
+		}
+	}
+
+	var category schemas.Category
+	err = json.Unmarshal(data, &category)
+	if err != nil {
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse category data",
+			Details: fmt.Sprintf("Error parsing category data for ID %d: %v", id, err),
+		}
+	}
+
+	return category, nil
+}
+
+func GetCategories() ([]schemas.Category, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("categories").
+		Select("*", "", false).
+		Is("deleted_at", "null").
+		Order("name", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving categories"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving categories: %v", err),
+		}
+	}
+
+	var categories []schemas.Category
+	err = json.Unmarshal(data, &categories)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse categories data",
+			Details: fmt.Sprintf("Error parsing categories data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if categories == nil {
+		categories = []schemas.Category{}
+	}
+
+	return categories, nil
+}
+
+// BuildCategoryTree nests the categories below their parents and returns the root categories
+func BuildCategoryTree(categories []schemas.Category) []schemas.Category {
+	childrenByParent := map[int8][]schemas.Category{}
+	var roots []schemas.Category
+
+	for _, category := range categories {
+		if category.ParentId == nil {
+			roots = append(roots, category)
+		} else {
+			childrenByParent[*category.ParentId] = append(childrenByParent[*category.ParentId], category)
+		}
+	}
+
+	var attachChildren func(nodes []schemas.Category) []schemas.Category
+	attachChildren = func(nodes []schemas.Category) []schemas.Category {
+		for i := range nodes {
+			nodes[i].Children = attachChildren(childrenByParent[nodes[i].Id])
+		}
+		return nodes
+	}
+
+	if roots == nil {
+		return []schemas.Category{}
+	}
+	return attachChildren(roots)
+}
+
+// GetCategoryDescendantIds returns the id of the category and the ids of all its descendants
+func GetCategoryDescendantIds(id int8) ([]int8, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "category_descendant_ids", map[string]interface{}{
+		"root_id": id,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the category descendants"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving descendants of category with ID %d: %v", id, err),
+		}
+	}
+
+	var ids []int8
+	err = json.Unmarshal(data, &ids)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse category descendants",
+			Details: fmt.Sprintf("Error parsing descendants of category with ID %d: %v", id, err),
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+			Details: fmt.Sprintf("Category with ID %d has no descendant ids, so it does not exist", id),
+		}
+	}
+
+	return ids, nil
+}
+
+func CreateCategory(category schemas.Category) (schemas.Category, error) {
+	client := db.Connect()
+
+	if category.ParentId != nil {
+		if _, err := GetCategory(*category.ParentId); err != nil {
+			return schemas.Category{}, err
+		}
+	}
+
+	data, _, err := client.
+		From("categories").
+		Insert(map[string]interface{}{
+			"name":        category.Name,
+			"description": category.Description,
+			"parent_id":   category.ParentId,
+			"created_at":  utils.GetCurrentISODate(),
+			"updated_at":  utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the category"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating category: %v", err),
+		}
+	}
+
+	var createdCategory schemas.Category
+	err = json.Unmarshal(data, &createdCategory)
+	if err != nil {
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse category data",
+			Details: fmt.Sprintf("Error parsing category data while creating category: %v", err),
+		}
+	}
+
+	return createdCategory, nil
+}
+
+func UpdateCategory(id int8, updates map[string]interface{}) (schemas.Category, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	// A category can not be moved below itself or one of its descendants
+	if parent, exists := updates["parent_id"]; exists && parent != nil {
+		parentId, ok := parent.(float64)
+		if !ok {
+			return schemas.Category{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid parent_id",
+				Details: fmt.Sprintf("Expected a number for parent_id of category %d, got %v", id, parent),
+			}
+		}
+
+		descendantIds, err := GetCategoryDescendantIds(id)
+		if err != nil {
+			return schemas.Category{}, err
+		}
+
+		if slices.Contains(descendantIds, int8(parentId)) {
+			return schemas.Category{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "A category can not be moved below itself",
+				Details: fmt.Sprintf("Category %d can not get parent %d, as it is one of its descendants", id, int8(parentId)),
+			}
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("categories").
+		Update(updates, "", "").
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the category"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Category not found"
+			}
+		}
+
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating category with ID %d: %v", id, err),
+		}
+	}
+
+	var updatedCategory schemas.Category
+	err = json.Unmarshal(data, &updatedCategory)
+	if err != nil {
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse category data",
+			Details: fmt.Sprintf("Error parsing category data for ID %d: %v", id, err),
+		}
+	}
+
+	return updatedCategory, nil
+}
+
+// DeleteCategory deletes a category. Categories that still have items or children
+// can not be deleted, which the database reports as a foreign key violation.
+// Items that are soft deleted lose the category instead.
+func DeleteCategory(id int8) error {
+	client := db.Connect()
+
+	_, err := db.Rpc(client, "delete_category", map[string]interface{}{
+		"target_id": id,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the category"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Category not found"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Category still has items or subcategories"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting category with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+// MergeCategories moves the items and subcategories of the source categories
+// to the target category and deletes the source categories
+func MergeCategories(targetId int8, sourceIds []int8) (schemas.Category, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "merge_categories", map[string]interface{}{
+		"target_id":  targetId,
+		"source_ids": sourceIds,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while merging categories"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Category not found"
+			} else if code == http.StatusBadRequest {
+				message = "A category can not be merged into itself or its descendants"
+			}
+		}
+
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error merging categories %v into %d: %v", sourceIds, targetId, err),
+		}
+	}
+
+	var category schemas.Category
+	err = json.Unmarshal(data, &category)
+	if err != nil {
+		return schemas.Category{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse category data",
+			Details: fmt.Sprintf("Error parsing category data after merging into %d: %v", targetId, err),
+		}
+	}
+
+	return category, nil
+}
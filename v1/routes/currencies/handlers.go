@@ -0,0 +1,226 @@
+package currencies
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func GetCurrenciesHandler(context *gin.Context) {
+	currencies, err := GetCurrencies()
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve currencies", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving currencies", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve currencies",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Currencies retrieved successfully",
+		Data:    currencies,
+	})
+}
+
+func GetExchangeRatesHandler(context *gin.Context) {
+	baseCurrency := context.Query("base")
+	quoteCurrency := context.Query("quote")
+
+	rates, err := GetExchangeRates(baseCurrency, quoteCurrency)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve exchange rates", "base", baseCurrency, "quote", quoteCurrency, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving exchange rates", "base", baseCurrency, "quote", quoteCurrency, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve exchange rates",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Exchange rates retrieved successfully",
+		Data:    rates,
+	})
+}
+
+func CreateExchangeRateHandler(context *gin.Context) {
+	var rateData map[string]interface{}
+	if err := context.ShouldBindJSON(&rateData); err != nil {
+		slog.Error("Failed to parse JSON of new exchange rate", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(rateData, []string{"base_currency", "quote_currency", "rate"})
+	if err != nil {
+		slog.Error("Missing required fields in exchange rate data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	baseCurrency, baseOk := rateData["base_currency"].(string)
+	quoteCurrency, quoteOk := rateData["quote_currency"].(string)
+	rate, rateOk := rateData["rate"].(float64)
+	if !baseOk || !quoteOk || !rateOk {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "base_currency and quote_currency must be strings and rate must be a number",
+		})
+		return
+	}
+
+	newRate := schemas.ExchangeRate{
+		BaseCurrency:  baseCurrency,
+		QuoteCurrency: quoteCurrency,
+		Rate:          rate,
+	}
+
+	if effectiveDate, exists := rateData["effective_date"].(string); exists {
+		newRate.EffectiveDate = effectiveDate
+	}
+
+	createdRate, err := CreateExchangeRate(newRate)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create exchange rate", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating exchange rate", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create exchange rate",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Exchange rate created successfully",
+		Data:    createdRate,
+	})
+}
+
+func DeleteExchangeRateHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteExchangeRate(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete exchange rate", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when deleting exchange rate", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete exchange rate",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Exchange rate deleted successfully",
+	})
+}
+
+func ConvertMoneyHandler(context *gin.Context) {
+	amountMinor, err := strconv.ParseInt(context.Query("amount-minor"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "amount-minor must be a whole number",
+		})
+		return
+	}
+
+	from := context.Query("from")
+	if from == "" {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "from is required",
+		})
+		return
+	}
+
+	// Without a target currency the amount is converted into the base currency
+	to := context.Query("to")
+	date := context.Query("date")
+
+	conversion, err := ConvertMoney(schemas.Money{AmountMinor: amountMinor, Currency: from}, to, date)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to convert amount", "from", from, "to", to, "date", date, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when converting amount", "from", from, "to", to, "date", date, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to convert amount",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Amount converted successfully",
+		Data:    conversion,
+	})
+}
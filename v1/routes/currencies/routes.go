@@ -0,0 +1,17 @@
+package currencies
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCurrencyRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetCurrenciesHandler)
+}
+
+func SetupExchangeRateRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetExchangeRatesHandler)
+	routes.GET("/convert", ConvertMoneyHandler)
+
+	routes.POST("/", CreateExchangeRateHandler)
+	routes.DELETE("/:id", DeleteExchangeRateHandler)
+}
@@ -0,0 +1,423 @@
+package currencies
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+func GetCurrencies() ([]schemas.Currency, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("currencies").
+		Select("*", "", false).
+		Order("code", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving currencies"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving currencies: %v", err),
+		}
+	}
+
+	var currencies []schemas.Currency
+	err = json.Unmarshal(data, &currencies)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse currency data",
+			Details: fmt.Sprintf("Error parsing currency data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if currencies == nil {
+		currencies = []schemas.Currency{}
+	}
+
+	return currencies, nil
+}
+
+// GetExchangeRates returns the exchange rates, newest first.
+// Rates can be filtered by base and quote currency, empty filters are ignored.
+func GetExchangeRates(baseCurrency string, quoteCurrency string) ([]schemas.ExchangeRate, error) {
+	client := db.Connect()
+
+	query := client.
+		From("exchange_rates").
+		Select("*", "", false)
+
+	if baseCurrency != "" {
+		query = query.Eq("base_currency", strings.ToUpper(baseCurrency))
+	}
+	if quoteCurrency != "" {
+		query = query.Eq("quote_currency", strings.ToUpper(quoteCurrency))
+	}
+
+	data, _, err := query.
+		Order("effective_date", &postgrest.OrderOpts{Ascending: false}).
+		Order("base_currency", &postgrest.OrderOpts{Ascending: true}).
+		Order("quote_currency", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving exchange rates"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving exchange rates for %q/%q: %v", baseCurrency, quoteCurrency, err),
+		}
+	}
+
+	var rates []schemas.ExchangeRate
+	err = json.Unmarshal(data, &rates)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse exchange rate data",
+			Details: fmt.Sprintf("Error parsing exchange rate data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if rates == nil {
+		rates = []schemas.ExchangeRate{}
+	}
+
+	return rates, nil
+}
+
+// CreateExchangeRate records a rate. A rate for the same pair and date is replaced.
+func CreateExchangeRate(rate schemas.ExchangeRate) (schemas.ExchangeRate, error) {
+	client := db.Connect()
+
+	rate.BaseCurrency = strings.ToUpper(rate.BaseCurrency)
+	rate.QuoteCurrency = strings.ToUpper(rate.QuoteCurrency)
+
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return schemas.ExchangeRate{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "The base and quote currency must be different",
+			Details: fmt.Sprintf("Attempted to create exchange rate from %s to itself", rate.BaseCurrency),
+		}
+	}
+
+	if rate.Rate <= 0 {
+		return schemas.ExchangeRate{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "rate must be a positive number",
+			Details: fmt.Sprintf("Invalid exchange rate %v for %s/%s", rate.Rate, rate.BaseCurrency, rate.QuoteCurrency),
+		}
+	}
+
+	if rate.EffectiveDate == "" {
+		rate.EffectiveDate = utils.GetCurrentISODay()
+	} else if _, err := time.Parse(time.DateOnly, rate.EffectiveDate); err != nil {
+		return schemas.ExchangeRate{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid effective_date, expected YYYY-MM-DD",
+			Details: fmt.Sprintf("Failed to parse effective date %q: %v", rate.EffectiveDate, err),
+		}
+	}
+
+	data, _, err := client.
+		From("exchange_rates").
+		Insert(map[string]interface{}{
+			"base_currency":  rate.BaseCurrency,
+			"quote_currency": rate.QuoteCurrency,
+			"rate":           rate.Rate,
+			"effective_date": rate.EffectiveDate,
+			"created_at":     utils.GetCurrentISODate(),
+		}, true, "base_currency,quote_currency,effective_date", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the exchange rate"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusUnprocessableEntity {
+				message = "Unknown currency"
+			}
+		}
+
+		return schemas.ExchangeRate{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating exchange rate %s/%s: %v", rate.BaseCurrency, rate.QuoteCurrency, err),
+		}
+	}
+
+	var createdRate schemas.ExchangeRate
+	err = json.Unmarshal(data, &createdRate)
+	if err != nil {
+		return schemas.ExchangeRate{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse exchange rate data",
+			Details: fmt.Sprintf("Error parsing exchange rate data while creating rate: %v", err),
+		}
+	}
+
+	return createdRate, nil
+}
+
+func DeleteExchangeRate(id int8) error {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	_, _, err := client.
+		From("exchange_rates").
+		Delete("", "").
+		Eq("id", idStr).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the exchange rate"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Exchange rate not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting exchange rate with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+// RateTable converts amounts between currencies with the rates that were effective on a date.
+// It is loaded once, so reports can convert many amounts without querying the rates again.
+type RateTable struct {
+	Date string
+	// minorUnits holds the number of decimals per currency
+	minorUnits map[string]int
+	// rates holds the latest rate per base and quote currency
+	rates map[[2]string]float64
+}
+
+// NewRateTable builds a rate table from the currencies and the rates. Rates must be sorted newest first,
+// so the first rate of a pair that is effective on the date is used.
+func NewRateTable(date string, currencies []schemas.Currency, rates []schemas.ExchangeRate) *RateTable {
+	table := &RateTable{
+		Date:       date,
+		minorUnits: map[string]int{},
+		rates:      map[[2]string]float64{},
+	}
+
+	for _, currency := range currencies {
+		table.minorUnits[currency.Code] = currency.MinorUnit
+	}
+
+	for _, rate := range rates {
+		// Dates in YYYY-MM-DD format can be compared as strings
+		if rate.EffectiveDate > date {
+			continue
+		}
+
+		pair := [2]string{rate.BaseCurrency, rate.QuoteCurrency}
+		if _, exists := table.rates[pair]; !exists {
+			table.rates[pair] = rate.Rate
+		}
+	}
+
+	return table
+}
+
+// LoadRateTable loads the rates that were effective on the date. An empty date is today.
+func LoadRateTable(date string) (*RateTable, error) {
+	if date == "" {
+		date = utils.GetCurrentISODay()
+	}
+
+	currencies, err := GetCurrencies()
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := GetExchangeRates("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRateTable(date, currencies, rates), nil
+}
+
+// MinorUnit returns the number of decimals of the currency
+func (table *RateTable) MinorUnit(currency string) (int, bool) {
+	minorUnit, exists := table.minorUnits[currency]
+	return minorUnit, exists
+}
+
+// directRate returns the rate from one currency to another, using the inverse rate if needed
+func (table *RateTable) directRate(from string, to string) (float64, bool) {
+	if rate, exists := table.rates[[2]string{from, to}]; exists {
+		return rate, true
+	}
+	if rate, exists := table.rates[[2]string{to, from}]; exists {
+		return 1 / rate, true
+	}
+	return 0, false
+}
+
+// Rate returns how much one from is worth in to.
+// Without a rate between the two currencies, a rate through a third currency is used.
+func (table *RateTable) Rate(from string, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	if rate, exists := table.directRate(from, to); exists {
+		return rate, nil
+	}
+
+	// Prefer the base currency as the third currency, as most rates are kept against it
+	baseCurrency := utils.GetBaseCurrency()
+	if fromRate, exists := table.directRate(from, baseCurrency); exists {
+		if toRate, exists := table.directRate(baseCurrency, to); exists {
+			return fromRate * toRate, nil
+		}
+	}
+
+	for currency := range table.minorUnits {
+		if fromRate, exists := table.directRate(from, currency); exists {
+			if toRate, exists := table.directRate(currency, to); exists {
+				return fromRate * toRate, nil
+			}
+		}
+	}
+
+	return 0, &schemas.CustomError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: fmt.Sprintf("No exchange rate from %s to %s on %s", from, to, table.Date),
+		Details: fmt.Sprintf("No direct or cross exchange rate found from %s to %s effective on %s", from, to, table.Date),
+	}
+}
+
+// Convert converts an amount in minor units of one currency to minor units of another currency
+func (table *RateTable) Convert(amount schemas.Money, to string) (schemas.Money, error) {
+	fromMinorUnit, fromExists := table.minorUnits[amount.Currency]
+	toMinorUnit, toExists := table.minorUnits[to]
+	if !fromExists || !toExists {
+		unknown := amount.Currency
+		if fromExists {
+			unknown = to
+		}
+		return schemas.Money{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Unknown currency: %s", unknown),
+			Details: fmt.Sprintf("Currency %q is not in the currencies table", unknown),
+		}
+	}
+
+	rate, err := table.Rate(amount.Currency, to)
+	if err != nil {
+		return schemas.Money{}, err
+	}
+
+	converted := utils.MinorToMajor(amount.AmountMinor, fromMinorUnit) * rate
+	return schemas.Money{
+		AmountMinor: utils.MajorToMinor(converted, toMinorUnit),
+		Currency:    to,
+	}, nil
+}
+
+// ConvertAmount converts a decimal amount, like a unit cost, from one currency to another
+func (table *RateTable) ConvertAmount(amount float64, from string, to string) (float64, error) {
+	rate, err := table.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// ConvertMoney converts an amount in minor units with the rates that were effective on the date.
+// An empty date is today and an empty target currency is the base currency.
+func ConvertMoney(amount schemas.Money, to string, date string) (schemas.CurrencyConversion, error) {
+	amount.Currency = strings.ToUpper(amount.Currency)
+	to = strings.ToUpper(to)
+	if to == "" {
+		to = utils.GetBaseCurrency()
+	}
+
+	if date != "" {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return schemas.CurrencyConversion{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid date, expected YYYY-MM-DD",
+				Details: fmt.Sprintf("Failed to parse conversion date %q: %v", date, err),
+			}
+		}
+	}
+
+	rates, err := LoadRateTable(date)
+	if err != nil {
+		return schemas.CurrencyConversion{}, err
+	}
+
+	converted, err := rates.Convert(amount, to)
+	if err != nil {
+		return schemas.CurrencyConversion{}, err
+	}
+
+	rate, err := rates.Rate(amount.Currency, to)
+	if err != nil {
+		return schemas.CurrencyConversion{}, err
+	}
+
+	return schemas.CurrencyConversion{
+		From: amount,
+		To:   converted,
+		Rate: rate,
+		Date: rates.Date,
+	}, nil
+}
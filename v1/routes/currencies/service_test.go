@@ -0,0 +1,109 @@
+package currencies
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func testRateTable(t *testing.T) *RateTable {
+	t.Setenv("BASE_CURRENCY", "EUR")
+
+	currencies := []schemas.Currency{
+		{Code: "EUR", MinorUnit: 2},
+		{Code: "USD", MinorUnit: 2},
+		{Code: "DKK", MinorUnit: 2},
+		{Code: "JPY", MinorUnit: 0},
+		{Code: "GBP", MinorUnit: 2},
+		{Code: "CHF", MinorUnit: 2},
+	}
+
+	// Sorted newest first, as GetExchangeRates returns them
+	rates := []schemas.ExchangeRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.2, EffectiveDate: "2026-11-01"},
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.1, EffectiveDate: "2026-10-01"},
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.0, EffectiveDate: "2026-09-01"},
+		{BaseCurrency: "EUR", QuoteCurrency: "DKK", Rate: 7.5, EffectiveDate: "2026-10-01"},
+		{BaseCurrency: "JPY", QuoteCurrency: "EUR", Rate: 0.006, EffectiveDate: "2026-10-01"},
+		{BaseCurrency: "GBP", QuoteCurrency: "CHF", Rate: 1.1, EffectiveDate: "2026-10-01"},
+	}
+
+	return NewRateTable("2026-10-19", currencies, rates)
+}
+
+func TestRateTableRate(t *testing.T) {
+	table := testRateTable(t)
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want float64
+	}{
+		{name: "same currency", from: "USD", to: "USD", want: 1},
+		{name: "direct", from: "EUR", to: "USD", want: 1.1},
+		{name: "inverse", from: "USD", to: "EUR", want: 1 / 1.1},
+		{name: "inverse of a quote in the base currency", from: "EUR", to: "JPY", want: 1 / 0.006},
+		{name: "cross through the base currency", from: "USD", to: "DKK", want: 7.5 / 1.1},
+		{name: "cross of two inverse rates", from: "JPY", to: "USD", want: 0.006 * 1.1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := table.Rate(test.from, test.to)
+			if err != nil {
+				t.Fatalf("Rate(%s, %s) returned error: %v", test.from, test.to, err)
+			}
+			if math.Abs(got-test.want) > 1e-9 {
+				t.Fatalf("Rate(%s, %s) = %v, want %v", test.from, test.to, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRateTableRateMissing(t *testing.T) {
+	table := testRateTable(t)
+
+	// GBP and CHF only have a rate between each other, not to the others
+	if _, err := table.Rate("GBP", "USD"); err == nil {
+		t.Fatal("Rate(GBP, USD) returned no error")
+	}
+
+	// Rates that are not effective yet on the date are not used
+	future := NewRateTable("2026-08-01", nil, []schemas.ExchangeRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.0, EffectiveDate: "2026-09-01"},
+	})
+	if _, err := future.Rate("EUR", "USD"); err == nil {
+		t.Fatal("Rate(EUR, USD) before the effective date returned no error")
+	}
+}
+
+func TestRateTableConvert(t *testing.T) {
+	table := testRateTable(t)
+
+	tests := []struct {
+		amount schemas.Money
+		to     string
+		want   int64
+	}{
+		{schemas.Money{AmountMinor: 1000, Currency: "EUR"}, "USD", 1100},
+		{schemas.Money{AmountMinor: 1000, Currency: "EUR"}, "JPY", 1667},
+		{schemas.Money{AmountMinor: 1000, Currency: "JPY"}, "EUR", 600},
+		{schemas.Money{AmountMinor: 1, Currency: "EUR"}, "EUR", 1},
+	}
+
+	for _, test := range tests {
+		got, err := table.Convert(test.amount, test.to)
+		if err != nil {
+			t.Fatalf("Convert(%+v, %s) returned error: %v", test.amount, test.to, err)
+		}
+		if got.AmountMinor != test.want || got.Currency != test.to {
+			t.Errorf("Convert(%+v, %s) = %+v, want %d %s", test.amount, test.to, got, test.want, test.to)
+		}
+	}
+
+	if _, err := table.Convert(schemas.Money{AmountMinor: 100, Currency: "XXX"}, "EUR"); err == nil {
+		t.Error("Convert from an unknown currency returned no error")
+	}
+}
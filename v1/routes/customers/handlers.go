@@ -0,0 +1,247 @@
+package customers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify
+var protectedFields = []string{"id", "created_at", "updated_at", "deleted_at"}
+
+func GetCustomersHandler(context *gin.Context) {
+	name := context.Query("name")
+
+	customers, err := GetCustomers(name)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve customers", "name", name, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving customers", "name", name, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve customers",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Customers retrieved successfully",
+		Data:    customers,
+	})
+}
+
+func GetCustomerHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	customer, err := GetCustomer(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve customer", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving customer", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve customer",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Customer retrieved successfully",
+		Data:    customer,
+	})
+}
+
+func CreateCustomerHandler(context *gin.Context) {
+	var customerData map[string]interface{}
+	if err := context.ShouldBindJSON(&customerData); err != nil {
+		slog.Error("Failed to parse JSON of new customer", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(customerData, []string{"name"})
+	if err != nil {
+		slog.Error("Missing required fields in customer data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	name, ok := customerData["name"].(string)
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "name must be a string",
+		})
+		return
+	}
+
+	newCustomer := schemas.Customer{Name: name}
+
+	// optionalString returns the string field, or nil when it is not set
+	optionalString := func(field string) *string {
+		if value, exists := customerData[field].(string); exists {
+			return &value
+		}
+		return nil
+	}
+	newCustomer.Email = optionalString("email")
+	newCustomer.Phone = optionalString("phone")
+	newCustomer.Address = optionalString("address")
+	newCustomer.VatNumber = optionalString("vat_number")
+	newCustomer.Notes = optionalString("notes")
+
+	customer, err := CreateCustomer(newCustomer)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create customer", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating customer", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create customer",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Customer created successfully",
+		Data:    customer,
+	})
+}
+
+func UpdateCustomerHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of customer update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	customer, err := UpdateCustomer(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update customer", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating customer", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update customer",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Customer updated successfully",
+		Data:    customer,
+	})
+}
+
+func DeleteCustomerHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteCustomer(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete customer", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when deleting customer", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete customer",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Customer deleted successfully",
+	})
+}
@@ -0,0 +1,14 @@
+package customers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCustomerRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetCustomersHandler)
+	routes.GET("/:id", GetCustomerHandler)
+
+	routes.PATCH("/:id", UpdateCustomerHandler)
+	routes.POST("/", CreateCustomerHandler)
+	routes.DELETE("/:id", DeleteCustomerHandler)
+}
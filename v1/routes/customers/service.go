@@ -0,0 +1,273 @@
+package customers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// GetCustomers returns the customers by name. An empty name returns all customers.
+func GetCustomers(name string) ([]schemas.Customer, error) {
+	client := db.Connect()
+
+	query := client.
+		From("customers").
+		Select("*", "", false).
+		Is("deleted_at", "null")
+
+	if name = strings.TrimSpace(name); name != "" {
+		query = query.Ilike("name", "%"+name+"%")
+	}
+
+	data, _, err := query.
+		Order("name", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving customers"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving customers matching %q: %v", name, err),
+		}
+	}
+
+	var customers []schemas.Customer
+	err = json.Unmarshal(data, &customers)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse customer data",
+			Details: fmt.Sprintf("Error parsing customer data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if customers == nil {
+		customers = []schemas.Customer{}
+	}
+
+	return customers, nil
+}
+
+func GetCustomer(id int8) (schemas.Customer, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("customers").
+		Select("*", "", false).
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the customer"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Customer not found"
+			}
+		}
+
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving customer with ID %d: %v", id, err),
+		}
+	}
+
+	var customer schemas.Customer
+	err = json.Unmarshal(data, &customer)
+	if err != nil {
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse customer data",
+			Details: fmt.Sprintf("Error parsing customer data for ID %d: %v", id, err),
+		}
+	}
+
+	return customer, nil
+}
+
+func CreateCustomer(customer schemas.Customer) (schemas.Customer, error) {
+	client := db.Connect()
+
+	customer.Name = strings.TrimSpace(customer.Name)
+	if customer.Name == "" {
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "name can not be empty",
+			Details: "Attempted to create a customer without a name",
+		}
+	}
+
+	data, _, err := client.
+		From("customers").
+		Insert(map[string]interface{}{
+			"name":       customer.Name,
+			"email":      customer.Email,
+			"phone":      customer.Phone,
+			"address":    customer.Address,
+			"vat_number": customer.VatNumber,
+			"notes":      customer.Notes,
+			"created_at": utils.GetCurrentISODate(),
+			"updated_at": utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the customer"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating customer %q: %v", customer.Name, err),
+		}
+	}
+
+	var createdCustomer schemas.Customer
+	err = json.Unmarshal(data, &createdCustomer)
+	if err != nil {
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse customer data",
+			Details: fmt.Sprintf("Error parsing customer data while creating customer: %v", err),
+		}
+	}
+
+	return createdCustomer, nil
+}
+
+func UpdateCustomer(id int8, updates map[string]interface{}) (schemas.Customer, error) {
+	client := db.Connect()
+
+	if name, exists := updates["name"]; exists {
+		if nameStr, ok := name.(string); !ok || strings.TrimSpace(nameStr) == "" {
+			return schemas.Customer{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "name can not be empty",
+				Details: fmt.Sprintf("Invalid name %v for customer %d", name, id),
+			}
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("customers").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the customer"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Customer not found"
+			}
+		}
+
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating customer with ID %d: %v", id, err),
+		}
+	}
+
+	var customer schemas.Customer
+	err = json.Unmarshal(data, &customer)
+	if err != nil {
+		return schemas.Customer{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse customer data",
+			Details: fmt.Sprintf("Error parsing customer data for ID %d: %v", id, err),
+		}
+	}
+
+	return customer, nil
+}
+
+func DeleteCustomer(id int8) error {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	_, _, err := client.
+		From("customers").
+		Delete("", "").
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the customer"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Customer not found"
+			} else if code == http.StatusConflict || code == http.StatusUnprocessableEntity {
+				message = "Customer still has sales orders"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting customer with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
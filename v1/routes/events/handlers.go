@@ -0,0 +1,248 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/categories"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// entityTypes contains the entity types the events can be filtered by
+var entityTypes = []string{schemas.EventEntityItem, schemas.EventEntitySupplier}
+
+// parseEventFilters reads the type, entity-type, entity-id and category-id filters from the query.
+// The category filter includes the descendants of the category.
+func parseEventFilters(context *gin.Context) (EventFilters, error) {
+	filters := EventFilters{
+		EntityType: context.Query("entity-type"),
+	}
+
+	if types := context.Query("type"); types != "" {
+		filters.Types = strings.Split(types, ",")
+	}
+
+	if filters.EntityType != "" && !slices.Contains(entityTypes, filters.EntityType) {
+		return EventFilters{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid entity type, must be one of: %s", strings.Join(entityTypes, ", ")),
+			Details: fmt.Sprintf("Unknown entity type %q", filters.EntityType),
+		}
+	}
+
+	if entityIdStr := context.Query("entity-id"); entityIdStr != "" {
+		entityId, err := strconv.ParseInt(entityIdStr, 10, 64)
+		if err != nil {
+			return EventFilters{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid entity ID",
+				Details: fmt.Sprintf("Invalid entity ID %q: %v", entityIdStr, err),
+			}
+		}
+
+		// Items and suppliers have separate IDs
+		if filters.EntityType == "" {
+			return EventFilters{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "entity-type is required with entity-id",
+				Details: fmt.Sprintf("Entity ID %d without entity type", entityId),
+			}
+		}
+		filters.EntityId = &entityId
+	}
+
+	if categoryIdStr := context.Query("category-id"); categoryIdStr != "" {
+		categoryId, err := strconv.ParseInt(categoryIdStr, 10, 8)
+		if err != nil {
+			return EventFilters{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid category ID",
+				Details: fmt.Sprintf("Invalid category ID %q: %v", categoryIdStr, err),
+			}
+		}
+
+		ids, err := categories.GetCategoryDescendantIds(int8(categoryId))
+		if err != nil {
+			return EventFilters{}, err
+		}
+		filters.CategoryIds = ids
+	}
+
+	return filters, nil
+}
+
+// parseLastEventId returns the event ID to resume after, from the Last-Event-ID header or the
+// last-event-id query parameter. It returns nil when the stream is not resumed.
+func parseLastEventId(context *gin.Context) (*int64, error) {
+	lastEventIdStr := context.GetHeader("Last-Event-ID")
+	if lastEventIdStr == "" {
+		lastEventIdStr = context.Query("last-event-id")
+	}
+	if lastEventIdStr == "" {
+		return nil, nil
+	}
+
+	lastEventId, err := strconv.ParseInt(lastEventIdStr, 10, 64)
+	if err != nil || lastEventId < 0 {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid last event ID",
+			Details: fmt.Sprintf("Invalid last event ID %q", lastEventIdStr),
+		}
+	}
+
+	return &lastEventId, nil
+}
+
+// respondWithError writes the error of a request that failed before it started streaming
+func respondWithError(context *gin.Context, err error, logMessage string, fallbackMessage string) {
+	if utils.IsCustomError(err) {
+		customErr := err.(*schemas.CustomError)
+		slog.Error(logMessage, "error", customErr.Details)
+		context.JSON(customErr.Code, schemas.ApiResponse{
+			Success: false,
+			Message: customErr.Message,
+		})
+		return
+	}
+
+	slog.Error(logMessage, "error", err)
+	context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+		Success: false,
+		Message: fallbackMessage,
+	})
+}
+
+// GetEventsHandler returns the events after the given event ID, filtered by type, entity and category
+func GetEventsHandler(context *gin.Context) {
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", "50"))
+	if err != nil || !utils.InRange(limit, 1, 500) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid limit, must be between 1 and 500",
+		})
+		return
+	}
+
+	after, err := strconv.ParseInt(context.DefaultQuery("after", "0"), 10, 64)
+	if err != nil || after < 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid after, must be an event ID",
+		})
+		return
+	}
+
+	filters, err := parseEventFilters(context)
+	if err != nil {
+		respondWithError(context, err, "Failed to parse event filters", "Failed to retrieve events")
+		return
+	}
+	filters.After = after
+
+	events, err := GetEvents(filters, limit)
+	if err != nil {
+		respondWithError(context, err, "Failed to retrieve events", "Failed to retrieve events")
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Events retrieved successfully",
+		Data:    events,
+	})
+}
+
+// StreamHandler streams the events as Server-Sent Events. A client that reconnects sends the
+// Last-Event-ID header and receives the events it missed first.
+func StreamHandler(context *gin.Context) {
+	filters, err := parseEventFilters(context)
+	if err != nil {
+		respondWithError(context, err, "Failed to parse stream filters", "Failed to open the event stream")
+		return
+	}
+
+	lastEventId, err := parseLastEventId(context)
+	if err != nil {
+		respondWithError(context, err, "Failed to parse the last event ID", "Failed to open the event stream")
+		return
+	}
+
+	writer := context.Writer
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	// Stop proxies such as nginx from buffering the stream
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+
+	// Ask the client to reconnect after 3 seconds when the stream is closed
+	fmt.Fprint(writer, "retry: 3000\n\n")
+	writer.Flush()
+
+	send := func(event schemas.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(writer, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data); err != nil {
+			return err
+		}
+		writer.Flush()
+		return nil
+	}
+
+	keepAlive := func() error {
+		if _, err := fmt.Fprint(writer, ": keep-alive\n\n"); err != nil {
+			return err
+		}
+		writer.Flush()
+		return nil
+	}
+
+	err = StreamEvents(context.Request.Context(), filters, lastEventId, send, keepAlive)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			slog.Error("Event stream failed", "error", err.(*schemas.CustomError).Details)
+		} else {
+			slog.Warn("Event stream closed", "error", err)
+		}
+	}
+}
+
+// StreamWebSocketHandler streams the events over a WebSocket, as one JSON message per event.
+// The stream is resumed with the last-event-id query parameter.
+func StreamWebSocketHandler(context *gin.Context) {
+	filters, err := parseEventFilters(context)
+	if err != nil {
+		respondWithError(context, err, "Failed to parse stream filters", "Failed to open the event stream")
+		return
+	}
+
+	lastEventId, err := parseLastEventId(context)
+	if err != nil {
+		respondWithError(context, err, "Failed to parse the last event ID", "Failed to open the event stream")
+		return
+	}
+
+	server := websocket.Server{
+		// The API has no origin restrictions, so any origin can open a stream
+		Handshake: func(config *websocket.Config, request *http.Request) error {
+			return nil
+		},
+		Handler: func(connection *websocket.Conn) {
+			serveWebSocket(connection, filters, lastEventId)
+		},
+	}
+
+	server.ServeHTTP(context.Writer, context.Request)
+}
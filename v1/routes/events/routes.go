@@ -0,0 +1,14 @@
+package events
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupEventRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetEventsHandler)
+}
+
+func SetupStreamRoutes(routes *gin.RouterGroup) {
+	routes.GET("", StreamHandler)
+	routes.GET("/ws", StreamWebSocketHandler)
+}
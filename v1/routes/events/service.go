@@ -0,0 +1,286 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// EventFilters narrows down the events of the outbox
+type EventFilters struct {
+	// After only returns the events with a higher ID
+	After      int64
+	Types      []string
+	EntityType string
+	EntityId   *int64
+	// CategoryIds only returns the item events of items in these categories
+	CategoryIds []int8
+}
+
+// Matches reports whether the event passes the filters, apart from After
+func (filters EventFilters) Matches(outboxEvent schemas.OutboxEvent) bool {
+	if len(filters.Types) > 0 && !slices.Contains(filters.Types, outboxEvent.Type) {
+		return false
+	}
+	if filters.EntityType != "" && outboxEvent.EntityType != filters.EntityType {
+		return false
+	}
+	if filters.EntityId != nil && outboxEvent.EntityId != *filters.EntityId {
+		return false
+	}
+
+	if len(filters.CategoryIds) > 0 {
+		var item struct {
+			CategoryId *int8 `json:"category_id"`
+		}
+		if outboxEvent.EntityType != schemas.EventEntityItem || json.Unmarshal(outboxEvent.Payload, &item) != nil || item.CategoryId == nil {
+			return false
+		}
+		return slices.Contains(filters.CategoryIds, *item.CategoryId)
+	}
+
+	return true
+}
+
+// ToEvent returns the outbox event as it is sent to sinks
+func ToEvent(outboxEvent schemas.OutboxEvent) schemas.Event {
+	return schemas.Event{
+		Id:         strconv.FormatInt(outboxEvent.Id, 10),
+		Type:       outboxEvent.Type,
+		EntityType: outboxEvent.EntityType,
+		EntityId:   outboxEvent.EntityId,
+		CreatedAt:  outboxEvent.CreatedAt,
+		Data:       outboxEvent.Payload,
+	}
+}
+
+// GetEvents returns the events of the outbox in the order they were recorded, oldest first
+func GetEvents(filters EventFilters, limit int) ([]schemas.Event, error) {
+	outboxEvents, err := getOutboxEvents(filters, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]schemas.Event, 0, len(outboxEvents))
+	for _, outboxEvent := range outboxEvents {
+		events = append(events, ToEvent(outboxEvent))
+	}
+
+	return events, nil
+}
+
+// getOutboxEvents returns the events of the outbox ordered by ID, without their dispatch state
+func getOutboxEvents(filters EventFilters, limit int) ([]schemas.OutboxEvent, error) {
+	client := db.Connect()
+
+	query := client.
+		From("outbox_events").
+		Select("id, type, entity_type, entity_id, payload, created_at", "", false).
+		Gt("id", strconv.FormatInt(filters.After, 10))
+
+	if len(filters.Types) > 0 {
+		query = query.In("type", filters.Types)
+	}
+	if filters.EntityType != "" {
+		query = query.Eq("entity_type", filters.EntityType)
+	}
+	if filters.EntityId != nil {
+		query = query.Eq("entity_id", strconv.FormatInt(*filters.EntityId, 10))
+	}
+	if len(filters.CategoryIds) > 0 {
+		idStrs := make([]string, 0, len(filters.CategoryIds))
+		for _, categoryId := range filters.CategoryIds {
+			idStrs = append(idStrs, fmt.Sprintf("%d", categoryId))
+		}
+		query = query.
+			Eq("entity_type", schemas.EventEntityItem).
+			In("payload->>category_id", idStrs)
+	}
+
+	data, _, err := query.
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving events"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving events after %d: %v", filters.After, err),
+		}
+	}
+
+	var outboxEvents []schemas.OutboxEvent
+	err = json.Unmarshal(data, &outboxEvents)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse event data",
+			Details: fmt.Sprintf("Error parsing events after %d: %v", filters.After, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if outboxEvents == nil {
+		outboxEvents = []schemas.OutboxEvent{}
+	}
+
+	return outboxEvents, nil
+}
+
+// getLatestEventId returns the ID of the latest event of the outbox, or 0 when it is empty
+func getLatestEventId() (int64, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("outbox_events").
+		Select("id", "", false).
+		Order("id", &postgrest.OrderOpts{Ascending: false}).
+		Limit(1, "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the latest event"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return 0, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving the latest event: %v", err),
+		}
+	}
+
+	var latest []struct {
+		Id int64 `json:"id"`
+	}
+	err = json.Unmarshal(data, &latest)
+	if err != nil {
+		return 0, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse event data",
+			Details: fmt.Sprintf("Error parsing the latest event: %v", err),
+		}
+	}
+
+	if len(latest) == 0 {
+		return 0, nil
+	}
+
+	return latest[0].Id, nil
+}
+
+// claimEvents leases the events that are due for dispatching
+func claimEvents() ([]schemas.OutboxEvent, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "claim_outbox_events", map[string]interface{}{
+		"batch_size":    dispatchBatchSize,
+		"lease_seconds": int(dispatchLease.Seconds()),
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while claiming outbox events"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error claiming outbox events: %v", err),
+		}
+	}
+
+	var claimed []schemas.OutboxEvent
+	err = json.Unmarshal(data, &claimed)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse outbox event data",
+			Details: fmt.Sprintf("Error parsing claimed outbox events: %v", err),
+		}
+	}
+
+	return claimed, nil
+}
+
+// recordDispatch stores the sinks that received the event. The event is dispatched when every sink received it,
+// otherwise it is dispatched again to the remaining sinks after a delay.
+func recordDispatch(outboxEvent schemas.OutboxEvent, deliveredTo []string, dispatchErr error) error {
+	client := db.Connect()
+
+	attempts := outboxEvent.Attempts + 1
+
+	updates := map[string]interface{}{
+		"delivered_to": deliveredTo,
+		"attempts":     attempts,
+	}
+
+	if dispatchErr == nil {
+		updates["dispatched_at"] = utils.GetCurrentISODate()
+		updates["last_error"] = nil
+	} else {
+		updates["last_error"] = dispatchErr.Error()
+		updates["next_attempt_at"] = time.Now().UTC().Add(RetryDelay(attempts)).Format(time.RFC3339)
+	}
+
+	_, _, err := client.
+		From("outbox_events").
+		Update(updates, "", "").
+		Eq("id", strconv.FormatInt(outboxEvent.Id, 10)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while recording the dispatch of an event"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error recording dispatch %d of outbox event %d: %v", attempts, outboxEvent.Id, err),
+		}
+	}
+
+	return nil
+}
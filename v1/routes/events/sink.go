@@ -0,0 +1,44 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// Sink receives the events of the outbox. Every event is delivered at least once to every registered sink,
+// so a sink can receive an event again when the dispatcher stops before recording the delivery.
+type Sink interface {
+	// Name identifies the sink in the delivered_to column of the outbox, so it must never change
+	Name() string
+	// Deliver returns an error when the event must be delivered again later
+	Deliver(event schemas.Event) error
+}
+
+var (
+	sinksMutex sync.RWMutex
+	sinks      []Sink
+)
+
+// RegisterSink adds a sink to deliver the events to. It panics when a sink with the same name is registered.
+func RegisterSink(sink Sink) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+
+	for _, registered := range sinks {
+		if registered.Name() == sink.Name() {
+			panic(fmt.Sprintf("events: sink %q is registered twice", sink.Name()))
+		}
+	}
+
+	sinks = append(sinks, sink)
+}
+
+// registeredSinks returns a copy of the registered sinks
+func registeredSinks() []Sink {
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+
+	return append([]Sink{}, sinks...)
+}
@@ -0,0 +1,245 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+const (
+	// DefaultStreamPollInterval is used when STREAM_POLL_INTERVAL is not set
+	DefaultStreamPollInterval = time.Second
+	// streamBatchSize is the number of events read from the outbox at a time
+	streamBatchSize = 500
+	// streamBufferSize is the number of events a stream can fall behind before it is closed
+	streamBufferSize = 256
+	// streamHeartbeat is how often an idle stream sends a keep-alive
+	streamHeartbeat = 15 * time.Second
+	// gapTimeout is how long the tailer waits for a missing event ID. IDs are taken before the transaction commits,
+	// so a lower ID can appear after a higher one, and the IDs of rolled back transactions never appear.
+	gapTimeout = 10 * time.Second
+)
+
+// ErrStreamBehind closes a stream that can not keep up. The client can resume with the ID of the last event.
+var ErrStreamBehind = errors.New("the stream fell too far behind, resume with the ID of the last event")
+
+// GetStreamPollInterval returns how often the outbox is read for the streams, e.g. STREAM_POLL_INTERVAL=500ms
+func GetStreamPollInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("STREAM_POLL_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultStreamPollInterval
+}
+
+// subscription is an open stream with its filters
+type subscription struct {
+	filters EventFilters
+	events  chan schemas.OutboxEvent
+}
+
+var (
+	subscriptionsMutex sync.Mutex
+	subscriptions      = map[*subscription]struct{}{}
+)
+
+func subscribe(filters EventFilters) *subscription {
+	subscriptionsMutex.Lock()
+	defer subscriptionsMutex.Unlock()
+
+	sub := &subscription{filters: filters, events: make(chan schemas.OutboxEvent, streamBufferSize)}
+	subscriptions[sub] = struct{}{}
+	return sub
+}
+
+func unsubscribe(sub *subscription) {
+	subscriptionsMutex.Lock()
+	defer subscriptionsMutex.Unlock()
+
+	if _, exists := subscriptions[sub]; exists {
+		delete(subscriptions, sub)
+		close(sub.events)
+	}
+}
+
+// broadcast sends the event to the streams it matches. A stream with a full buffer is closed,
+// so a slow client never holds up the others.
+func broadcast(outboxEvent schemas.OutboxEvent) {
+	subscriptionsMutex.Lock()
+	defer subscriptionsMutex.Unlock()
+
+	for sub := range subscriptions {
+		if !sub.filters.Matches(outboxEvent) {
+			continue
+		}
+
+		select {
+		case sub.events <- outboxEvent:
+		default:
+			delete(subscriptions, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// tailer reads the new events of the outbox. cursor is the ID up to which every event was broadcast,
+// and seen holds the broadcast events above it.
+type tailer struct {
+	cursor       int64
+	seen         map[int64]struct{}
+	waitingSince time.Time
+}
+
+// poll broadcasts the events recorded since the last poll
+func (t *tailer) poll() error {
+	outboxEvents, err := getOutboxEvents(EventFilters{After: t.cursor}, streamBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, outboxEvent := range outboxEvents {
+		if _, seen := t.seen[outboxEvent.Id]; seen {
+			continue
+		}
+		t.seen[outboxEvent.Id] = struct{}{}
+		broadcast(outboxEvent)
+	}
+
+	t.advance(time.Now())
+	return nil
+}
+
+// advance moves the cursor past the events without a gap before them.
+// A gap is skipped when it is not filled within gapTimeout.
+func (t *tailer) advance(now time.Time) {
+	for {
+		if _, seen := t.seen[t.cursor+1]; seen {
+			delete(t.seen, t.cursor+1)
+			t.cursor++
+			continue
+		}
+
+		if len(t.seen) == 0 {
+			t.waitingSince = time.Time{}
+			return
+		}
+
+		if t.waitingSince.IsZero() {
+			t.waitingSince = now
+			return
+		}
+		if now.Sub(t.waitingSince) < gapTimeout {
+			return
+		}
+
+		// Skip to the first event after the gap
+		first := int64(0)
+		for id := range t.seen {
+			if first == 0 || id < first {
+				first = id
+			}
+		}
+		t.cursor = first - 1
+		t.waitingSince = time.Time{}
+	}
+}
+
+// StartStreamTailer broadcasts the new events of the outbox to the open streams every interval,
+// until the context is done
+func StartStreamTailer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var t *tailer
+	for {
+		// The streams start at the latest event, older events are only sent to resumed streams
+		if t == nil {
+			latest, err := getLatestEventId()
+			if err == nil {
+				t = &tailer{cursor: latest, seen: map[int64]struct{}{}}
+			} else if utils.IsCustomError(err) {
+				slog.Error("Failed to start the event stream", "error", err.(*schemas.CustomError).Details)
+			} else {
+				slog.Error("Unexpected error when starting the event stream", "error", err)
+			}
+		}
+
+		if t != nil {
+			if err := t.poll(); err != nil {
+				if utils.IsCustomError(err) {
+					slog.Error("Failed to read events for the stream", "error", err.(*schemas.CustomError).Details)
+				} else {
+					slog.Error("Unexpected error when reading events for the stream", "error", err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamEvents sends the events that match the filters until the context is done or sending fails.
+// When lastEventId is set, the events after it are sent first. keepAlive is called when the stream is idle.
+func StreamEvents(ctx context.Context, filters EventFilters, lastEventId *int64, send func(schemas.Event) error, keepAlive func() error) error {
+	// Subscribe before resuming, so no event is missed in between
+	sub := subscribe(filters)
+	defer unsubscribe(sub)
+
+	resumed := map[int64]struct{}{}
+	if lastEventId != nil {
+		resumeFilters := filters
+		resumeFilters.After = *lastEventId
+
+		for {
+			outboxEvents, err := getOutboxEvents(resumeFilters, streamBatchSize)
+			if err != nil {
+				return err
+			}
+
+			for _, outboxEvent := range outboxEvents {
+				if err := send(ToEvent(outboxEvent)); err != nil {
+					return err
+				}
+				resumed[outboxEvent.Id] = struct{}{}
+				resumeFilters.After = outboxEvent.Id
+			}
+
+			if len(outboxEvents) < streamBatchSize {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case outboxEvent, open := <-sub.events:
+			if !open {
+				return ErrStreamBehind
+			}
+			if _, sent := resumed[outboxEvent.Id]; sent {
+				continue
+			}
+			if err := send(ToEvent(outboxEvent)); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := keepAlive(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// newTestTailer returns a tailer at cursor that has seen the given event IDs
+func newTestTailer(cursor int64, seen ...int64) *tailer {
+	t := &tailer{cursor: cursor, seen: map[int64]struct{}{}}
+	for _, id := range seen {
+		t.seen[id] = struct{}{}
+	}
+	return t
+}
+
+func TestTailerAdvance(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	// Events without a gap move the cursor past them
+	tail := newTestTailer(10, 11, 12, 13)
+	tail.advance(start)
+	if tail.cursor != 13 || len(tail.seen) != 0 || !tail.waitingSince.IsZero() {
+		t.Fatalf("without a gap: cursor = %d, seen = %v, waitingSince = %v, want 13, none and zero", tail.cursor, tail.seen, tail.waitingSince)
+	}
+
+	// A gap stops the cursor before it and starts waiting
+	tail = newTestTailer(10, 11, 13, 14)
+	tail.advance(start)
+	if tail.cursor != 11 || len(tail.seen) != 2 || !tail.waitingSince.Equal(start) {
+		t.Fatalf("at a gap: cursor = %d, seen = %v, waitingSince = %v, want 11, 13 and 14, and %v", tail.cursor, tail.seen, tail.waitingSince, start)
+	}
+
+	// The gap is kept open within the timeout
+	tail.advance(start.Add(gapTimeout - time.Millisecond))
+	if tail.cursor != 11 || !tail.waitingSince.Equal(start) {
+		t.Fatalf("within the timeout: cursor = %d, waitingSince = %v, want 11 and %v", tail.cursor, tail.waitingSince, start)
+	}
+
+	// A late event fills the gap
+	tail.seen[12] = struct{}{}
+	tail.advance(start.Add(gapTimeout - time.Millisecond))
+	if tail.cursor != 14 || len(tail.seen) != 0 || !tail.waitingSince.IsZero() {
+		t.Fatalf("after the gap is filled: cursor = %d, seen = %v, waitingSince = %v, want 14, none and zero", tail.cursor, tail.seen, tail.waitingSince)
+	}
+}
+
+func TestTailerAdvanceSkipsGap(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	// Two gaps: 11 and 12 never appear, and 15 is missing as well
+	tail := newTestTailer(10, 13, 14, 16)
+	tail.advance(start)
+	if tail.cursor != 10 {
+		t.Fatalf("cursor = %d before the timeout, want 10", tail.cursor)
+	}
+
+	// After the timeout the first gap is skipped, and the cursor waits at the next gap from now on
+	later := start.Add(gapTimeout)
+	tail.advance(later)
+	if tail.cursor != 14 {
+		t.Fatalf("cursor = %d after the timeout, want 14", tail.cursor)
+	}
+	if _, seen := tail.seen[16]; !seen || len(tail.seen) != 1 {
+		t.Fatalf("seen = %v after the timeout, want only 16", tail.seen)
+	}
+	if !tail.waitingSince.Equal(later) {
+		t.Fatalf("waitingSince = %v after the timeout, want the time of the skip %v", tail.waitingSince, later)
+	}
+
+	tail.advance(later.Add(gapTimeout))
+	if tail.cursor != 16 || len(tail.seen) != 0 || !tail.waitingSince.IsZero() {
+		t.Fatalf("after the second timeout: cursor = %d, seen = %v, waitingSince = %v, want 16, none and zero", tail.cursor, tail.seen, tail.waitingSince)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	itemEvents := subscribe(EventFilters{EntityType: "item"})
+	defer unsubscribe(itemEvents)
+	slow := subscribe(EventFilters{})
+	defer unsubscribe(slow)
+
+	// Fill the buffer of the slow stream, so the next event closes it
+	for i := range streamBufferSize {
+		slow.events <- schemas.OutboxEvent{Id: int64(i)}
+	}
+
+	broadcast(schemas.OutboxEvent{Id: 1000, EntityType: "supplier"})
+	broadcast(schemas.OutboxEvent{Id: 1001, EntityType: "item"})
+
+	select {
+	case outboxEvent := <-itemEvents.events:
+		if outboxEvent.Id != 1001 {
+			t.Fatalf("item stream received event %d, want 1001", outboxEvent.Id)
+		}
+	default:
+		t.Fatal("item stream received no event")
+	}
+	if len(itemEvents.events) != 0 {
+		t.Fatalf("item stream has %d more events, want none", len(itemEvents.events))
+	}
+
+	for range streamBufferSize {
+		<-slow.events
+	}
+	if _, open := <-slow.events; open {
+		t.Fatal("the stream with a full buffer was not closed")
+	}
+}
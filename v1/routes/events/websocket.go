@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"golang.org/x/net/websocket"
+)
+
+// serveWebSocket streams the events to the WebSocket, as one JSON text message per event, until it is closed
+func serveWebSocket(connection *websocket.Conn, filters EventFilters, lastEventId *int64) {
+	defer connection.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The client only sends to close the stream, so the stream ends when reading fails
+	go func() {
+		defer cancel()
+		var message []byte
+		for websocket.Message.Receive(connection, &message) == nil {
+		}
+	}()
+
+	send := func(event schemas.Event) error {
+		return websocket.JSON.Send(connection, event)
+	}
+
+	// Pings are answered by the client itself and keep proxies from closing the idle connection
+	keepAlive := func() error {
+		connection.PayloadType = websocket.PingFrame
+		defer func() { connection.PayloadType = websocket.TextFrame }()
+
+		_, err := connection.Write(nil)
+		return err
+	}
+
+	err := StreamEvents(ctx, filters, lastEventId, send, keepAlive)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			slog.Error("Event stream failed", "error", err.(*schemas.CustomError).Details)
+		} else {
+			slog.Warn("Event stream closed", "error", err)
+		}
+	}
+}
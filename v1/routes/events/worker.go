@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+const (
+	// DefaultDispatchInterval is used when OUTBOX_DISPATCH_INTERVAL is not set
+	DefaultDispatchInterval = time.Second
+	// retryBase is the delay before an event is dispatched again to the sinks that failed
+	retryBase = 5 * time.Second
+	// maxRetryDelay caps the exponential backoff. Events are retried until every sink received them.
+	maxRetryDelay = 5 * time.Minute
+	// dispatchBatchSize is the number of events claimed at a time
+	dispatchBatchSize = 100
+	// dispatchLease is how long a claimed event is left alone by other dispatchers
+	dispatchLease = 2 * time.Minute
+)
+
+// GetDispatchInterval returns how often the outbox is dispatched, e.g. OUTBOX_DISPATCH_INTERVAL=500ms
+func GetDispatchInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("OUTBOX_DISPATCH_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultDispatchInterval
+}
+
+// RetryDelay returns the delay after the given number of failed dispatches: 5s, 10s, 20s and so on
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// dispatchEvent delivers the event to the sinks that did not receive it yet.
+// It returns the sinks that received the event so far, and the errors of the sinks that failed.
+func dispatchEvent(outboxEvent schemas.OutboxEvent, sinks []Sink) ([]string, error) {
+	event := ToEvent(outboxEvent)
+	deliveredTo := append([]string{}, outboxEvent.DeliveredTo...)
+
+	var sinkErrors []error
+	for _, sink := range sinks {
+		if slices.Contains(deliveredTo, sink.Name()) {
+			continue
+		}
+
+		if err := sink.Deliver(event); err != nil {
+			if utils.IsCustomError(err) {
+				err = errors.New(err.(*schemas.CustomError).Details)
+			}
+			sinkErrors = append(sinkErrors, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+
+		deliveredTo = append(deliveredTo, sink.Name())
+	}
+
+	return deliveredTo, errors.Join(sinkErrors...)
+}
+
+// DispatchDue delivers the due events of the outbox to the registered sinks and returns how many were dispatched
+func DispatchDue() (int, error) {
+	claimed, err := claimEvents()
+	if err != nil {
+		return 0, err
+	}
+
+	sinks := registeredSinks()
+
+	dispatched := 0
+	for _, outboxEvent := range claimed {
+		deliveredTo, dispatchErr := dispatchEvent(outboxEvent, sinks)
+		if dispatchErr == nil {
+			dispatched++
+		} else {
+			slog.Warn("Event dispatch failed", "event_id", outboxEvent.Id, "type", outboxEvent.Type, "attempt", outboxEvent.Attempts+1, "error", dispatchErr)
+		}
+
+		if err := recordDispatch(outboxEvent, deliveredTo, dispatchErr); err != nil {
+			return dispatched, err
+		}
+	}
+
+	return dispatched, nil
+}
+
+// StartDispatcher delivers the events of the outbox every interval, until the context is done
+func StartDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		dispatched, err := DispatchDue()
+		if err != nil {
+			if utils.IsCustomError(err) {
+				slog.Error("Failed to dispatch events", "error", err.(*schemas.CustomError).Details)
+			} else {
+				slog.Error("Unexpected error when dispatching events", "error", err)
+			}
+		} else if dispatched > 0 {
+			slog.Info("Dispatched events", "count", dispatched)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
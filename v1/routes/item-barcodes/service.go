@@ -0,0 +1,229 @@
+package itembarcodes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+func GetItemBarcodes(itemId int8) ([]schemas.ItemBarcode, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", itemId)
+
+	data, _, err := client.
+		From("item_barcodes").
+		Select("*", "", false).
+		Eq("item_id", idStr).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the item barcodes"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				return []schemas.ItemBarcode{}, nil
+			}
+		}
+
+		return []schemas.ItemBarcode{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving barcodes for item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var barcodes []schemas.ItemBarcode
+	err = json.Unmarshal(data, &barcodes)
+	if err != nil {
+		return []schemas.ItemBarcode{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item barcode data",
+			Details: fmt.Sprintf("Error parsing barcode data for item ID %d: %v", itemId, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if barcodes == nil {
+		barcodes = []schemas.ItemBarcode{}
+	}
+
+	return barcodes, nil
+}
+
+// CreateItemBarcode validates and adds a barcode to the item.
+// If no symbology is given it is detected from the code.
+func CreateItemBarcode(itemId int8, code string, symbology string) (schemas.ItemBarcode, error) {
+	client := db.Connect()
+
+	code = utils.NormalizeBarcode(code)
+	if symbology == "" {
+		symbology = utils.DetectSymbology(code)
+	}
+
+	if err := utils.ValidateBarcode(code, symbology); err != nil {
+		return schemas.ItemBarcode{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Details: fmt.Sprintf("Invalid %s barcode %q for item %d: %v", symbology, code, itemId, err),
+		}
+	}
+
+	data, _, err := client.
+		From("item_barcodes").
+		Insert(map[string]interface{}{
+			"item_id":    itemId,
+			"code":       code,
+			"symbology":  symbology,
+			"created_at": utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while adding the barcode"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = "The barcode is already assigned to an item"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Item not found"
+			}
+		}
+
+		return schemas.ItemBarcode{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error adding barcode to item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var barcode schemas.ItemBarcode
+	err = json.Unmarshal(data, &barcode)
+	if err != nil {
+		return schemas.ItemBarcode{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item barcode data",
+			Details: fmt.Sprintf("Error parsing barcode data for item ID %d: %v", itemId, err),
+		}
+	}
+
+	return barcode, nil
+}
+
+func DeleteItemBarcode(itemId int8, barcodeId int64) error {
+	client := db.Connect()
+
+	_, _, err := client.
+		From("item_barcodes").
+		Delete("", "").
+		Eq("id", fmt.Sprintf("%d", barcodeId)).
+		Eq("item_id", fmt.Sprintf("%d", itemId)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the barcode"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Barcode not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting barcode %d of item with ID %d: %v", barcodeId, itemId, err),
+		}
+	}
+
+	return nil
+}
+
+// barcodeCandidates returns the codes a scanned barcode can be stored as.
+// Scanners often read UPC-A codes as EAN-13 with a leading zero, and the other way around.
+func barcodeCandidates(code string) []string {
+	candidates := []string{code}
+	if utils.DetectSymbology(code) == schemas.SymbologyUPCA {
+		candidates = append(candidates, "0"+code)
+	} else if utils.DetectSymbology(code) == schemas.SymbologyEAN13 && code[0] == '0' {
+		candidates = append(candidates, code[1:])
+	}
+	return candidates
+}
+
+// FindItemIdByBarcode returns the id of the item with the scanned barcode
+func FindItemIdByBarcode(barcode string) (int8, error) {
+	client := db.Connect()
+	barcode = utils.NormalizeBarcode(barcode)
+
+	data, _, err := client.
+		From("item_barcodes").
+		Select("item_id", "", false).
+		In("code", barcodeCandidates(barcode)).
+		Limit(1, "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while looking up the barcode"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return 0, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error looking up barcode %q: %v", barcode, err),
+		}
+	}
+
+	var matches []schemas.ItemBarcode
+	err = json.Unmarshal(data, &matches)
+	if err != nil {
+		return 0, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item barcode data",
+			Details: fmt.Sprintf("Error parsing barcode lookup data for %q: %v", barcode, err),
+		}
+	}
+
+	if len(matches) == 0 {
+		return 0, &schemas.CustomError{
+			Code:    http.StatusNotFound,
+			Message: "No item found for barcode",
+			Details: fmt.Sprintf("Barcode %q is not assigned to any item", barcode),
+		}
+	}
+
+	return matches[0].ItemId, nil
+}
@@ -1,17 +1,22 @@
 package items
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
+	itembarcodes "github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/item-barcodes"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
 	"github.com/gin-gonic/gin"
 )
 
-// protectedFields contains fields that the user should not be able to modify
-var protectedFields = []string{"id", "created_at", "updated_at", "deleted_at"}
+// ProtectedFields contains fields that the user should not be able to modify.
+// The quantity is changed through stock movements, so every change is in the ledger.
+// The category is the name of category_id, which is set by the database.
+var ProtectedFields = []string{"id", "quantity", "category", "purchase_unit_cost", "created_at", "updated_at", "deleted_at", "barcodes", "image_url", "reserved_quantity", "available_quantity"}
 
 func GetItemHandler(context *gin.Context) {
 	id, err := utils.GetIdFromContext(context)
@@ -72,7 +77,7 @@ func UpdateItemHandler(context *gin.Context) {
 		return
 	}
 
-	utils.RemoveProtectedFields(updates, protectedFields)
+	utils.RemoveProtectedFields(updates, ProtectedFields)
 
 	item, err := UpdateItem(id, updates)
 	if err != nil {
@@ -112,7 +117,7 @@ func CreateItemHandler(context *gin.Context) {
 		return
 	}
 
-	err := utils.CheckRequiredFields(itemData, []string{"name", "description", "quantity", "price", "supplier_id", "category"})
+	err := utils.CheckRequiredFields(itemData, []string{"name", "description", "quantity", "supplier_id"})
 	if err != nil {
 		slog.Error("Missing required fields in item data", "error", err)
 		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
@@ -123,12 +128,93 @@ func CreateItemHandler(context *gin.Context) {
 	}
 
 	newItem := schemas.Item{
-		Name:          itemData["name"].(string),
-		Description:   itemData["description"].(string),
-		Quantity:      int8(itemData["quantity"].(float64)),
-		PurchasePrice: itemData["purchase_price"].(float64),
-		SupplierId:    int8(itemData["supplier_id"].(float64)),
-		Category:      itemData["category"].(string),
+		Name:        itemData["name"].(string),
+		Description: itemData["description"].(string),
+		Quantity:    int(itemData["quantity"].(float64)),
+		SupplierId:  int8(itemData["supplier_id"].(float64)),
+	}
+
+	// The purchase price can be given as a decimal or in minor units of the purchase currency
+	if purchasePriceMinor, exists := itemData["purchase_price_minor"].(float64); exists {
+		if purchasePriceMinor < 0 || purchasePriceMinor != float64(int64(purchasePriceMinor)) {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "purchase_price_minor must be a whole number of at least 0",
+			})
+			return
+		}
+		minor := int64(purchasePriceMinor)
+		newItem.PurchasePriceMinor = &minor
+	} else if purchasePrice, exists := itemData["purchase_price"].(float64); exists {
+		newItem.PurchasePrice = purchasePrice
+	} else {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Missing required field: purchase_price or purchase_price_minor",
+		})
+		return
+	}
+
+	// Without a purchase currency the database default (EUR) is used
+	if purchaseCurrency, exists := itemData["purchase_currency"].(string); exists {
+		newItem.PurchaseCurrency = strings.ToUpper(purchaseCurrency)
+	}
+
+	// The category is optional, and its name is set by the database from the category id
+	if value, exists := itemData["category_id"]; exists && value != nil {
+		categoryId, ok := value.(float64)
+		if !ok || categoryId != float64(int8(categoryId)) {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "category_id must be the ID of a category",
+			})
+			return
+		}
+		id := int8(categoryId)
+		newItem.CategoryId = &id
+	}
+
+	if sku, exists := itemData["sku"].(string); exists {
+		newItem.Sku = &sku
+	}
+
+	if location, exists := itemData["location"].(string); exists && strings.TrimSpace(location) != "" {
+		location = strings.TrimSpace(location)
+		newItem.Location = &location
+	}
+
+	// Without a base unit the database default (pcs) is used
+	if baseUnit, exists := itemData["base_unit"].(string); exists {
+		newItem.BaseUnit = baseUnit
+	}
+
+	if serialized, exists := itemData["serialized"].(bool); exists {
+		newItem.Serialized = serialized
+	}
+
+	// Tags are validated like on updates, instead of dropping the tags that are not strings
+	if value, exists := itemData["tags"]; exists && value != nil {
+		tags, ok := value.([]interface{})
+		for _, tag := range tags {
+			tagStr, isString := tag.(string)
+			if !isString {
+				ok = false
+				break
+			}
+			newItem.Tags = append(newItem.Tags, tagStr)
+		}
+
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Tags must be an array of strings",
+			})
+			return
+		}
+	}
+
+	if attributes, exists := itemData["attributes"].(map[string]interface{}); exists {
+		newItem.Attributes = attributes
 	}
 
 	item, err := CreateItem(newItem)
@@ -196,6 +282,12 @@ func DeleteItemHandler(context *gin.Context) {
 }
 
 func GetPagedItemsHandler(context *gin.Context) {
+	// Without a page number we use cursor pagination
+	if _, hasPage := context.GetQuery("page"); !hasPage {
+		GetCursorItemsHandler(context)
+		return
+	}
+
 	pageStr := context.Query("page")
 	pageSizeStr := context.Query("page-size")
 
@@ -217,7 +309,16 @@ func GetPagedItemsHandler(context *gin.Context) {
 		return
 	}
 
-	items, count, err := GetPagedItems(page, pageSize)
+	filters, err := getListFiltersFromContext(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items, count, err := GetPagedItems(page, pageSize, filters)
 	if err != nil {
 		if utils.IsCustomError(err) {
 			customErr := err.(*schemas.CustomError)
@@ -249,10 +350,77 @@ func GetPagedItemsHandler(context *gin.Context) {
 	})
 }
 
+func GetCursorItemsHandler(context *gin.Context) {
+	cursorStr := context.Query("cursor")
+	limitStr := context.DefaultQuery("limit", "20")
+	sortBy := context.DefaultQuery("sort-by", "name")
+	sortOrder := context.DefaultQuery("sort-order", "asc")
+	includeCount := context.Query("include-count") == "true"
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || !utils.InRange(limit, 1, 100) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid limit, must be between 1 and 100",
+		})
+		return
+	}
+
+	if sortOrder != "asc" && sortOrder != "desc" {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid sort order, must be asc or desc",
+		})
+		return
+	}
+
+	filters, err := getListFiltersFromContext(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items, page, err := GetCursorItems(cursorStr, limit, sortBy, sortOrder == "asc", includeCount, filters)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve cursor items", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to retrieve cursor items", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve items",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Items retrieved successfully",
+		Data: map[string]interface{}{
+			"count":      page.Count,
+			"limit":      page.Limit,
+			"nextCursor": page.NextCursor,
+			"prevCursor": page.PrevCursor,
+			"data":       items,
+		},
+	})
+}
+
 func GetPagedItemSearchHandler(context *gin.Context) {
 	pageStr := context.Query("page")
 	pageSizeStr := context.Query("page-size")
-	nameStr := context.Query("name")
+	// "name" is kept for clients using the old prefix search
+	searchQuery := context.DefaultQuery("q", context.Query("name"))
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
@@ -272,7 +440,16 @@ func GetPagedItemSearchHandler(context *gin.Context) {
 		return
 	}
 
-	items, count, err := PagedItemSearch(nameStr, page, pageSize)
+	filters, err := getSearchFiltersFromContext(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items, count, err := PagedItemSearch(searchQuery, filters, page, pageSize)
 	if err != nil {
 		if utils.IsCustomError(err) {
 			customErr := err.(*schemas.CustomError)
@@ -292,14 +469,457 @@ func GetPagedItemSearchHandler(context *gin.Context) {
 		return
 	}
 
+	data := map[string]interface{}{
+		"count":    count,
+		"page":     page,
+		"pageSize": pageSize,
+		"data":     items,
+	}
+
+	if context.Query("facets") == "true" {
+		facets, err := GetItemSearchFacets(searchQuery, filters)
+		if err != nil {
+			if utils.IsCustomError(err) {
+				customErr := err.(*schemas.CustomError)
+				slog.Error("Failed to retrieve item search facets", "error", customErr.Details)
+				context.JSON(customErr.Code, schemas.ApiResponse{
+					Success: false,
+					Message: customErr.Message,
+				})
+				return
+			}
+
+			slog.Error("Failed to retrieve item search facets", "error", err)
+			context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+				Success: false,
+				Message: "Failed to retrieve search facets",
+			})
+			return
+		}
+		data["facets"] = facets
+	}
+
 	context.JSON(http.StatusOK, schemas.ApiResponse{
 		Success: true,
 		Message: "Paged items retrieved successfully from search",
-		Data: map[string]interface{}{
-			"count":    count,
-			"page":     page,
-			"pageSize": pageSize,
-			"data":     items,
-		},
+		Data:    data,
+	})
+}
+
+// stockStatuses contains the valid values of the stock-status search filter
+var stockStatuses = map[string]struct{}{
+	"in_stock":     {},
+	"low_stock":    {},
+	"out_of_stock": {},
+}
+
+// getListFiltersFromContext returns the item listing filters from the query parameters.
+// Attributes are filtered with attr.<key>=<value> and tags with one or more tag parameters.
+func getListFiltersFromContext(context *gin.Context) (schemas.ItemListFilters, error) {
+	filters := schemas.ItemListFilters{
+		Tags:       context.QueryArray("tag"),
+		Attributes: map[string]string{},
+	}
+
+	if categoryIdStr, exists := context.GetQuery("category-id"); exists {
+		categoryId, err := strconv.ParseInt(categoryIdStr, 10, 8)
+		if err != nil {
+			return filters, fmt.Errorf("invalid category-id: %s", categoryIdStr)
+		}
+		id := int8(categoryId)
+		filters.CategoryId = &id
+	}
+
+	for param, values := range context.Request.URL.Query() {
+		if key, found := strings.CutPrefix(param, "attr."); found && len(values) > 0 {
+			filters.Attributes[key] = values[0]
+		}
+	}
+
+	return filters, nil
+}
+
+func getSearchFiltersFromContext(context *gin.Context) (schemas.ItemSearchFilters, error) {
+	var filters schemas.ItemSearchFilters
+
+	if category, exists := context.GetQuery("category"); exists {
+		filters.Category = &category
+	}
+
+	if categoryIdStr, exists := context.GetQuery("category-id"); exists {
+		categoryId, err := strconv.ParseInt(categoryIdStr, 10, 8)
+		if err != nil {
+			return filters, fmt.Errorf("invalid category-id: %s", categoryIdStr)
+		}
+		id := int8(categoryId)
+		filters.CategoryId = &id
+	}
+
+	if supplierIdStr, exists := context.GetQuery("supplier-id"); exists {
+		supplierId, err := strconv.ParseInt(supplierIdStr, 10, 8)
+		if err != nil {
+			return filters, fmt.Errorf("invalid supplier-id: %s", supplierIdStr)
+		}
+		id := int8(supplierId)
+		filters.SupplierId = &id
+	}
+
+	if minPriceStr, exists := context.GetQuery("min-price"); exists {
+		minPrice, err := strconv.ParseFloat(minPriceStr, 64)
+		if err != nil {
+			return filters, fmt.Errorf("invalid min-price: %s", minPriceStr)
+		}
+		filters.MinPrice = &minPrice
+	}
+
+	if maxPriceStr, exists := context.GetQuery("max-price"); exists {
+		maxPrice, err := strconv.ParseFloat(maxPriceStr, 64)
+		if err != nil {
+			return filters, fmt.Errorf("invalid max-price: %s", maxPriceStr)
+		}
+		filters.MaxPrice = &maxPrice
+	}
+
+	if stockStatus, exists := context.GetQuery("stock-status"); exists {
+		if _, valid := stockStatuses[stockStatus]; !valid {
+			return filters, fmt.Errorf("invalid stock-status: %s", stockStatus)
+		}
+		filters.StockStatus = &stockStatus
+	}
+
+	return filters, nil
+}
+
+func GetItemByBarcodeHandler(context *gin.Context) {
+	code := context.Param("code")
+
+	item, err := GetItemByBarcode(code)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve item by barcode", "code", code, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving item by barcode", "code", code, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve item",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Item retrieved successfully",
+		Data:    item,
+	})
+}
+
+func GetItemBarcodesHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	barcodes, err := itembarcodes.GetItemBarcodes(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve item barcodes", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving item barcodes", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve item barcodes",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Item barcodes retrieved successfully",
+		Data:    barcodes,
+	})
+}
+
+func CreateItemBarcodeHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var body struct {
+		Code      string `json:"code"`
+		Symbology string `json:"symbology"`
+	}
+	if err := context.ShouldBindJSON(&body); err != nil || body.Code == "" {
+		slog.Error("Failed to parse JSON of new item barcode", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Body must contain a code.",
+		})
+		return
+	}
+
+	barcode, err := itembarcodes.CreateItemBarcode(id, body.Code, body.Symbology)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to add item barcode", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to add item barcode", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to add item barcode",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Item barcode added successfully",
+		Data:    barcode,
+	})
+}
+
+func DeleteItemBarcodeHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	barcodeIdStr := context.Param("barcodeId")
+	barcodeId, err := strconv.ParseInt(barcodeIdStr, 10, 64)
+	if err != nil {
+		slog.Error("Failed to get barcode ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid barcode ID",
+		})
+		return
+	}
+
+	err = itembarcodes.DeleteItemBarcode(id, barcodeId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete item barcode", "id", id, "barcode_id", barcodeId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to delete item barcode", "id", id, "barcode_id", barcodeId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete item barcode",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Item barcode deleted successfully",
+	})
+}
+
+func GetItemStockMovementsHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", "50"))
+	if err != nil || !utils.InRange(limit, 1, 500) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid limit, must be between 1 and 500",
+		})
+		return
+	}
+
+	movements, err := GetItemStockMovements(id, limit)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve item stock movements", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to retrieve item stock movements", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve item stock movements",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Item stock movements retrieved successfully",
+		Data:    movements,
+	})
+}
+
+// manualMovementTypes are the stock movement types that can be recorded directly on an item.
+// Receipts add stock, issues remove stock and adjustments can do both.
+var manualMovementTypes = map[string]int{
+	schemas.StockMovementReceipt:    1,
+	schemas.StockMovementIssue:      -1,
+	schemas.StockMovementAdjustment: 0,
+}
+
+func CreateItemStockMovementHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var movementData map[string]interface{}
+	if err := context.ShouldBindJSON(&movementData); err != nil {
+		slog.Error("Failed to parse JSON of new stock movement", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err = utils.CheckRequiredFields(movementData, []string{"type", "quantity"})
+	if err != nil {
+		slog.Error("Missing required fields in stock movement data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	movementType, _ := movementData["type"].(string)
+	sign, valid := manualMovementTypes[movementType]
+	if !valid {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid type, expected receipt, issue or adjustment",
+		})
+		return
+	}
+
+	quantity, ok := movementData["quantity"].(float64)
+	if !ok || quantity == 0 || (sign != 0 && quantity < 0) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid quantity",
+		})
+		return
+	}
+	if sign != 0 {
+		quantity *= float64(sign)
+	}
+
+	// The quantity is given in the unit of the movement, which defaults to the base unit of the item
+	movement := schemas.StockMovement{
+		ItemId:       id,
+		Type:         movementType,
+		UnitQuantity: &quantity,
+	}
+
+	if unit, exists := movementData["unit"].(string); exists {
+		movement.Unit = &unit
+	}
+
+	if unitCost, exists := movementData["unit_cost"].(float64); exists {
+		movement.UnitCost = &unitCost
+	}
+
+	if value, exists := movementData["lot_id"]; exists && value != nil {
+		lotId, ok := utils.ParseJSONId(value)
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "lot_id must be the ID of a lot",
+			})
+			return
+		}
+		movement.LotId = &lotId
+	}
+
+	if note, exists := movementData["note"].(string); exists {
+		movement.Note = &note
+	}
+
+	movements, err := ApplyStockMovements([]schemas.StockMovement{movement})
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to record stock movement", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to record stock movement", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to record stock movement",
+		})
+		return
+	}
+
+	// A movement that is allocated over the lots of the item is recorded per lot
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Stock movement recorded successfully",
+		Data:    movements,
 	})
 }
@@ -118,6 +118,7 @@ func QueryItems(queryParams map[string][]string) ([]schemas.Item, *int64, error)
 		message := "An error occurred while querying items"
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = "No items found matching query"
@@ -8,8 +8,15 @@ func SetupItemRoutes(routes *gin.RouterGroup) {
 	routes.GET("", GetPagedItemsHandler)
 	routes.GET("/:id", GetItemHandler)
 	routes.GET("/search", GetPagedItemSearchHandler)
+	routes.GET("/by-barcode/:code", GetItemByBarcodeHandler)
+	routes.GET("/:id/barcodes", GetItemBarcodesHandler)
+	routes.GET("/:id/movements", GetItemStockMovementsHandler)
 
 	routes.PATCH("/:id", UpdateItemHandler)
 	routes.POST("/", CreateItemHandler)
 	routes.DELETE("/:id", DeleteItemHandler)
+
+	routes.POST("/:id/barcodes", CreateItemBarcodeHandler)
+	routes.POST("/:id/movements", CreateItemStockMovementHandler)
+	routes.DELETE("/:id/barcodes/:barcodeId", DeleteItemBarcodeHandler)
 }
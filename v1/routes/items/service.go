@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/attributes"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/categories"
+	itembarcodes "github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/item-barcodes"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
 	"github.com/supabase-community/postgrest-go"
@@ -34,6 +39,7 @@ func GetItem(id int8) (schemas.Item, error) {
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = "Item not found"
@@ -59,13 +65,256 @@ func GetItem(id int8) (schemas.Item, error) {
 
 	item.ImageUrl = GetItemImage(item.Id)
 
+	barcodes, err := itembarcodes.GetItemBarcodes(item.Id)
+	if err != nil {
+		return schemas.Item{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get barcodes for item",
+			Details: fmt.Sprintf("Failed to get barcodes for item ID %d: %v", id, err),
+		}
+	}
+	item.Barcodes = barcodes
+
 	return item, nil
 }
 
+// GetItemByBarcode returns the item with the scanned barcode.
+// If no barcode matches, the code is looked up as a SKU.
+func GetItemByBarcode(code string) (schemas.Item, error) {
+	id, err := itembarcodes.FindItemIdByBarcode(code)
+	if err == nil {
+		return GetItem(id)
+	}
+
+	if customErr, ok := err.(*schemas.CustomError); !ok || customErr.Code != http.StatusNotFound {
+		return schemas.Item{}, err
+	}
+
+	client := db.Connect()
+
+	data, _, err := client.
+		From("items").
+		Select("*", "", false).
+		Eq("sku", strings.TrimSpace(code)).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while looking up the barcode"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "No item found for barcode"
+			}
+		}
+
+		return schemas.Item{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error looking up item by barcode or SKU: %v", err),
+		}
+	}
+
+	var item schemas.Item
+	err = json.Unmarshal(data, &item)
+	if err != nil {
+		return schemas.Item{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item data",
+			Details: fmt.Sprintf("Error parsing item data found by SKU: %v", err),
+		}
+	}
+
+	return GetItem(item.Id)
+}
+
+// NormalizeTags trims and lowercases the tags and removes empty and duplicate tags
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// prepareTagsUpdate converts the tags of an update body to normalized tags
+func prepareTagsUpdate(id int8, value interface{}) ([]string, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Tags must be an array of strings",
+			Details: fmt.Sprintf("Invalid tags for item %d: %v", id, value),
+		}
+	}
+
+	tags := make([]string, len(values))
+	for i, tagValue := range values {
+		tag, ok := tagValue.(string)
+		if !ok {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Tags must be an array of strings",
+				Details: fmt.Sprintf("Invalid tag for item %d: %v", id, tagValue),
+			}
+		}
+		tags[i] = tag
+	}
+
+	return NormalizeTags(tags), nil
+}
+
+// prepareAttributesUpdate merges the attributes of an update body into the current
+// attributes of the item and validates the result. A null value removes the attribute.
+func prepareAttributesUpdate(id int8, value interface{}) (map[string]interface{}, error) {
+	changes, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Attributes must be an object",
+			Details: fmt.Sprintf("Invalid attributes for item %d: %v", id, value),
+		}
+	}
+
+	item, err := GetItem(id)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]interface{}{}
+	for key, attributeValue := range item.Attributes {
+		merged[key] = attributeValue
+	}
+	for key, attributeValue := range changes {
+		if attributeValue == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = attributeValue
+		}
+	}
+
+	if err := attributes.ValidateAttributes(merged); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// itemHasLots tells if the item has any lots, including empty ones
+func itemHasLots(id int8) (bool, error) {
+	client := db.Connect()
+
+	_, count, err := client.
+		From("item_lots").
+		Select("", "exact", true).
+		Eq("item_id", fmt.Sprintf("%d", id)).
+		Execute()
+
+	if err != nil {
+		return false, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "An error occurred while updating the item",
+			Details: fmt.Sprintf("Error counting the lots of item %d: %v", id, err),
+		}
+	}
+
+	return count > 0, nil
+}
+
 func UpdateItem(id int8, updates map[string]interface{}) (schemas.Item, error) {
 	client := db.Connect()
 	idStr := fmt.Sprintf("%d", id)
 
+	if sku, exists := updates["sku"]; exists && sku != nil {
+		skuStr, ok := sku.(string)
+		if !ok {
+			return schemas.Item{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid sku",
+				Details: fmt.Sprintf("Expected a string for sku of item %d, got %v", id, sku),
+			}
+		}
+
+		skuStr = strings.TrimSpace(skuStr)
+		if err := utils.ValidateSku(skuStr); err != nil {
+			return schemas.Item{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+				Details: fmt.Sprintf("Invalid sku %q for item %d: %v", skuStr, id, err),
+			}
+		}
+		updates["sku"] = skuStr
+	}
+
+	_, updatesQuantity := updates["quantity"]
+	_, updatesBaseUnit := updates["base_unit"]
+	if updatesQuantity || updatesBaseUnit {
+		current, err := GetItem(id)
+		if err != nil {
+			return schemas.Item{}, err
+		}
+
+		// The quantity of serialised items is derived from their serials
+		if updatesQuantity && current.Serialized {
+			return schemas.Item{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "The quantity of a serialised item is derived from its serials",
+				Details: fmt.Sprintf("Attempted to set the quantity of serialised item %d", id),
+			}
+		}
+
+		// The quantity of items with lots is the sum of their lots
+		if updatesQuantity {
+			hasLots, err := itemHasLots(id)
+			if err != nil {
+				return schemas.Item{}, err
+			}
+			if hasLots {
+				return schemas.Item{}, &schemas.CustomError{
+					Code:    http.StatusBadRequest,
+					Message: "The quantity of an item with lots is changed through its lots",
+					Details: fmt.Sprintf("Attempted to set the quantity of item %d, which has lots", id),
+				}
+			}
+		}
+
+		// Stock and unit conversions are kept in the base unit, so it can only change while there is no stock
+		if updatesBaseUnit && updates["base_unit"] != current.BaseUnit && current.Quantity != 0 {
+			return schemas.Item{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "The base unit can only be changed while the item has no stock",
+				Details: fmt.Sprintf("Attempted to change the base unit of item %d with quantity %d", id, current.Quantity),
+			}
+		}
+	}
+
+	if tags, exists := updates["tags"]; exists {
+		normalized, err := prepareTagsUpdate(id, tags)
+		if err != nil {
+			return schemas.Item{}, err
+		}
+		updates["tags"] = normalized
+	}
+
+	if attributeValues, exists := updates["attributes"]; exists {
+		merged, err := prepareAttributesUpdate(id, attributeValues)
+		if err != nil {
+			return schemas.Item{}, err
+		}
+		updates["attributes"] = merged
+	}
+
 	// Add updated_at field
 	updates["updated_at"] = utils.GetCurrentISODate()
 
@@ -85,6 +334,7 @@ func UpdateItem(id int8, updates map[string]interface{}) (schemas.Item, error) {
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = "Item not found"
@@ -117,6 +367,28 @@ func UpdateItem(id int8, updates map[string]interface{}) (schemas.Item, error) {
 func CreateItem(item schemas.Item) (schemas.Item, error) {
 	client := db.Connect()
 
+	if item.Sku != nil {
+		sku := strings.TrimSpace(*item.Sku)
+		if err := utils.ValidateSku(sku); err != nil {
+			return schemas.Item{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+				Details: fmt.Sprintf("Invalid sku %q for new item: %v", sku, err),
+			}
+		}
+		item.Sku = &sku
+	}
+
+	item.Tags = NormalizeTags(item.Tags)
+
+	if item.Attributes == nil {
+		item.Attributes = map[string]interface{}{}
+	}
+
+	if err := attributes.ValidateAttributes(item.Attributes); err != nil {
+		return schemas.Item{}, err
+	}
+
 	item.CreatedAt = utils.GetCurrentISODate()
 	item.UpdatedAt = utils.GetCurrentISODate()
 
@@ -135,9 +407,12 @@ func CreateItem(item schemas.Item) (schemas.Item, error) {
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = "Item not found"
+			} else if code == http.StatusConflict {
+				message = "An item with this SKU already exists"
 			}
 		}
 
@@ -164,26 +439,31 @@ func CreateItem(item schemas.Item) (schemas.Item, error) {
 	return createdItem, nil
 }
 
+// DeleteItem soft deletes the item. Its history is kept until purge_records removes it after the retention period.
 func DeleteItem(id int8) error {
 	client := db.Connect()
 	idStr := fmt.Sprintf("%d", id)
 
 	_, _, err := client.
 		From("items").
-		Delete("", "").
+		Update(map[string]interface{}{
+			"deleted_at": utils.GetCurrentISODate(),
+		}, "", "").
 		Eq("id", idStr).
 		Is("deleted_at", "null").
+		Single().
 		Execute()
 
 	if err != nil {
 		// Set the default error code and message
 		code := http.StatusInternalServerError
-		message := "An error occurred while updating the item"
+		message := "An error occurred while deleting the item"
 
 		// Check if the error is a Postgres error
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = "Item not found"
@@ -193,21 +473,60 @@ func DeleteItem(id int8) error {
 		return &schemas.CustomError{
 			Code:    code,
 			Message: message,
-			Details: fmt.Sprintf("Error updating item with ID %d: %v", id, err),
+			Details: fmt.Sprintf("Error deleting item with ID %d: %v", id, err),
 		}
 	}
 
 	return nil
 }
 
-func GetPagedItems(page int, pageSize int) ([]schemas.Item, *int64, error) {
+// applyListFilters applies the item listing filters to the query.
+// The category filter includes the descendants of the category.
+func applyListFilters(query *postgrest.FilterBuilder, filters schemas.ItemListFilters) (*postgrest.FilterBuilder, error) {
+	if filters.CategoryId != nil {
+		ids, err := categories.GetCategoryDescendantIds(*filters.CategoryId)
+		if err != nil {
+			return nil, err
+		}
+
+		idStrs := make([]string, len(ids))
+		for i, id := range ids {
+			idStrs[i] = fmt.Sprintf("%d", id)
+		}
+		query = query.In("category_id", idStrs)
+	}
+
+	// Items must have all the given tags
+	if len(filters.Tags) > 0 {
+		query = query.Contains("tags", NormalizeTags(filters.Tags))
+	}
+
+	for key, value := range filters.Attributes {
+		if !attributes.KeyRegex.MatchString(key) {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid attribute filter: %s", key),
+				Details: fmt.Sprintf("Attribute filter key %q does not match %s", key, attributes.KeyRegex.String()),
+			}
+		}
+		query = query.Eq(fmt.Sprintf("attributes->>%s", key), value)
+	}
+
+	return query, nil
+}
+
+func GetPagedItems(page int, pageSize int, filters schemas.ItemListFilters) ([]schemas.Item, *int64, error) {
 	client := db.Connect()
 
-	_, count, err := client.
+	countQuery, err := applyListFilters(client.
 		From("items").
 		Select("", "exact", false).
-		Is("deleted_at", "null").
-		Execute()
+		Is("deleted_at", "null"), filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, count, err := countQuery.Execute()
 
 	if err != nil {
 		return nil, nil, &schemas.CustomError{
@@ -236,9 +555,16 @@ func GetPagedItems(page int, pageSize int) ([]schemas.Item, *int64, error) {
 
 	pageStartIndex := (page - 1) * pageSize
 
-	data, _, err := client.
+	query, err := applyListFilters(client.
 		From("items").
-		Select("*", "", false).Order("name", &postgrest.OrderOpts{Ascending: true}).
+		Select("*", "", false).
+		Is("deleted_at", "null"), filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, _, err := query.
+		Order("name", &postgrest.OrderOpts{Ascending: true}).
 		Range(pageStartIndex, pageEndIndex-1, "").
 		Execute()
 
@@ -251,6 +577,7 @@ func GetPagedItems(page int, pageSize int) ([]schemas.Item, *int64, error) {
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = fmt.Sprintf("No items found for page %d", page)
@@ -291,87 +618,425 @@ func GetItemImage(id int8) *string {
 	return &url
 }
 
-func PagedItemSearch(name string, page int, pageSize int) ([]schemas.Item, *int64, error) {
+// searchItemRow is a single row returned by the search_items database function
+type searchItemRow struct {
+	Item       schemas.Item      `json:"item"`
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+	TotalCount int64             `json:"total_count"`
+}
+
+// searchParams builds the parameters shared by the item search database functions.
+// Prices are filtered and bucketed in the base currency.
+func searchParams(searchQuery string, filters schemas.ItemSearchFilters) map[string]interface{} {
+	params := map[string]interface{}{
+		"search_query":   searchQuery,
+		"price_currency": utils.GetBaseCurrency(),
+	}
 
+	if filters.Category != nil {
+		params["filter_category"] = *filters.Category
+	}
+	if filters.CategoryId != nil {
+		params["filter_category_id"] = *filters.CategoryId
+	}
+	if filters.SupplierId != nil {
+		params["filter_supplier_id"] = *filters.SupplierId
+	}
+	if filters.MinPrice != nil {
+		params["filter_min_price"] = *filters.MinPrice
+	}
+	if filters.MaxPrice != nil {
+		params["filter_max_price"] = *filters.MaxPrice
+	}
+	if filters.StockStatus != nil {
+		params["filter_stock_status"] = *filters.StockStatus
+	}
+
+	return params
+}
+
+// PagedItemSearch does a ranked full-text and fuzzy search across the name, category,
+// description and notes of the items. See the search_items database function.
+func PagedItemSearch(searchQuery string, filters schemas.ItemSearchFilters, page int, pageSize int) ([]schemas.ItemSearchResult, *int64, error) {
 	client := db.Connect()
 
-	_, count, err := client.
-		From("items").
-		Select("", "exact", false).
-		Ilike("name", name+"%").
-		Is("deleted_at", "null").
-		Execute()
+	params := searchParams(searchQuery, filters)
+	params["result_limit"] = pageSize
+	params["result_offset"] = (page - 1) * pageSize
+
+	data, err := db.Rpc(client, "search_items", params)
 
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := fmt.Sprintf("An error occurred while searching items page %v", page)
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error searching items for %q on page %d: %v", searchQuery, page, err),
+		}
+	}
+
+	var rows []searchItemRow
+	err = json.Unmarshal(data, &rows)
 	if err != nil {
 		return nil, nil, &schemas.CustomError{
 			Code:    http.StatusInternalServerError,
-			Message: "Failed to retrieve items",
-			Details: fmt.Sprintf("Failed to retrieve item count: %v", err),
+			Message: "Failed to parse items data",
+			Details: fmt.Sprintf("Error parsing item search data for page %d: %v", page, err),
 		}
 	}
 
-	// If count is zero, return an empty slice to save time and resources;
-	if count == 0 {
-		return []schemas.Item{}, &count, nil
+	var count int64
+	results := make([]schemas.ItemSearchResult, len(rows))
+	for i, row := range rows {
+		count = row.TotalCount
+		row.Item.ImageUrl = GetItemImage(row.Item.Id)
+		if row.Highlights == nil {
+			row.Highlights = map[string]string{}
+		}
+
+		results[i] = schemas.ItemSearchResult{
+			Item:       row.Item,
+			Rank:       row.Rank,
+			Highlights: row.Highlights,
+		}
 	}
 
-	lastPage := (int(count) + pageSize - 1) / pageSize
-	if page > lastPage {
+	// The total count is only known when the page contains results
+	if len(rows) == 0 && page > 1 {
 		return nil, nil, &schemas.CustomError{
 			Code:    http.StatusBadRequest,
 			Message: "Page out of range",
-			Details: fmt.Sprintf("Requested page %d with page size %d is out of range for total items %d", page, pageSize, count),
+			Details: fmt.Sprintf("Requested search page %d with page size %d has no results for %q", page, pageSize, searchQuery),
 		}
 	}
 
-	// The end index must not exceed the total count of items
-	pageEndIndex := min(page*pageSize, int(count))
+	return results, &count, nil
+}
 
-	pageStartIndex := (page - 1) * pageSize
+// cursorSortColumns contains the columns items can be sorted by when using cursor pagination
+var cursorSortColumns = map[string]struct{}{
+	"id":             {},
+	"name":           {},
+	"created_at":     {},
+	"updated_at":     {},
+	"purchase_price": {},
+	"quantity":       {},
+}
 
-	data, _, err := client.
+// itemSortValue returns the value of the sort column for the item, as used in cursors
+func itemSortValue(item schemas.Item, column string) string {
+	switch column {
+	case "name":
+		return item.Name
+	case "created_at":
+		return item.CreatedAt
+	case "updated_at":
+		return item.UpdatedAt
+	case "purchase_price":
+		return strconv.FormatFloat(item.PurchasePrice, 'f', -1, 64)
+	case "quantity":
+		return strconv.Itoa(item.Quantity)
+	default:
+		return strconv.Itoa(int(item.Id))
+	}
+}
+
+func GetCursorItems(cursorStr string, limit int, sortBy string, ascending bool, includeCount bool, filters schemas.ItemListFilters) ([]schemas.Item, schemas.CursorPage, error) {
+	client := db.Connect()
+
+	cursor := utils.Cursor{SortBy: sortBy, Ascending: ascending, Direction: utils.CursorNext}
+	hasCursor := cursorStr != ""
+	if hasCursor {
+		decoded, err := utils.DecodeCursor(cursorStr)
+		if err != nil {
+			return nil, schemas.CursorPage{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid cursor",
+				Details: fmt.Sprintf("Failed to decode item cursor: %v", err),
+			}
+		}
+		// The cursor decides the sorting, so it stays stable across pages
+		cursor = decoded
+	}
+
+	if _, allowed := cursorSortColumns[cursor.SortBy]; !allowed {
+		return nil, schemas.CursorPage{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid sort field: %s", cursor.SortBy),
+			Details: fmt.Sprintf("Cursor item listing failed. Expected a valid field name for sorting, got %s", cursor.SortBy),
+		}
+	}
+
+	// When going backwards we query in the opposite order and reverse the result afterwards
+	queryAscending := cursor.Ascending
+	if cursor.Direction == utils.CursorPrev {
+		queryAscending = !queryAscending
+	}
+
+	query, err := applyListFilters(client.
 		From("items").
 		Select("*", "", false).
-		Ilike("name", name+"%").
-		Order("name", &postgrest.OrderOpts{Ascending: true}).
-		Range(pageStartIndex, pageEndIndex-1, "").
+		Is("deleted_at", "null"), filters)
+	if err != nil {
+		return nil, schemas.CursorPage{}, err
+	}
+
+	if hasCursor {
+		query = query.Or(utils.KeysetFilter(cursor.SortBy, cursor.Value, cursor.Id, queryAscending), "")
+	}
+
+	if cursor.SortBy != "id" {
+		query = query.Order(cursor.SortBy, &postgrest.OrderOpts{Ascending: queryAscending})
+	}
+
+	// We fetch one extra item to know if there are more items after this page
+	data, _, err := query.
+		Order("id", &postgrest.OrderOpts{Ascending: queryAscending}).
+		Limit(limit+1, "").
 		Execute()
 
 	if err != nil {
 		// Set the default error code and message
 		code := http.StatusInternalServerError
-		message := fmt.Sprintf("An error occurred while getting item page %v", page)
+		message := "An error occurred while getting items"
 
 		// Check if the error is a Postgres error
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
-
-			if code == http.StatusNotFound {
-				message = fmt.Sprintf("No items found for page %d", page)
-			}
+			message = utils.PostgresErrorMessage(err, message)
 		}
 
-		return nil, nil, &schemas.CustomError{
+		return nil, schemas.CursorPage{}, &schemas.CustomError{
 			Code:    code,
 			Message: message,
-			Details: fmt.Sprintf("Error retrieving items for page %d: %v", page, err),
+			Details: fmt.Sprintf("Error retrieving items for cursor %q: %v", cursorStr, err),
 		}
 	}
 
 	var items []schemas.Item
 	err = json.Unmarshal(data, &items)
 	if err != nil {
-		return nil, nil, &schemas.CustomError{
+		return nil, schemas.CursorPage{}, &schemas.CustomError{
 			Code:    http.StatusInternalServerError,
 			Message: "Failed to parse items data",
-			Details: fmt.Sprintf("Error parsing items data for page %d: %v", page, err),
+			Details: fmt.Sprintf("Error parsing items data for cursor %q: %v", cursorStr, err),
+		}
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	if cursor.Direction == utils.CursorPrev {
+		slices.Reverse(items)
+	}
+
+	page := schemas.CursorPage{Limit: limit}
+
+	hasNext, hasPrev := hasMore, hasCursor
+	if cursor.Direction == utils.CursorPrev {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if len(items) > 0 {
+		if hasNext {
+			last := items[len(items)-1]
+			next := utils.EncodeCursor(utils.Cursor{
+				SortBy:    cursor.SortBy,
+				Ascending: cursor.Ascending,
+				Value:     itemSortValue(last, cursor.SortBy),
+				Id:        last.Id,
+				Direction: utils.CursorNext,
+			})
+			page.NextCursor = &next
+		}
+
+		if hasPrev {
+			first := items[0]
+			prev := utils.EncodeCursor(utils.Cursor{
+				SortBy:    cursor.SortBy,
+				Ascending: cursor.Ascending,
+				Value:     itemSortValue(first, cursor.SortBy),
+				Id:        first.Id,
+				Direction: utils.CursorPrev,
+			})
+			page.PrevCursor = &prev
+		}
+	}
+
+	if includeCount {
+		countQuery, err := applyListFilters(client.
+			From("items").
+			Select("", "exact", true).
+			Is("deleted_at", "null"), filters)
+		if err != nil {
+			return nil, schemas.CursorPage{}, err
+		}
+
+		_, count, err := countQuery.Execute()
+
+		if err != nil {
+			return nil, schemas.CursorPage{}, &schemas.CustomError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to retrieve items",
+				Details: fmt.Sprintf("Failed to retrieve item count: %v", err),
+			}
 		}
+		page.Count = &count
 	}
 
 	for i := 0; i < len(items); i++ {
 		items[i].ImageUrl = GetItemImage(items[i].Id)
 	}
 
-	return items, &count, nil
+	return items, page, nil
+}
+
+// GetItemSearchFacets returns the facet counts for an item search.
+// See the search_item_facets database function.
+func GetItemSearchFacets(searchQuery string, filters schemas.ItemSearchFilters) (schemas.ItemFacets, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "search_item_facets", searchParams(searchQuery, filters))
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while computing search facets"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.ItemFacets{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error computing item search facets for %q: %v", searchQuery, err),
+		}
+	}
+
+	var facets schemas.ItemFacets
+	err = json.Unmarshal(data, &facets)
+	if err != nil {
+		return schemas.ItemFacets{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse search facets",
+			Details: fmt.Sprintf("Error parsing item search facets for %q: %v", searchQuery, err),
+		}
+	}
+
+	return facets, nil
+}
+
+// ApplyStockMovements changes the stock of items and records the changes in the stock movements ledger.
+// Either all movements are applied or none of them, and stock can never become negative.
+func ApplyStockMovements(movements []schemas.StockMovement) ([]schemas.StockMovement, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "apply_stock_movements", map[string]interface{}{
+		"movements": movements,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while changing the stock"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by apply_stock_movements are written for the user
+			if code == http.StatusBadRequest || code == http.StatusNotFound {
+				message = utils.ParsePostgresError(err).Message
+			}
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error applying %d stock movements: %v", len(movements), err),
+		}
+	}
+
+	var recorded []schemas.StockMovement
+	err = json.Unmarshal(data, &recorded)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stock movement data",
+			Details: fmt.Sprintf("Error parsing recorded stock movements: %v", err),
+		}
+	}
+
+	return recorded, nil
+}
+
+// GetItemStockMovements returns the latest stock movements of the item, newest first
+func GetItemStockMovements(id int8, limit int) ([]schemas.StockMovement, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("stock_movements").
+		Select("*", "", false).
+		Eq("item_id", idStr).
+		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
+		Order("id", &postgrest.OrderOpts{Ascending: false}).
+		Limit(limit, "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the stock movements"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving stock movements for item with ID %d: %v", id, err),
+		}
+	}
+
+	var movements []schemas.StockMovement
+	err = json.Unmarshal(data, &movements)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stock movement data",
+			Details: fmt.Sprintf("Error parsing stock movements for item ID %d: %v", id, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if movements == nil {
+		movements = []schemas.StockMovement{}
+	}
+
+	return movements, nil
 }
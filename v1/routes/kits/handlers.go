@@ -0,0 +1,359 @@
+package kits
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func GetKitComponentsHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	components, err := GetKitComponents(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve kit components", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving kit components", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve kit components",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Kit components retrieved successfully",
+		Data:    components,
+	})
+}
+
+// getPositiveInt reads a positive whole number from the body data
+func getPositiveInt(data map[string]interface{}, field string) (int, bool) {
+	value, ok := data[field].(float64)
+	if !ok || value < 1 || value != float64(int(value)) {
+		return 0, false
+	}
+	return int(value), true
+}
+
+func AddKitComponentHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var componentData map[string]interface{}
+	if err := context.ShouldBindJSON(&componentData); err != nil {
+		slog.Error("Failed to parse JSON of new kit component", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err = utils.CheckRequiredFields(componentData, []string{"component_item_id", "quantity"})
+	if err != nil {
+		slog.Error("Missing required fields in kit component data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	componentItemId, ok := getPositiveInt(componentData, "component_item_id")
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid component_item_id",
+		})
+		return
+	}
+
+	quantity, ok := getPositiveInt(componentData, "quantity")
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "quantity must be a positive number",
+		})
+		return
+	}
+
+	component, err := AddKitComponent(id, int8(componentItemId), quantity)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to add kit component", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when adding kit component", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to add kit component",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Kit component added successfully",
+		Data:    component,
+	})
+}
+
+func UpdateKitComponentHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	componentIdStr := context.Param("componentId")
+	componentId, err := strconv.ParseInt(componentIdStr, 10, 8)
+	if err != nil {
+		slog.Error("Failed to get component ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid component ID",
+		})
+		return
+	}
+
+	var componentData map[string]interface{}
+	if err := context.ShouldBindJSON(&componentData); err != nil {
+		slog.Error("Failed to parse JSON of kit component update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	// Only the quantity of a component can be changed
+	quantity, ok := getPositiveInt(componentData, "quantity")
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "quantity must be a positive number",
+		})
+		return
+	}
+
+	component, err := UpdateKitComponent(id, int8(componentId), quantity)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update kit component", "id", id, "component_id", componentId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating kit component", "id", id, "component_id", componentId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update kit component",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Kit component updated successfully",
+		Data:    component,
+	})
+}
+
+func DeleteKitComponentHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	componentIdStr := context.Param("componentId")
+	componentId, err := strconv.ParseInt(componentIdStr, 10, 8)
+	if err != nil {
+		slog.Error("Failed to get component ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid component ID",
+		})
+		return
+	}
+
+	err = DeleteKitComponent(id, int8(componentId))
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to remove kit component", "id", id, "component_id", componentId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when removing kit component", "id", id, "component_id", componentId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to remove kit component",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Kit component removed successfully",
+	})
+}
+
+func GetKitAvailabilityHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	availability, err := GetKitAvailability(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to compute kit availability", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when computing kit availability", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to compute kit availability",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Kit availability retrieved successfully",
+		Data:    availability,
+	})
+}
+
+// kitOperationHandler returns a handler that reads the quantity and note from the body
+// and runs the kit operation
+func kitOperationHandler(operation func(kitId int8, quantity int, note *string) (schemas.KitOperation, error), name string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id, err := utils.GetIdFromContext(context)
+		if err != nil {
+			slog.Error("Failed to get ID from context", "error", err)
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid ID",
+			})
+			return
+		}
+
+		var operationData map[string]interface{}
+		if err := context.ShouldBindJSON(&operationData); err != nil {
+			slog.Error("Failed to parse JSON of kit "+name, "error", err)
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid JSON in body.",
+			})
+			return
+		}
+
+		quantity, ok := getPositiveInt(operationData, "quantity")
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "quantity must be a positive number",
+			})
+			return
+		}
+
+		var note *string
+		if noteStr, exists := operationData["note"].(string); exists {
+			note = &noteStr
+		}
+
+		result, err := operation(id, quantity, note)
+		if err != nil {
+			if utils.IsCustomError(err) {
+				customErr := err.(*schemas.CustomError)
+				slog.Error("Failed kit "+name, "id", id, "quantity", quantity, "error", customErr.Details)
+				context.JSON(customErr.Code, schemas.ApiResponse{
+					Success: false,
+					Message: customErr.Message,
+				})
+				return
+			}
+
+			slog.Error("Unexpected error during kit "+name, "id", id, "quantity", quantity, "error", err)
+			context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+				Success: false,
+				Message: "Failed kit " + name,
+			})
+			return
+		}
+
+		context.JSON(http.StatusOK, schemas.ApiResponse{
+			Success: true,
+			Message: "Kit " + name + " completed successfully",
+			Data:    result,
+		})
+	}
+}
+
+var AssembleKitsHandler = kitOperationHandler(AssembleKits, "assembly")
+
+var DisassembleKitsHandler = kitOperationHandler(DisassembleKits, "disassembly")
@@ -0,0 +1,18 @@
+package kits
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// SetupItemKitRoutes sets up the kit routes nested below an item, which is the kit
+func SetupItemKitRoutes(routes *gin.RouterGroup) {
+	routes.GET("/components", GetKitComponentsHandler)
+	routes.GET("/available-to-build", GetKitAvailabilityHandler)
+
+	routes.POST("/components", AddKitComponentHandler)
+	routes.PATCH("/components/:componentId", UpdateKitComponentHandler)
+	routes.DELETE("/components/:componentId", DeleteKitComponentHandler)
+
+	routes.POST("/assemble", AssembleKitsHandler)
+	routes.POST("/disassemble", DisassembleKitsHandler)
+}
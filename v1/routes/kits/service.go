@@ -0,0 +1,358 @@
+package kits
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/currencies"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/items"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// componentSelect embeds the component item. kit_components references items twice,
+// so the embed is disambiguated by the foreign key column.
+const componentSelect = "*, component:items!component_item_id(id, name, sku, quantity, purchase_price, purchase_unit_cost, purchase_currency)"
+
+func GetKitComponents(kitId int8) ([]schemas.KitComponent, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", kitId)
+
+	data, _, err := client.
+		From("kit_components").
+		Select(componentSelect, "", false).
+		Eq("kit_item_id", idStr).
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the kit components"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving components of kit with ID %d: %v", kitId, err),
+		}
+	}
+
+	var components []schemas.KitComponent
+	err = json.Unmarshal(data, &components)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse kit component data",
+			Details: fmt.Sprintf("Error parsing component data for kit ID %d: %v", kitId, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if components == nil {
+		components = []schemas.KitComponent{}
+	}
+
+	return components, nil
+}
+
+func AddKitComponent(kitId int8, componentItemId int8, quantity int) (schemas.KitComponent, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("kit_components").
+		Insert(map[string]interface{}{
+			"kit_item_id":       kitId,
+			"component_item_id": componentItemId,
+			"quantity":          quantity,
+			"created_at":        utils.GetCurrentISODate(),
+			"updated_at":        utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while adding the kit component"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = "The item is already a component of this kit"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Kit or component item not found, or the component is the kit itself"
+			} else if code == http.StatusBadRequest {
+				message = "A kit can not contain itself as a component"
+			}
+		}
+
+		return schemas.KitComponent{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error adding component %d to kit with ID %d: %v", componentItemId, kitId, err),
+		}
+	}
+
+	var component schemas.KitComponent
+	err = json.Unmarshal(data, &component)
+	if err != nil {
+		return schemas.KitComponent{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse kit component data",
+			Details: fmt.Sprintf("Error parsing component data while adding component to kit %d: %v", kitId, err),
+		}
+	}
+
+	return component, nil
+}
+
+func UpdateKitComponent(kitId int8, componentId int8, quantity int) (schemas.KitComponent, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("kit_components").
+		Update(map[string]interface{}{
+			"quantity":   quantity,
+			"updated_at": utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", fmt.Sprintf("%d", componentId)).
+		Eq("kit_item_id", fmt.Sprintf("%d", kitId)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the kit component"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Kit component not found"
+			}
+		}
+
+		return schemas.KitComponent{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating component %d of kit with ID %d: %v", componentId, kitId, err),
+		}
+	}
+
+	var component schemas.KitComponent
+	err = json.Unmarshal(data, &component)
+	if err != nil {
+		return schemas.KitComponent{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse kit component data",
+			Details: fmt.Sprintf("Error parsing component data for component %d: %v", componentId, err),
+		}
+	}
+
+	return component, nil
+}
+
+func DeleteKitComponent(kitId int8, componentId int8) error {
+	client := db.Connect()
+
+	_, _, err := client.
+		From("kit_components").
+		Delete("", "").
+		Eq("id", fmt.Sprintf("%d", componentId)).
+		Eq("kit_item_id", fmt.Sprintf("%d", kitId)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while removing the kit component"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Kit component not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error removing component %d of kit with ID %d: %v", componentId, kitId, err),
+		}
+	}
+
+	return nil
+}
+
+// ComputeKitAvailability computes how many kits can be built from the stock of the components.
+// The component with the least stock relative to its quantity per kit limits the result.
+func ComputeKitAvailability(kitId int8, components []schemas.KitComponent) schemas.KitAvailability {
+	availability := schemas.KitAvailability{
+		KitItemId:  kitId,
+		Components: []schemas.KitComponentAvailability{},
+	}
+
+	if len(components) == 0 {
+		return availability
+	}
+
+	availableToBuild := math.MaxInt
+	for _, component := range components {
+		componentAvailability := schemas.KitComponentAvailability{
+			ComponentItemId: component.ComponentItemId,
+			PerKit:          component.Quantity,
+		}
+
+		if component.Component != nil {
+			componentAvailability.Name = component.Component.Name
+			componentAvailability.OnHand = max(component.Component.Quantity, 0)
+		}
+		componentAvailability.Buildable = componentAvailability.OnHand / component.Quantity
+
+		availableToBuild = min(availableToBuild, componentAvailability.Buildable)
+		availability.Components = append(availability.Components, componentAvailability)
+	}
+
+	availability.AvailableToBuild = availableToBuild
+	return availability
+}
+
+func GetKitAvailability(kitId int8) (schemas.KitAvailability, error) {
+	components, err := GetKitComponents(kitId)
+	if err != nil {
+		return schemas.KitAvailability{}, err
+	}
+
+	return ComputeKitAvailability(kitId, components), nil
+}
+
+// buildKitMovements returns the stock movements for assembling (direction 1) or disassembling (direction -1) kits.
+// The kit is valued at the purchase price of its components, converted to the purchase currency of the kit.
+func buildKitMovements(kitId int8, kitCurrency string, components []schemas.KitComponent, quantity int, direction int, movementType string, note *string) ([]schemas.StockMovement, error) {
+	referenceType := "kit"
+	referenceId := int64(kitId)
+
+	var movements []schemas.StockMovement
+	kitCost := 0.0
+	// rates is only loaded when a component is priced in another currency than the kit
+	var rates *currencies.RateTable
+	for _, component := range components {
+		movement := schemas.StockMovement{
+			ItemId:        component.ComponentItemId,
+			Quantity:      -direction * component.Quantity * quantity,
+			Type:          movementType,
+			ReferenceType: &referenceType,
+			ReferenceId:   &referenceId,
+			Note:          note,
+		}
+
+		if component.Component != nil {
+			unitCost := component.Component.PurchaseUnitCost
+			movement.UnitCost = &unitCost
+
+			// Components bought in another currency are converted with the current exchange rates
+			if component.Component.PurchaseCurrency != kitCurrency {
+				if rates == nil {
+					loadedRates, err := currencies.LoadRateTable("")
+					if err != nil {
+						return nil, err
+					}
+					rates = loadedRates
+				}
+
+				convertedCost, err := rates.ConvertAmount(unitCost, component.Component.PurchaseCurrency, kitCurrency)
+				if err != nil {
+					return nil, err
+				}
+				unitCost = convertedCost
+			}
+
+			kitCost += unitCost * float64(component.Quantity)
+		}
+
+		movements = append(movements, movement)
+	}
+
+	movements = append(movements, schemas.StockMovement{
+		ItemId:        kitId,
+		Quantity:      direction * quantity,
+		Type:          movementType,
+		UnitCost:      &kitCost,
+		ReferenceType: &referenceType,
+		ReferenceId:   &referenceId,
+		Note:          note,
+	})
+
+	return movements, nil
+}
+
+// runKitOperation assembles or disassembles kits through the item stock movements,
+// so all component and kit quantities change together or not at all
+func runKitOperation(kitId int8, quantity int, direction int, movementType string, note *string) (schemas.KitOperation, error) {
+	components, err := GetKitComponents(kitId)
+	if err != nil {
+		return schemas.KitOperation{}, err
+	}
+
+	if len(components) == 0 {
+		return schemas.KitOperation{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "The item has no kit components",
+			Details: fmt.Sprintf("Attempted to %s item %d, which has no components", movementType, kitId),
+		}
+	}
+
+	kit, err := items.GetItem(kitId)
+	if err != nil {
+		return schemas.KitOperation{}, err
+	}
+
+	kitMovements, err := buildKitMovements(kitId, kit.PurchaseCurrency, components, quantity, direction, movementType, note)
+	if err != nil {
+		return schemas.KitOperation{}, err
+	}
+
+	movements, err := items.ApplyStockMovements(kitMovements)
+	if err != nil {
+		return schemas.KitOperation{}, err
+	}
+
+	return schemas.KitOperation{
+		KitItemId: kitId,
+		Quantity:  quantity,
+		Movements: movements,
+	}, nil
+}
+
+// AssembleKits consumes the components and produces the given quantity of kits
+func AssembleKits(kitId int8, quantity int, note *string) (schemas.KitOperation, error) {
+	return runKitOperation(kitId, quantity, 1, schemas.StockMovementKitAssembly, note)
+}
+
+// DisassembleKits consumes the given quantity of kits and returns the components to stock
+func DisassembleKits(kitId int8, quantity int, note *string) (schemas.KitOperation, error) {
+	return runKitOperation(kitId, quantity, -1, schemas.StockMovementKitDisassembly, note)
+}
@@ -0,0 +1,192 @@
+package labels
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	FormatPNG = "png"
+	FormatPDF = "pdf"
+)
+
+// labelRequest contains the label options, from either the query or the body
+type labelRequest struct {
+	ItemIds  []int8  `json:"item_ids"`
+	Format   string  `json:"format"`
+	Size     string  `json:"size"`
+	WidthMm  float64 `json:"width_mm"`
+	HeightMm float64 `json:"height_mm"`
+	CodeType string  `json:"code"`
+	Content  string  `json:"content"`
+}
+
+// toOptions validates the request and converts it to label options.
+// A custom width and height overrides the named size.
+func (request labelRequest) toOptions() (LabelOptions, error) {
+	options := LabelOptions{
+		CodeType: CodeTypeCode128,
+		Content:  ContentBarcode,
+	}
+
+	if request.WidthMm != 0 || request.HeightMm != 0 {
+		if request.WidthMm < 20 || request.WidthMm > 300 || request.HeightMm < 10 || request.HeightMm > 300 {
+			return options, fmt.Errorf("invalid label size, width must be 20-300 mm and height 10-300 mm")
+		}
+		options.Size = LabelSize{WidthMm: request.WidthMm, HeightMm: request.HeightMm}
+	} else {
+		size, exists := LabelSizes[request.Size]
+		if request.Size == "" {
+			size, exists = LabelSizes["medium"], true
+		}
+		if !exists {
+			return options, fmt.Errorf("invalid label size: %s", request.Size)
+		}
+		options.Size = size
+	}
+
+	switch request.CodeType {
+	case "", CodeTypeCode128:
+	case CodeTypeQR:
+		options.CodeType = CodeTypeQR
+		// QR codes link to the item unless a barcode is asked for
+		options.Content = ContentURL
+	default:
+		return options, fmt.Errorf("invalid code type: %s", request.CodeType)
+	}
+
+	switch request.Content {
+	case "":
+	case ContentBarcode, ContentURL:
+		options.Content = request.Content
+	default:
+		return options, fmt.Errorf("invalid code content: %s", request.Content)
+	}
+
+	return options, nil
+}
+
+func GetItemLabelHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	request := labelRequest{
+		ItemIds:  []int8{id},
+		Format:   context.DefaultQuery("format", FormatPNG),
+		Size:     context.Query("size"),
+		CodeType: context.Query("code"),
+		Content:  context.Query("content"),
+	}
+
+	if widthStr, exists := context.GetQuery("width-mm"); exists {
+		request.WidthMm, _ = strconv.ParseFloat(widthStr, 64)
+	}
+	if heightStr, exists := context.GetQuery("height-mm"); exists {
+		request.HeightMm, _ = strconv.ParseFloat(heightStr, 64)
+	}
+
+	renderLabels(context, request)
+}
+
+func CreateLabelsHandler(context *gin.Context) {
+	var request labelRequest
+	if err := context.ShouldBindJSON(&request); err != nil {
+		slog.Error("Failed to parse JSON of label request", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	if len(request.ItemIds) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "missing required field: item_ids",
+		})
+		return
+	}
+
+	if request.Format == "" {
+		request.Format = FormatPDF
+	}
+
+	renderLabels(context, request)
+}
+
+// renderLabels writes the labels as a PNG for a single item, a zip of PNGs for
+// multiple items or a PDF with a label per page
+func renderLabels(context *gin.Context, request labelRequest) {
+	options, err := request.toOptions()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if request.Format != FormatPNG && request.Format != FormatPDF {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid format: %s", request.Format),
+		})
+		return
+	}
+
+	labelItems, err := GetLabelItems(request.ItemIds)
+
+	var data []byte
+	contentType := "application/pdf"
+	fileName := "labels.pdf"
+
+	if err == nil {
+		switch {
+		case request.Format == FormatPDF:
+			data, err = RenderLabelsPDF(labelItems, options)
+		case len(labelItems) == 1:
+			data, err = RenderLabelPNG(labelItems[0], options)
+			contentType = "image/png"
+			fileName = fmt.Sprintf("label-%d.png", labelItems[0].Id)
+		default:
+			data, err = RenderLabelsZip(labelItems, options)
+			contentType = "application/zip"
+			fileName = "labels.zip"
+		}
+	}
+
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to render labels", "item_ids", request.ItemIds, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to render labels", "item_ids", request.ItemIds, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to render labels",
+		})
+		return
+	}
+
+	context.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", fileName))
+	context.Data(http.StatusOK, contentType, data)
+}
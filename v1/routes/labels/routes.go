@@ -0,0 +1,10 @@
+package labels
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupLabelRoutes(routes *gin.RouterGroup) {
+	routes.GET("/items/:id", GetItemLabelHandler)
+	routes.POST("/", CreateLabelsHandler)
+}
@@ -0,0 +1,335 @@
+package labels
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/items"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	CodeTypeCode128 = "code128"
+	CodeTypeQR      = "qr"
+
+	ContentBarcode = "barcode"
+	ContentURL     = "url"
+
+	// dpi is the resolution PNG labels and the codes embedded in PDF labels are rendered at
+	dpi = 300
+	// paddingMm is the blank space around the content of a label
+	paddingMm = 2.0
+)
+
+type LabelSize struct {
+	WidthMm  float64 `json:"width_mm"`
+	HeightMm float64 `json:"height_mm"`
+}
+
+// LabelSizes contains the predefined label sizes, matching common label printer rolls
+var LabelSizes = map[string]LabelSize{
+	"small":  {WidthMm: 50, HeightMm: 25},
+	"medium": {WidthMm: 62, HeightMm: 29},
+	"large":  {WidthMm: 100, HeightMm: 50},
+}
+
+type LabelOptions struct {
+	Size     LabelSize
+	CodeType string
+	Content  string
+}
+
+func mmToPx(mm float64) int {
+	return int(mm / 25.4 * dpi)
+}
+
+// GetLabelItems retrieves the items to print labels for, in the given order
+func GetLabelItems(ids []int8) ([]schemas.Item, error) {
+	labelItems := make([]schemas.Item, 0, len(ids))
+	for _, id := range ids {
+		item, err := items.GetItem(id)
+		if err != nil {
+			return nil, err
+		}
+		labelItems = append(labelItems, item)
+	}
+	return labelItems, nil
+}
+
+// labelCodeContent returns what the code on the label encodes.
+// For barcodes it is the first barcode of the item, falling back to the SKU and then the id.
+// For URLs it is the page of the item in the frontend, configured with PUBLIC_APP_URL.
+func labelCodeContent(item schemas.Item, content string) string {
+	if content == ContentURL {
+		baseURL := strings.TrimRight(os.Getenv("PUBLIC_APP_URL"), "/")
+		return fmt.Sprintf("%s/items/%d", baseURL, item.Id)
+	}
+
+	if len(item.Barcodes) > 0 {
+		return item.Barcodes[0].Code
+	}
+	if item.Sku != nil && *item.Sku != "" {
+		return *item.Sku
+	}
+	return fmt.Sprintf("%d", item.Id)
+}
+
+// renderCode renders the Code128 or QR code scaled to the given size in pixels
+func renderCode(content string, codeType string, widthPx int, heightPx int) (image.Image, error) {
+	var code barcode.Barcode
+	var err error
+
+	if codeType == CodeTypeQR {
+		code, err = qr.Encode(content, qr.M, qr.Auto)
+	} else {
+		code, err = code128.Encode(content)
+	}
+
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Failed to encode %q as %s", content, codeType),
+			Details: fmt.Sprintf("Error encoding label code %q as %s: %v", content, codeType, err),
+		}
+	}
+
+	scaled, err := barcode.Scale(code, widthPx, heightPx)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "The code does not fit on the label, use a larger label size",
+			Details: fmt.Sprintf("Error scaling %s code for %q to %dx%d px: %v", codeType, content, widthPx, heightPx, err),
+		}
+	}
+
+	return scaled, nil
+}
+
+// labelLayout contains the position of the code and the text on a label, in mm
+type labelLayout struct {
+	codeX, codeY, codeWidth, codeHeight float64
+	textX, textY, textWidth, textHeight float64
+}
+
+// layoutLabel places QR codes on the left of the text and Code128 codes below it
+func layoutLabel(options LabelOptions) labelLayout {
+	innerWidth := options.Size.WidthMm - 2*paddingMm
+	innerHeight := options.Size.HeightMm - 2*paddingMm
+
+	if options.CodeType == CodeTypeQR {
+		side := min(innerHeight, innerWidth/2)
+		return labelLayout{
+			codeX: paddingMm, codeY: paddingMm, codeWidth: side, codeHeight: side,
+			textX: paddingMm*2 + side, textY: paddingMm, textWidth: innerWidth - side - paddingMm, textHeight: innerHeight,
+		}
+	}
+
+	codeHeight := innerHeight * 0.45
+	return labelLayout{
+		codeX: paddingMm, codeY: options.Size.HeightMm - paddingMm - codeHeight, codeWidth: innerWidth, codeHeight: codeHeight,
+		textX: paddingMm, textY: paddingMm, textWidth: innerWidth, textHeight: innerHeight - codeHeight - paddingMm/2,
+	}
+}
+
+// labelLines returns the text lines of the label: name and SKU.
+// The purchase price is an internal cost, so it is not printed on labels that hang on the shelves.
+func labelLines(item schemas.Item) []string {
+	lines := []string{item.Name}
+	if item.Sku != nil && *item.Sku != "" {
+		lines = append(lines, "SKU: "+*item.Sku)
+	}
+	return lines
+}
+
+// RenderLabelPNG renders a single label as a PNG image
+func RenderLabelPNG(item schemas.Item, options LabelOptions) ([]byte, error) {
+	layout := layoutLabel(options)
+
+	label := image.NewRGBA(image.Rect(0, 0, mmToPx(options.Size.WidthMm), mmToPx(options.Size.HeightMm)))
+	draw.Draw(label, label.Bounds(), image.White, image.Point{}, draw.Src)
+
+	code, err := renderCode(labelCodeContent(item, options.Content), options.CodeType, mmToPx(layout.codeWidth), mmToPx(layout.codeHeight))
+	if err != nil {
+		return nil, err
+	}
+	codeOrigin := image.Pt(mmToPx(layout.codeX), mmToPx(layout.codeY))
+	draw.Draw(label, code.Bounds().Add(codeOrigin), code, image.Point{}, draw.Src)
+
+	lines := labelLines(item)
+	lineHeight := mmToPx(layout.textHeight) / len(lines)
+	for i, line := range lines {
+		drawTextLine(label, line, mmToPx(layout.textX), mmToPx(layout.textY)+i*lineHeight, mmToPx(layout.textWidth), lineHeight)
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, label); err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to render label",
+			Details: fmt.Sprintf("Error encoding label PNG for item %d: %v", item.Id, err),
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// drawTextLine draws the text with the built in bitmap font, scaled up to the line height.
+// Long text is scaled down to fit the width, and cut off if it gets too small to read.
+func drawTextLine(target draw.Image, text string, x int, y int, maxWidth int, lineHeight int) {
+	face := basicfont.Face7x13
+	// Leave a bit of space between the lines
+	scale := float64(lineHeight) * 0.8 / float64(face.Height)
+	fitScale := float64(maxWidth) / float64(face.Advance*max(len([]rune(text)), 1))
+	// At 300 dpi a scale of 2 is about 2 mm high, which is the smallest that is easy to read
+	scale = min(scale, max(fitScale, 2))
+
+	charWidth := float64(face.Advance) * scale
+	// The small epsilon avoids cutting off text that was scaled to fit exactly
+	maxChars := int(float64(maxWidth)/charWidth + 0.001)
+	if len([]rune(text)) > maxChars {
+		text = string([]rune(text)[:max(maxChars-3, 0)]) + "..."
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, face.Advance*len([]rune(text)), face.Height))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+	drawer := font.Drawer{
+		Dst:  small,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	drawer.DrawString(text)
+
+	scaledRect := image.Rect(x, y, x+int(float64(small.Bounds().Dx())*scale), y+int(float64(face.Height)*scale))
+	draw.NearestNeighbor.Scale(target, scaledRect, small, small.Bounds(), draw.Src, nil)
+}
+
+// RenderLabelsPDF renders the labels as a PDF with one label per page
+func RenderLabelsPDF(labelItems []schemas.Item, options LabelOptions) ([]byte, error) {
+	layout := layoutLabel(options)
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: options.Size.WidthMm, Ht: options.Size.HeightMm},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, item := range labelItems {
+		pdf.AddPage()
+
+		code, err := renderCode(labelCodeContent(item, options.Content), options.CodeType, mmToPx(layout.codeWidth), mmToPx(layout.codeHeight))
+		if err != nil {
+			return nil, err
+		}
+
+		var codePNG bytes.Buffer
+		if err := png.Encode(&codePNG, code); err != nil {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to render labels",
+				Details: fmt.Sprintf("Error encoding label code for item %d: %v", item.Id, err),
+			}
+		}
+
+		imageName := fmt.Sprintf("code-%d", item.Id)
+		imageOptions := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(imageName, imageOptions, &codePNG)
+		pdf.ImageOptions(imageName, layout.codeX, layout.codeY, layout.codeWidth, layout.codeHeight, false, imageOptions, 0, "")
+
+		lines := labelLines(item)
+		lineHeight := layout.textHeight / float64(len(lines))
+		// Font sizes are in points, 1 mm is 2.835 points
+		fontSize := min(lineHeight*2.835*0.8, 14)
+
+		for i, line := range lines {
+			style := ""
+			if i == 0 {
+				style = "B"
+			}
+			text := translate(line)
+
+			// Long text is shrunk to fit the width, down to 5 points
+			pdf.SetFont("Helvetica", style, fontSize)
+			if width := pdf.GetStringWidth(text); width > layout.textWidth {
+				pdf.SetFont("Helvetica", style, min(fontSize, max(fontSize*layout.textWidth/width, 5)))
+			}
+			text = fitText(pdf, text, layout.textWidth)
+			pdf.Text(layout.textX, layout.textY+float64(i)*lineHeight+lineHeight*0.8, text)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to render labels",
+			Details: fmt.Sprintf("Error writing label PDF: %v", err),
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// fitText cuts off the text so it fits the width with the current font
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+// RenderLabelsZip renders every label as a PNG and returns them in a zip archive
+func RenderLabelsZip(labelItems []schemas.Item, options LabelOptions) ([]byte, error) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+
+	for _, item := range labelItems {
+		label, err := RenderLabelPNG(item, options)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := archive.Create(fmt.Sprintf("label-%d.png", item.Id))
+		if err == nil {
+			_, err = file.Write(label)
+		}
+		if err != nil {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to render labels",
+				Details: fmt.Sprintf("Error adding label of item %d to zip: %v", item.Id, err),
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to render labels",
+			Details: fmt.Sprintf("Error writing label zip: %v", err),
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
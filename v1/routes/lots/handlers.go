@@ -0,0 +1,324 @@
+package lots
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify
+var protectedFields = []string{"id", "item_id", "created_at", "updated_at", "item"}
+
+// defaultExpiryWindow is the number of days used when the days parameter is not given
+const defaultExpiryWindow = 30
+
+func GetItemLotsHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	// Lots that are used up are hidden unless include-empty=true
+	includeEmpty := context.Query("include-empty") == "true"
+
+	lots, err := GetItemLots(id, includeEmpty)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve item lots", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving item lots", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve item lots",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Item lots retrieved successfully",
+		Data:    lots,
+	})
+}
+
+func CreateItemLotHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var lotData map[string]interface{}
+	if err := context.ShouldBindJSON(&lotData); err != nil {
+		slog.Error("Failed to parse JSON of new lot", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err = utils.CheckRequiredFields(lotData, []string{"lot_number", "quantity"})
+	if err != nil {
+		slog.Error("Missing required fields in lot data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	lotNumber, ok := lotData["lot_number"].(string)
+	if !ok || lotNumber == "" {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid lot_number",
+		})
+		return
+	}
+
+	quantity, ok := lotData["quantity"].(float64)
+	if !ok || quantity < 0 || quantity != float64(int(quantity)) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid quantity",
+		})
+		return
+	}
+
+	newLot := schemas.ItemLot{
+		LotNumber: lotNumber,
+		Quantity:  int(quantity),
+	}
+
+	if expiryDate, exists := lotData["expiry_date"]; exists && expiryDate != nil {
+		expiryDateStr, ok := expiryDate.(string)
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid expiry_date",
+			})
+			return
+		}
+		newLot.ExpiryDate = &expiryDateStr
+	}
+
+	if receivedAt, exists := lotData["received_at"]; exists && receivedAt != nil {
+		receivedAtStr, ok := receivedAt.(string)
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid received_at",
+			})
+			return
+		}
+		newLot.ReceivedAt = receivedAtStr
+	}
+
+	lot, err := CreateItemLot(id, newLot)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create item lot", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating item lot", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create item lot",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Item lot created successfully",
+		Data:    lot,
+	})
+}
+
+func UpdateItemLotHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lotIdStr := context.Param("lotId")
+	lotId, err := strconv.ParseInt(lotIdStr, 10, 64)
+	if err != nil {
+		slog.Error("Failed to get lot ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid lot ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of lot update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	lot, err := UpdateItemLot(id, lotId, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update item lot", "id", id, "lot_id", lotId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating item lot", "id", id, "lot_id", lotId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update item lot",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Item lot updated successfully",
+		Data:    lot,
+	})
+}
+
+// SuggestLotIssueHandler suggests which lots a quantity should be issued from, first expired first out
+func SuggestLotIssueHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	quantity, err := strconv.Atoi(context.Query("quantity"))
+	if err != nil || quantity <= 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "quantity must be a positive number",
+		})
+		return
+	}
+
+	includeExpired := context.Query("include-expired") == "true"
+
+	suggestion, err := SuggestLotIssue(id, quantity, includeExpired)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to suggest lots to issue", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when suggesting lots to issue", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to suggest lots to issue",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Lot issue suggestion retrieved successfully",
+		Data:    suggestion,
+	})
+}
+
+func GetExpiringLotsHandler(context *gin.Context) {
+	days := defaultExpiryWindow
+	if daysStr := context.Query("days"); daysStr != "" {
+		parsedDays, err := strconv.Atoi(daysStr)
+		if err != nil || !utils.InRange(parsedDays, 0, 3650) {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "days must be a number between 0 and 3650",
+			})
+			return
+		}
+		days = parsedDays
+	}
+
+	includeExpired := context.Query("include-expired") == "true"
+
+	lots, err := GetExpiringLots(days, includeExpired)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve expiring lots", "days", days, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving expiring lots", "days", days, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve expiring lots",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Expiring lots retrieved successfully",
+		Data:    lots,
+	})
+}
@@ -0,0 +1,18 @@
+package lots
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupLotRoutes(routes *gin.RouterGroup) {
+	routes.GET("/expiring", GetExpiringLotsHandler)
+}
+
+// SetupItemLotRoutes sets up the lot routes nested below an item
+func SetupItemLotRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetItemLotsHandler)
+	routes.GET("/fefo", SuggestLotIssueHandler)
+
+	routes.POST("/", CreateItemLotHandler)
+	routes.PATCH("/:lotId", UpdateItemLotHandler)
+}
@@ -0,0 +1,315 @@
+package lots
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// GetItemLots returns the lots of the item in FEFO order, earliest expiry first.
+// Lots without an expiry date come last.
+func GetItemLots(itemId int8, includeEmpty bool) ([]schemas.ItemLot, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", itemId)
+
+	query := client.
+		From("item_lots").
+		Select("*", "", false).
+		Eq("item_id", idStr)
+
+	if !includeEmpty {
+		query = query.Gt("quantity", "0")
+	}
+
+	data, _, err := query.
+		Order("expiry_date", &postgrest.OrderOpts{Ascending: true}).
+		Order("received_at", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the item lots"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving lots for item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var lots []schemas.ItemLot
+	err = json.Unmarshal(data, &lots)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item lot data",
+			Details: fmt.Sprintf("Error parsing lot data for item ID %d: %v", itemId, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if lots == nil {
+		lots = []schemas.ItemLot{}
+	}
+
+	return lots, nil
+}
+
+// validateExpiryDate checks that the expiry date is a YYYY-MM-DD date
+func validateExpiryDate(expiryDate *string) error {
+	if expiryDate == nil {
+		return nil
+	}
+
+	if _, err := time.Parse(time.DateOnly, *expiryDate); err != nil {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid expiry_date, expected YYYY-MM-DD",
+			Details: fmt.Sprintf("Failed to parse expiry date %q: %v", *expiryDate, err),
+		}
+	}
+
+	return nil
+}
+
+func CreateItemLot(itemId int8, lot schemas.ItemLot) (schemas.ItemLot, error) {
+	client := db.Connect()
+
+	if err := validateExpiryDate(lot.ExpiryDate); err != nil {
+		return schemas.ItemLot{}, err
+	}
+
+	if lot.ReceivedAt == "" {
+		lot.ReceivedAt = utils.GetCurrentISODate()
+	}
+
+	data, _, err := client.
+		From("item_lots").
+		Insert(map[string]interface{}{
+			"item_id":     itemId,
+			"lot_number":  lot.LotNumber,
+			"expiry_date": lot.ExpiryDate,
+			"quantity":    lot.Quantity,
+			"received_at": lot.ReceivedAt,
+			"created_at":  utils.GetCurrentISODate(),
+			"updated_at":  utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the lot"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = fmt.Sprintf("Lot %s already exists for this item", lot.LotNumber)
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Item not found"
+			}
+		}
+
+		return schemas.ItemLot{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating lot for item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var createdLot schemas.ItemLot
+	err = json.Unmarshal(data, &createdLot)
+	if err != nil {
+		return schemas.ItemLot{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item lot data",
+			Details: fmt.Sprintf("Error parsing lot data while creating lot for item %d: %v", itemId, err),
+		}
+	}
+
+	return createdLot, nil
+}
+
+func UpdateItemLot(itemId int8, lotId int64, updates map[string]interface{}) (schemas.ItemLot, error) {
+	client := db.Connect()
+
+	if expiryDate, exists := updates["expiry_date"]; exists && expiryDate != nil {
+		expiryDateStr, ok := expiryDate.(string)
+		if !ok {
+			expiryDateStr = fmt.Sprintf("%v", expiryDate)
+		}
+		if err := validateExpiryDate(&expiryDateStr); err != nil {
+			return schemas.ItemLot{}, err
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("item_lots").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", lotId)).
+		Eq("item_id", fmt.Sprintf("%d", itemId)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the lot"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Lot not found"
+			}
+		}
+
+		return schemas.ItemLot{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating lot %d of item with ID %d: %v", lotId, itemId, err),
+		}
+	}
+
+	var updatedLot schemas.ItemLot
+	err = json.Unmarshal(data, &updatedLot)
+	if err != nil {
+		return schemas.ItemLot{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item lot data",
+			Details: fmt.Sprintf("Error parsing lot data for lot %d: %v", lotId, err),
+		}
+	}
+
+	return updatedLot, nil
+}
+
+// AllocateFEFO allocates the quantity over the lots, first expired first out.
+// The lots must already be in FEFO order. Expired lots are skipped unless includeExpired is set.
+func AllocateFEFO(lots []schemas.ItemLot, quantity int, today string, includeExpired bool) schemas.LotIssueSuggestion {
+	suggestion := schemas.LotIssueSuggestion{
+		Requested:   quantity,
+		Allocations: []schemas.LotAllocation{},
+	}
+
+	remaining := quantity
+	for _, lot := range lots {
+		if remaining <= 0 {
+			break
+		}
+
+		// Dates in YYYY-MM-DD format can be compared as strings
+		if !includeExpired && lot.ExpiryDate != nil && *lot.ExpiryDate < today {
+			continue
+		}
+
+		allocated := min(lot.Quantity, remaining)
+		if allocated <= 0 {
+			continue
+		}
+
+		suggestion.Allocations = append(suggestion.Allocations, schemas.LotAllocation{
+			Lot:      lot,
+			Quantity: allocated,
+		})
+		remaining -= allocated
+	}
+
+	suggestion.Allocated = quantity - remaining
+	suggestion.Shortfall = remaining
+	return suggestion
+}
+
+// SuggestLotIssue suggests which lots to issue the quantity from
+func SuggestLotIssue(itemId int8, quantity int, includeExpired bool) (schemas.LotIssueSuggestion, error) {
+	lots, err := GetItemLots(itemId, false)
+	if err != nil {
+		return schemas.LotIssueSuggestion{}, err
+	}
+
+	return AllocateFEFO(lots, quantity, utils.GetCurrentISODay(), includeExpired), nil
+}
+
+// GetExpiringLots returns the lots with stock that expire within the given number of days.
+// Lots that have already expired are only included if includeExpired is set.
+func GetExpiringLots(days int, includeExpired bool) ([]schemas.ItemLot, error) {
+	client := db.Connect()
+
+	today := time.Now().UTC()
+	cutoff := today.AddDate(0, 0, days).Format(time.DateOnly)
+
+	query := client.
+		From("item_lots").
+		Select("*, item:items(id, name, sku)", "", false).
+		Gt("quantity", "0").
+		Lte("expiry_date", cutoff)
+
+	if !includeExpired {
+		query = query.Gte("expiry_date", today.Format(time.DateOnly))
+	}
+
+	data, _, err := query.
+		Order("expiry_date", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving expiring lots"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving lots expiring within %d days: %v", days, err),
+		}
+	}
+
+	var lots []schemas.ItemLot
+	err = json.Unmarshal(data, &lots)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item lot data",
+			Details: fmt.Sprintf("Error parsing lots expiring within %d days: %v", days, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if lots == nil {
+		lots = []schemas.ItemLot{}
+	}
+
+	return lots, nil
+}
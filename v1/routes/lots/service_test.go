@@ -0,0 +1,65 @@
+package lots
+
+import (
+	"testing"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func TestAllocateFEFO(t *testing.T) {
+	date := func(day string) *string { return &day }
+
+	// The lots are in FEFO order, as GetItemLots returns them
+	lots := []schemas.ItemLot{
+		{Id: 1, LotNumber: "EXPIRED", ExpiryDate: date("2026-10-01"), Quantity: 4},
+		{Id: 2, LotNumber: "TODAY", ExpiryDate: date("2026-10-19"), Quantity: 3},
+		{Id: 3, LotNumber: "EMPTY", ExpiryDate: date("2026-11-01"), Quantity: 0},
+		{Id: 4, LotNumber: "LATER", ExpiryDate: date("2027-01-01"), Quantity: 5},
+		{Id: 5, LotNumber: "NO-EXPIRY", Quantity: 10},
+	}
+
+	tests := []struct {
+		name           string
+		quantity       int
+		includeExpired bool
+		want           map[int64]int
+		shortfall      int
+	}{
+		{name: "first lot only", quantity: 2, want: map[int64]int{2: 2}},
+		{name: "across lots", quantity: 6, want: map[int64]int{2: 3, 4: 3}},
+		{name: "lot without expiry last", quantity: 12, want: map[int64]int{2: 3, 4: 5, 5: 4}},
+		{name: "shortfall", quantity: 20, want: map[int64]int{2: 3, 4: 5, 5: 10}, shortfall: 2},
+		{name: "include expired", quantity: 5, includeExpired: true, want: map[int64]int{1: 4, 2: 1}},
+		{name: "nothing requested", quantity: 0, want: map[int64]int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suggestion := AllocateFEFO(lots, test.quantity, "2026-10-19", test.includeExpired)
+
+			got := map[int64]int{}
+			for _, allocation := range suggestion.Allocations {
+				got[allocation.Lot.Id] = allocation.Quantity
+			}
+
+			if len(got) != len(test.want) {
+				t.Fatalf("allocations = %v, want %v", got, test.want)
+			}
+			for id, quantity := range test.want {
+				if got[id] != quantity {
+					t.Fatalf("allocations = %v, want %v", got, test.want)
+				}
+			}
+
+			if suggestion.Requested != test.quantity {
+				t.Errorf("Requested = %d, want %d", suggestion.Requested, test.quantity)
+			}
+			if suggestion.Shortfall != test.shortfall {
+				t.Errorf("Shortfall = %d, want %d", suggestion.Shortfall, test.shortfall)
+			}
+			if suggestion.Allocated != test.quantity-test.shortfall {
+				t.Errorf("Allocated = %d, want %d", suggestion.Allocated, test.quantity-test.shortfall)
+			}
+		})
+	}
+}
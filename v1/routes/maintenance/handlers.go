@@ -0,0 +1,74 @@
+package maintenance
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type purgeRequest struct {
+	RetentionDays *int `json:"retention_days"`
+	DryRun        bool `json:"dry_run"`
+}
+
+func PurgeHandler(context *gin.Context) {
+	var request purgeRequest
+	// The body is optional, without it the default retention is used
+	if context.Request.ContentLength != 0 {
+		if err := context.ShouldBindJSON(&request); err != nil {
+			slog.Error("Failed to parse JSON of purge", "error", err)
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid JSON in body.",
+			})
+			return
+		}
+	}
+
+	retentionDays := DefaultRetentionDays
+	if request.RetentionDays != nil {
+		retentionDays = *request.RetentionDays
+	}
+
+	if retentionDays < 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "retention_days must be at least 0",
+		})
+		return
+	}
+
+	result, err := Purge(retentionDays, request.DryRun)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to purge records", "retention_days", retentionDays, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when purging records", "retention_days", retentionDays, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to purge records",
+		})
+		return
+	}
+
+	message := "Records purged successfully"
+	if result.DryRun {
+		message = "Records to purge counted successfully"
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
@@ -0,0 +1,9 @@
+package maintenance
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupMaintenanceRoutes(routes *gin.RouterGroup) {
+	routes.POST("/purge", PurgeHandler)
+}
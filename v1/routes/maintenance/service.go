@@ -0,0 +1,56 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+// DefaultRetentionDays is how long deleted records and the event logs are kept when no retention is given
+const DefaultRetentionDays = 90
+
+// Purge removes the deleted items and suppliers, dispatched outbox events and finished webhook deliveries
+// that are older than the retention. A dry run only counts them.
+func Purge(retentionDays int, dryRun bool) (schemas.PurgeResult, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "purge_records", map[string]interface{}{
+		"retention_days": retentionDays,
+		"dry_run":        dryRun,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while purging records"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.PurgeResult{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error purging records older than %d days (dry run %t): %v", retentionDays, dryRun, err),
+		}
+	}
+
+	var result schemas.PurgeResult
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return schemas.PurgeResult{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse purge result",
+			Details: fmt.Sprintf("Error parsing purge result: %v", err),
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// valuationCSV writes the report with a row per item and a total row
+func valuationCSV(report schemas.ValuationReport) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{{"item_id", "sku", "name", "unit", "quantity", "unit_cost", "value", "currency"}}
+	for _, item := range report.Items {
+		sku := ""
+		if item.Sku != nil {
+			sku = *item.Sku
+		}
+
+		rows = append(rows, []string{
+			strconv.Itoa(int(item.ItemId)),
+			sku,
+			item.Name,
+			item.BaseUnit,
+			strconv.Itoa(item.Quantity),
+			strconv.FormatFloat(item.UnitCost, 'f', -1, 64),
+			strconv.FormatFloat(item.Value, 'f', -1, 64),
+			report.Currency,
+		})
+	}
+	rows = append(rows, []string{
+		"", "", "Total", "",
+		strconv.Itoa(report.TotalQuantity),
+		"",
+		strconv.FormatFloat(report.TotalValue, 'f', -1, 64),
+		report.Currency,
+	})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func GetValuationReportHandler(context *gin.Context) {
+	asOf := context.Query("as_of")
+	method := context.DefaultQuery("method", schemas.ValuationMethodFIFO)
+	format := context.DefaultQuery("format", FormatJSON)
+
+	if format != FormatJSON && format != FormatCSV {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid format: %s", format),
+		})
+		return
+	}
+
+	report, err := GetValuationReport(asOf, method)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to build valuation report", "as_of", asOf, "method", method, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when building valuation report", "as_of", asOf, "method", method, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to build valuation report",
+		})
+		return
+	}
+
+	if format == FormatJSON {
+		context.JSON(http.StatusOK, schemas.ApiResponse{
+			Success: true,
+			Message: "Valuation report built successfully",
+			Data:    report,
+		})
+		return
+	}
+
+	data, err := valuationCSV(report)
+	if err != nil {
+		slog.Error("Failed to write valuation report as CSV", "as_of", asOf, "method", method, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to build valuation report",
+		})
+		return
+	}
+
+	fileName := fmt.Sprintf("valuation-%s-%s.csv", report.AsOf, report.Method)
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	context.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
@@ -0,0 +1,9 @@
+package reports
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupReportRoutes(routes *gin.RouterGroup) {
+	routes.GET("/valuation", GetValuationReportHandler)
+}
@@ -0,0 +1,336 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/currencies"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// fetchPageSize is the number of rows fetched per request, which stays below the PostgREST row limit
+const fetchPageSize = 1000
+
+// getValuationItems returns all items, including deleted items, as they may have had stock on the report date
+func getValuationItems() ([]schemas.Item, error) {
+	client := db.Connect()
+
+	var items []schemas.Item
+	for start := 0; ; start += fetchPageSize {
+		data, _, err := client.
+			From("items").
+			Select("id, name, sku, quantity, base_unit, purchase_price, purchase_unit_cost, purchase_currency, created_at, deleted_at", "", false).
+			Order("id", &postgrest.OrderOpts{Ascending: true}).
+			Range(start, start+fetchPageSize-1, "").
+			Execute()
+
+		if err != nil {
+			// Set the default error code and message
+			code := http.StatusInternalServerError
+			message := "An error occurred while retrieving the items to value"
+
+			// Check if the error is a Postgres error
+			// If true we update the code and message accordingly
+			if status := utils.PostgresToHTTPError(err); status != nil {
+				code = *status
+				message = utils.PostgresErrorMessage(err, message)
+			}
+
+			return nil, &schemas.CustomError{
+				Code:    code,
+				Message: message,
+				Details: fmt.Sprintf("Error retrieving items from row %d for valuation: %v", start, err),
+			}
+		}
+
+		var page []schemas.Item
+		err = json.Unmarshal(data, &page)
+		if err != nil {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to parse items data",
+				Details: fmt.Sprintf("Error parsing items from row %d for valuation: %v", start, err),
+			}
+		}
+
+		items = append(items, page...)
+		if len(page) < fetchPageSize {
+			return items, nil
+		}
+	}
+}
+
+// getValuationMovements returns all stock movements, oldest first
+func getValuationMovements() ([]schemas.StockMovement, error) {
+	client := db.Connect()
+
+	var movements []schemas.StockMovement
+	for start := 0; ; start += fetchPageSize {
+		data, _, err := client.
+			From("stock_movements").
+			Select("id, item_id, quantity, type, unit_cost, currency, created_at", "", false).
+			Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+			Order("id", &postgrest.OrderOpts{Ascending: true}).
+			Range(start, start+fetchPageSize-1, "").
+			Execute()
+
+		if err != nil {
+			// Set the default error code and message
+			code := http.StatusInternalServerError
+			message := "An error occurred while retrieving the stock movements"
+
+			// Check if the error is a Postgres error
+			// If true we update the code and message accordingly
+			if status := utils.PostgresToHTTPError(err); status != nil {
+				code = *status
+				message = utils.PostgresErrorMessage(err, message)
+			}
+
+			return nil, &schemas.CustomError{
+				Code:    code,
+				Message: message,
+				Details: fmt.Sprintf("Error retrieving stock movements from row %d for valuation: %v", start, err),
+			}
+		}
+
+		var page []schemas.StockMovement
+		err = json.Unmarshal(data, &page)
+		if err != nil {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to parse stock movement data",
+				Details: fmt.Sprintf("Error parsing stock movements from row %d for valuation: %v", start, err),
+			}
+		}
+
+		movements = append(movements, page...)
+		if len(page) < fetchPageSize {
+			return movements, nil
+		}
+	}
+}
+
+// costLayer is a quantity received at the same unit cost
+type costLayer struct {
+	quantity int
+	unitCost float64
+}
+
+// stockValuer keeps the value of the stock of one item while the movements are replayed
+type stockValuer struct {
+	method string
+	// layers holds the received quantities oldest first, for FIFO
+	layers []costLayer
+	// quantity and value hold the running totals, for both methods
+	quantity int
+	value    float64
+}
+
+// averageCost returns the cost of one unit of the stock on hand, or the fallback when there is no stock
+func (valuer *stockValuer) averageCost(fallback float64) float64 {
+	if valuer.quantity <= 0 {
+		return fallback
+	}
+	return valuer.value / float64(valuer.quantity)
+}
+
+// receive adds stock at the unit cost
+func (valuer *stockValuer) receive(quantity int, unitCost float64) {
+	valuer.quantity += quantity
+	valuer.value += float64(quantity) * unitCost
+
+	if valuer.method == schemas.ValuationMethodFIFO {
+		valuer.layers = append(valuer.layers, costLayer{quantity: quantity, unitCost: unitCost})
+	}
+}
+
+// issue removes stock. FIFO takes it from the oldest layers, weighted average at the average cost.
+// Stock that was never received is ignored, so the stock does not go below zero.
+func (valuer *stockValuer) issue(quantity int) {
+	quantity = min(quantity, valuer.quantity)
+	if quantity <= 0 {
+		return
+	}
+
+	if valuer.method != schemas.ValuationMethodFIFO {
+		valuer.value -= valuer.averageCost(0) * float64(quantity)
+		valuer.quantity -= quantity
+		return
+	}
+
+	valuer.quantity -= quantity
+	for quantity > 0 && len(valuer.layers) > 0 {
+		layer := &valuer.layers[0]
+		taken := min(quantity, layer.quantity)
+
+		layer.quantity -= taken
+		valuer.value -= float64(taken) * layer.unitCost
+		quantity -= taken
+
+		if layer.quantity == 0 {
+			valuer.layers = valuer.layers[1:]
+		}
+	}
+}
+
+// ValueStock computes the stock value of each item at the end of the as of date in the base currency.
+//
+// The value follows from replaying the stock movements. Costs in other currencies are converted with
+// the exchange rates effective on the as of date. Stock that is not explained by the movements, like the
+// quantity an item was created with, is valued as an opening balance at the purchase price of the item.
+// Movements that add stock without a unit cost are valued at the average cost of the stock on hand.
+func ValueStock(asOf string, method string, items []schemas.Item, movements []schemas.StockMovement, rates *currencies.RateTable) (schemas.ValuationReport, error) {
+	baseCurrency := utils.GetBaseCurrency()
+	minorUnit, exists := rates.MinorUnit(baseCurrency)
+	if !exists {
+		return schemas.ValuationReport{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("Unknown base currency: %s", baseCurrency),
+			Details: fmt.Sprintf("Base currency %q is not in the currencies table", baseCurrency),
+		}
+	}
+
+	asOfDay, err := time.Parse(time.DateOnly, asOf)
+	if err != nil {
+		return schemas.ValuationReport{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid as_of, expected YYYY-MM-DD",
+			Details: fmt.Sprintf("Failed to parse valuation date %q: %v", asOf, err),
+		}
+	}
+	// Movements before the start of the next day are included
+	cutoff := asOfDay.AddDate(0, 0, 1)
+
+	// The ledger quantity of all movements is compared to the item quantity to find the opening balance
+	ledgerQuantities := map[int8]int{}
+	movementsByItem := map[int8][]schemas.StockMovement{}
+	for _, movement := range movements {
+		ledgerQuantities[movement.ItemId] += movement.Quantity
+
+		createdAt, err := time.Parse(time.RFC3339Nano, movement.CreatedAt)
+		if err != nil || !createdAt.Before(cutoff) {
+			continue
+		}
+		movementsByItem[movement.ItemId] = append(movementsByItem[movement.ItemId], movement)
+	}
+
+	report := schemas.ValuationReport{
+		AsOf:     asOf,
+		Method:   method,
+		Currency: baseCurrency,
+		Items:    []schemas.ItemValuation{},
+	}
+
+	for _, item := range items {
+		// Items that did not exist yet, or were already deleted, have no stock on the date
+		if createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt); err == nil && !createdAt.Before(cutoff) {
+			continue
+		}
+		if item.DeletedAt != nil {
+			if deletedAt, err := time.Parse(time.RFC3339Nano, *item.DeletedAt); err == nil && deletedAt.Before(cutoff) {
+				continue
+			}
+		}
+
+		purchaseCurrency := item.PurchaseCurrency
+		if purchaseCurrency == "" {
+			purchaseCurrency = baseCurrency
+		}
+		// The unit cost keeps the fractions of a minor unit of prices per pack
+		unitCost := item.PurchasePrice
+		if item.PurchaseUnitCost != nil {
+			unitCost = *item.PurchaseUnitCost
+		}
+		purchasePrice, err := rates.ConvertAmount(unitCost, purchaseCurrency, baseCurrency)
+		if err != nil {
+			return schemas.ValuationReport{}, err
+		}
+
+		valuer := stockValuer{method: method}
+		if opening := item.Quantity - ledgerQuantities[item.Id]; opening > 0 {
+			valuer.receive(opening, purchasePrice)
+		}
+
+		for _, movement := range movementsByItem[item.Id] {
+			if movement.Quantity < 0 {
+				valuer.issue(-movement.Quantity)
+				continue
+			}
+
+			unitCost := valuer.averageCost(purchasePrice)
+			if movement.UnitCost != nil {
+				costCurrency := purchaseCurrency
+				if movement.Currency != nil {
+					costCurrency = *movement.Currency
+				}
+
+				unitCost, err = rates.ConvertAmount(*movement.UnitCost, costCurrency, baseCurrency)
+				if err != nil {
+					return schemas.ValuationReport{}, err
+				}
+			}
+
+			valuer.receive(movement.Quantity, unitCost)
+		}
+
+		if valuer.quantity <= 0 {
+			continue
+		}
+
+		valueMinor := utils.MajorToMinor(valuer.value, minorUnit)
+		report.Items = append(report.Items, schemas.ItemValuation{
+			ItemId:     item.Id,
+			Name:       item.Name,
+			Sku:        item.Sku,
+			BaseUnit:   item.BaseUnit,
+			Quantity:   valuer.quantity,
+			UnitCost:   math.Round(valuer.value/float64(valuer.quantity)*10000) / 10000,
+			Value:      utils.MinorToMajor(valueMinor, minorUnit),
+			ValueMinor: valueMinor,
+		})
+
+		report.TotalQuantity += valuer.quantity
+		report.TotalValueMinor += valueMinor
+	}
+
+	report.TotalValue = utils.MinorToMajor(report.TotalValueMinor, minorUnit)
+	return report, nil
+}
+
+// GetValuationReport returns the stock value at the end of the as of date. An empty date is today.
+func GetValuationReport(asOf string, method string) (schemas.ValuationReport, error) {
+	if asOf == "" {
+		asOf = utils.GetCurrentISODay()
+	}
+
+	if method != schemas.ValuationMethodFIFO && method != schemas.ValuationMethodWeightedAverage {
+		return schemas.ValuationReport{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid method, must be %s or %s", schemas.ValuationMethodFIFO, schemas.ValuationMethodWeightedAverage),
+			Details: fmt.Sprintf("Invalid valuation method %q", method),
+		}
+	}
+
+	rates, err := currencies.LoadRateTable(asOf)
+	if err != nil {
+		return schemas.ValuationReport{}, err
+	}
+
+	items, err := getValuationItems()
+	if err != nil {
+		return schemas.ValuationReport{}, err
+	}
+
+	movements, err := getValuationMovements()
+	if err != nil {
+		return schemas.ValuationReport{}, err
+	}
+
+	return ValueStock(asOf, method, items, movements, rates)
+}
@@ -0,0 +1,140 @@
+package reports
+
+import (
+	"math"
+	"testing"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/currencies"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func TestStockValuer(t *testing.T) {
+	type step struct {
+		quantity int
+		unitCost float64
+	}
+
+	// Positive quantities are received at the unit cost, negative quantities are issued
+	steps := []step{{10, 1}, {10, 2}, {-15, 0}, {4, 3}, {-1, 0}}
+
+	tests := []struct {
+		method   string
+		quantity int
+		value    float64
+	}{
+		// FIFO issues the 10 at 1 and 5 at 2, then 1 more at 2: 4 at 2 and 4 at 3 remain
+		{method: schemas.ValuationMethodFIFO, quantity: 8, value: 20},
+		// The average is 1.5 after the receipts, so 5 at 1.5 remain, then 4 at 3 make 19.5 for 9,
+		// and one more is issued at the average
+		{method: schemas.ValuationMethodWeightedAverage, quantity: 8, value: 19.5 - 19.5/9},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			valuer := stockValuer{method: test.method}
+			for _, s := range steps {
+				if s.quantity > 0 {
+					valuer.receive(s.quantity, s.unitCost)
+				} else {
+					valuer.issue(-s.quantity)
+				}
+			}
+
+			if valuer.quantity != test.quantity {
+				t.Errorf("quantity = %d, want %d", valuer.quantity, test.quantity)
+			}
+			if math.Abs(valuer.value-test.value) > 1e-9 {
+				t.Errorf("value = %v, want %v", valuer.value, test.value)
+			}
+		})
+	}
+}
+
+func TestStockValuerIssueMoreThanOnHand(t *testing.T) {
+	for _, method := range []string{schemas.ValuationMethodFIFO, schemas.ValuationMethodWeightedAverage} {
+		valuer := stockValuer{method: method}
+		valuer.receive(3, 2)
+		valuer.issue(5)
+
+		if valuer.quantity != 0 || math.Abs(valuer.value) > 1e-9 {
+			t.Errorf("%s: quantity = %d and value = %v after issuing more than on hand, want 0 and 0", method, valuer.quantity, valuer.value)
+		}
+		if cost := valuer.averageCost(4); cost != 4 {
+			t.Errorf("%s: averageCost without stock = %v, want the fallback 4", method, cost)
+		}
+	}
+}
+
+func TestValueStock(t *testing.T) {
+	t.Setenv("BASE_CURRENCY", "EUR")
+
+	rates := currencies.NewRateTable("2026-10-19",
+		[]schemas.Currency{{Code: "EUR", MinorUnit: 2}, {Code: "USD", MinorUnit: 2}},
+		[]schemas.ExchangeRate{{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.25, EffectiveDate: "2026-01-01"}},
+	)
+
+	usd := "USD"
+	cost := func(value float64) *float64 { return &value }
+	boxCost := 0.0333
+
+	items := []schemas.Item{
+		// Stock from movements only, one receipt in USD
+		{Id: 1, Name: "Bolt", Quantity: 12, PurchasePrice: 2, PurchaseCurrency: "EUR", CreatedAt: "2026-09-01T00:00:00Z"},
+		// Stock without movements is an opening balance at the exact unit cost
+		{Id: 2, Name: "Washer", Quantity: 4, PurchasePrice: 0.03, PurchaseUnitCost: &boxCost, PurchaseCurrency: "EUR", CreatedAt: "2026-09-01T00:00:00Z"},
+		// Created after the as of date
+		{Id: 3, Name: "Nut", Quantity: 5, PurchasePrice: 1, PurchaseCurrency: "EUR", CreatedAt: "2026-10-20T08:00:00Z"},
+	}
+
+	movements := []schemas.StockMovement{
+		{ItemId: 1, Quantity: 10, UnitCost: cost(2), CreatedAt: "2026-10-01T10:00:00Z"},
+		{ItemId: 1, Quantity: 5, UnitCost: cost(3), Currency: &usd, CreatedAt: "2026-10-10T10:00:00Z"},
+		{ItemId: 1, Quantity: -5, CreatedAt: "2026-10-19T23:59:59Z"},
+		// After the as of date, so only part of the ledger quantity
+		{ItemId: 1, Quantity: 2, UnitCost: cost(5), CreatedAt: "2026-10-20T00:00:00Z"},
+	}
+
+	tests := []struct {
+		method string
+		// values in minor units per item id
+		values map[int8]int64
+		total  int64
+	}{
+		// 5 at 2 and 5 at 3 USD, which is 2.40 EUR, remain
+		{method: schemas.ValuationMethodFIFO, values: map[int8]int64{1: 2200, 2: 13}, total: 2213},
+		// 32 EUR for 15, of which 5 are issued at the average
+		{method: schemas.ValuationMethodWeightedAverage, values: map[int8]int64{1: 2133, 2: 13}, total: 2146},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			report, err := ValueStock("2026-10-19", test.method, items, movements, rates)
+			if err != nil {
+				t.Fatalf("ValueStock returned error: %v", err)
+			}
+
+			if len(report.Items) != len(test.values) {
+				t.Fatalf("ValueStock valued %d items, want %d: %+v", len(report.Items), len(test.values), report.Items)
+			}
+			for _, valuation := range report.Items {
+				if want := test.values[valuation.ItemId]; valuation.ValueMinor != want {
+					t.Errorf("value of item %d = %d, want %d", valuation.ItemId, valuation.ValueMinor, want)
+				}
+			}
+
+			if report.TotalValueMinor != test.total {
+				t.Errorf("TotalValueMinor = %d, want %d", report.TotalValueMinor, test.total)
+			}
+			if report.TotalQuantity != 14 {
+				t.Errorf("TotalQuantity = %d, want 14", report.TotalQuantity)
+			}
+			if report.Currency != "EUR" {
+				t.Errorf("Currency = %s, want EUR", report.Currency)
+			}
+		})
+	}
+
+	if _, err := ValueStock("19-10-2026", schemas.ValuationMethodFIFO, items, movements, rates); err == nil {
+		t.Error("ValueStock with an invalid date returned no error")
+	}
+}
@@ -0,0 +1,295 @@
+package reservations
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify.
+// The status changes through release and fulfill, and a reservation can not move to another item.
+var protectedFields = []string{"id", "item_id", "status", "released_at", "created_at", "updated_at", "item"}
+
+// getReservationIdFromContext returns the reservation ID of the path. Reservation IDs are 64 bit, unlike the
+// item IDs.
+func getReservationIdFromContext(context *gin.Context) (int64, error) {
+	return strconv.ParseInt(context.Param("id"), 10, 64)
+}
+
+// writeReservationsResponse writes the reservations, or the error when retrieving them failed
+func writeReservationsResponse(context *gin.Context, reservations []schemas.StockReservation, err error) {
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve reservations", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving reservations", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve reservations",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Reservations retrieved successfully",
+		Data:    reservations,
+	})
+}
+
+// GetReservationsHandler returns the reservations across items, filtered by status and owner
+func GetReservationsHandler(context *gin.Context) {
+	reservations, err := GetReservations(nil, context.Query("status"), context.Query("owner"))
+	writeReservationsResponse(context, reservations, err)
+}
+
+// GetItemReservationsHandler returns the reservations of an item, filtered by status
+func GetItemReservationsHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	reservations, err := GetReservations(&id, context.Query("status"), "")
+	writeReservationsResponse(context, reservations, err)
+}
+
+func GetReservationHandler(context *gin.Context) {
+	id, err := getReservationIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	reservation, err := GetReservation(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve reservation", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving reservation", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve reservation",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Reservation retrieved successfully",
+		Data:    reservation,
+	})
+}
+
+// CreateItemReservationHandler reserves stock of the item
+func CreateItemReservationHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var reservationData map[string]interface{}
+	if err := context.ShouldBindJSON(&reservationData); err != nil {
+		slog.Error("Failed to parse JSON of new reservation", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err = utils.CheckRequiredFields(reservationData, []string{"quantity", "owner"})
+	if err != nil {
+		slog.Error("Missing required fields in reservation data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	quantity, quantityOk := reservationData["quantity"].(float64)
+	owner, ownerOk := reservationData["owner"].(string)
+	if !quantityOk || !ownerOk || quantity != float64(int(quantity)) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "quantity must be a whole number and owner must be a string",
+		})
+		return
+	}
+
+	newReservation := schemas.StockReservation{
+		ItemId:   id,
+		Quantity: int(quantity),
+		Owner:    owner,
+	}
+
+	if reason, exists := reservationData["reason"].(string); exists {
+		newReservation.Reason = &reason
+	}
+
+	// Without an expiry the reservation holds until it is released or fulfilled
+	if expiresAt, exists := reservationData["expires_at"].(string); exists {
+		newReservation.ExpiresAt = &expiresAt
+	}
+
+	reservation, err := CreateReservation(newReservation)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create reservation", "item_id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating reservation", "item_id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create reservation",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Reservation created successfully",
+		Data:    reservation,
+	})
+}
+
+func UpdateReservationHandler(context *gin.Context) {
+	id, err := getReservationIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of reservation update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	reservation, err := UpdateReservation(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update reservation", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating reservation", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update reservation",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Reservation updated successfully",
+		Data:    reservation,
+	})
+}
+
+// closeReservationHandler returns a handler that ends the reservation
+func closeReservationHandler(closeFunc func(id int64) (schemas.StockReservation, error), name string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id, err := getReservationIdFromContext(context)
+		if err != nil {
+			slog.Error("Failed to get ID from context", "error", err)
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid ID",
+			})
+			return
+		}
+
+		reservation, err := closeFunc(id)
+		if err != nil {
+			if utils.IsCustomError(err) {
+				customErr := err.(*schemas.CustomError)
+				slog.Error("Failed to "+name+" reservation", "id", id, "error", customErr.Details)
+				context.JSON(customErr.Code, schemas.ApiResponse{
+					Success: false,
+					Message: customErr.Message,
+				})
+				return
+			}
+
+			slog.Error("Unexpected error when trying to "+name+" reservation", "id", id, "error", err)
+			context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+				Success: false,
+				Message: "Failed to " + name + " reservation",
+			})
+			return
+		}
+
+		context.JSON(http.StatusOK, schemas.ApiResponse{
+			Success: true,
+			Message: "Reservation " + reservation.Status + " successfully",
+			Data:    reservation,
+		})
+	}
+}
+
+var ReleaseReservationHandler = closeReservationHandler(ReleaseReservation, "release")
+
+var FulfillReservationHandler = closeReservationHandler(FulfillReservation, "fulfill")
@@ -0,0 +1,21 @@
+package reservations
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupReservationRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetReservationsHandler)
+	routes.GET("/:id", GetReservationHandler)
+
+	routes.PATCH("/:id", UpdateReservationHandler)
+	routes.POST("/:id/release", ReleaseReservationHandler)
+	routes.POST("/:id/fulfill", FulfillReservationHandler)
+}
+
+// SetupItemReservationRoutes sets up the reservation routes nested below an item
+func SetupItemReservationRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetItemReservationsHandler)
+
+	routes.POST("/", CreateItemReservationHandler)
+}
@@ -0,0 +1,415 @@
+package reservations
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// reservationSelect embeds the reserved item
+const reservationSelect = "*, item:items(id, name, sku)"
+
+// GetReservations returns the reservations, filtered by item, status and owner. Empty filters are ignored.
+func GetReservations(itemId *int8, status string, owner string) ([]schemas.StockReservation, error) {
+	client := db.Connect()
+
+	query := client.
+		From("stock_reservations").
+		Select(reservationSelect, "", false)
+
+	if itemId != nil {
+		query = query.Eq("item_id", fmt.Sprintf("%d", *itemId))
+	}
+	if status != "" {
+		query = query.Eq("status", status)
+	}
+	if owner != "" {
+		query = query.Eq("owner", owner)
+	}
+
+	data, _, err := query.
+		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving reservations"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving reservations with status %q and owner %q: %v", status, owner, err),
+		}
+	}
+
+	var reservations []schemas.StockReservation
+	err = json.Unmarshal(data, &reservations)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse reservation data",
+			Details: fmt.Sprintf("Error parsing reservation data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if reservations == nil {
+		reservations = []schemas.StockReservation{}
+	}
+
+	return reservations, nil
+}
+
+func GetReservation(id int64) (schemas.StockReservation, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("stock_reservations").
+		Select(reservationSelect, "", false).
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the reservation"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Reservation not found"
+			}
+		}
+
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving reservation with ID %d: %v", id, err),
+		}
+	}
+
+	var reservation schemas.StockReservation
+	err = json.Unmarshal(data, &reservation)
+	if err != nil {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse reservation data",
+			Details: fmt.Sprintf("Error parsing reservation data for ID %d: %v", id, err),
+		}
+	}
+
+	return reservation, nil
+}
+
+// validateExpiry checks that the expiry is a timestamp in the future
+func validateExpiry(expiresAt string) error {
+	expiry, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid expires_at, expected an RFC 3339 timestamp",
+			Details: fmt.Sprintf("Failed to parse reservation expiry %q: %v", expiresAt, err),
+		}
+	}
+
+	if !expiry.After(time.Now()) {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "expires_at must be in the future",
+			Details: fmt.Sprintf("Reservation expiry %q is in the past", expiresAt),
+		}
+	}
+
+	return nil
+}
+
+// CreateReservation reserves stock of an item. The database rejects reservations of more than is available.
+func CreateReservation(reservation schemas.StockReservation) (schemas.StockReservation, error) {
+	client := db.Connect()
+
+	if reservation.Quantity <= 0 {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "quantity must be a positive number",
+			Details: fmt.Sprintf("Invalid reservation quantity %d for item %d", reservation.Quantity, reservation.ItemId),
+		}
+	}
+
+	reservation.Owner = strings.TrimSpace(reservation.Owner)
+	if reservation.Owner == "" {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "owner is required",
+			Details: fmt.Sprintf("Attempted to reserve item %d without an owner", reservation.ItemId),
+		}
+	}
+
+	if reservation.ExpiresAt != nil {
+		if err := validateExpiry(*reservation.ExpiresAt); err != nil {
+			return schemas.StockReservation{}, err
+		}
+	}
+
+	data, _, err := client.
+		From("stock_reservations").
+		Insert(map[string]interface{}{
+			"item_id":        reservation.ItemId,
+			"quantity":       reservation.Quantity,
+			"owner":          reservation.Owner,
+			"reason":         reservation.Reason,
+			"expires_at":     reservation.ExpiresAt,
+			"reference_type": reservation.ReferenceType,
+			"reference_id":   reservation.ReferenceId,
+			"created_at":     utils.GetCurrentISODate(),
+			"updated_at":     utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the reservation"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by the reservation triggers are written for the user
+			if code == http.StatusBadRequest {
+				message = utils.ParsePostgresError(err).Message
+			} else if code == http.StatusNotFound || code == http.StatusUnprocessableEntity {
+				message = "Item not found"
+			}
+		}
+
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error reserving %d of item %d for %q: %v", reservation.Quantity, reservation.ItemId, reservation.Owner, err),
+		}
+	}
+
+	var createdReservation schemas.StockReservation
+	err = json.Unmarshal(data, &createdReservation)
+	if err != nil {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse reservation data",
+			Details: fmt.Sprintf("Error parsing reservation data while creating reservation: %v", err),
+		}
+	}
+
+	return createdReservation, nil
+}
+
+// UpdateReservation changes an active reservation
+func UpdateReservation(id int64, updates map[string]interface{}) (schemas.StockReservation, error) {
+	client := db.Connect()
+
+	if quantity, exists := updates["quantity"]; exists {
+		if quantityValue, ok := quantity.(float64); !ok || quantityValue < 1 || quantityValue != float64(int(quantityValue)) {
+			return schemas.StockReservation{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "quantity must be a positive whole number",
+				Details: fmt.Sprintf("Invalid reservation quantity %v for reservation %d", quantity, id),
+			}
+		}
+	}
+
+	if expiresAt, exists := updates["expires_at"]; exists && expiresAt != nil {
+		expiresAtStr, _ := expiresAt.(string)
+		if err := validateExpiry(expiresAtStr); err != nil {
+			return schemas.StockReservation{}, err
+		}
+	}
+
+	reservation, err := GetReservation(id)
+	if err != nil {
+		return schemas.StockReservation{}, err
+	}
+	if reservation.Status != schemas.ReservationStatusActive {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Only active reservations can be changed, this reservation is %s", reservation.Status),
+			Details: fmt.Sprintf("Attempted to update reservation %d with status %s", id, reservation.Status),
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("stock_reservations").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.ReservationStatusActive).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the reservation"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by the reservation triggers are written for the user
+			if code == http.StatusBadRequest {
+				message = utils.ParsePostgresError(err).Message
+			} else if code == http.StatusNotFound {
+				message = "Reservation not found"
+			}
+		}
+
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating reservation with ID %d: %v", id, err),
+		}
+	}
+
+	var updatedReservation schemas.StockReservation
+	err = json.Unmarshal(data, &updatedReservation)
+	if err != nil {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse reservation data",
+			Details: fmt.Sprintf("Error parsing reservation data for ID %d: %v", id, err),
+		}
+	}
+
+	return updatedReservation, nil
+}
+
+// closeReservation ends an active reservation with the status
+func closeReservation(id int64, status string) (schemas.StockReservation, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("stock_reservations").
+		Update(map[string]interface{}{
+			"status":      status,
+			"released_at": utils.GetCurrentISODate(),
+			"updated_at":  utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.ReservationStatusActive).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while closing the reservation"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Reservation not found"
+
+				// The reservation may exist, but not be active anymore
+				if reservation, getErr := GetReservation(id); getErr == nil {
+					code = http.StatusConflict
+					message = fmt.Sprintf("The reservation is already %s", reservation.Status)
+				}
+			}
+		}
+
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error setting status of reservation %d to %s: %v", id, status, err),
+		}
+	}
+
+	var reservation schemas.StockReservation
+	err = json.Unmarshal(data, &reservation)
+	if err != nil {
+		return schemas.StockReservation{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse reservation data",
+			Details: fmt.Sprintf("Error parsing reservation data for ID %d: %v", id, err),
+		}
+	}
+
+	return reservation, nil
+}
+
+// ReleaseReservation gives the reserved stock back, without it being used
+func ReleaseReservation(id int64) (schemas.StockReservation, error) {
+	return closeReservation(id, schemas.ReservationStatusReleased)
+}
+
+// FulfillReservation marks the reservation as used, when the reserved stock is issued
+func FulfillReservation(id int64) (schemas.StockReservation, error) {
+	return closeReservation(id, schemas.ReservationStatusFulfilled)
+}
+
+// ExpireReservations expires the active reservations that are past their expiry and returns them
+func ExpireReservations() ([]schemas.StockReservation, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "expire_stock_reservations", map[string]interface{}{})
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while expiring reservations"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error expiring reservations: %v", err),
+		}
+	}
+
+	var expired []schemas.StockReservation
+	err = json.Unmarshal(data, &expired)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse reservation data",
+			Details: fmt.Sprintf("Error parsing expired reservations: %v", err),
+		}
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,47 @@
+package reservations
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+// DefaultExpiryInterval is used when RESERVATION_EXPIRY_INTERVAL is not set
+const DefaultExpiryInterval = time.Minute
+
+// GetExpiryInterval returns how often expired reservations are released, e.g. RESERVATION_EXPIRY_INTERVAL=30s
+func GetExpiryInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("RESERVATION_EXPIRY_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultExpiryInterval
+}
+
+// StartExpiryWorker releases the expired reservations every interval, until the context is done
+func StartExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := ExpireReservations()
+		if err != nil {
+			if utils.IsCustomError(err) {
+				slog.Error("Failed to expire reservations", "error", err.(*schemas.CustomError).Details)
+			} else {
+				slog.Error("Unexpected error when expiring reservations", "error", err)
+			}
+		} else if len(expired) > 0 {
+			slog.Info("Expired reservations", "count", len(expired))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,673 @@
+package returns
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify.
+// The type can not change and the status only changes through complete and cancel.
+var protectedFields = []string{
+	"id", "type", "status", "completed_at", "cancelled_at",
+	"created_at", "updated_at", "customer", "supplier", "lines",
+}
+
+// lineFields contains the fields of a line that can be updated. The outcome is set by inspecting the line.
+var lineFields = []string{"quantity", "lot_id", "reason", "sales_order_line_id"}
+
+// optionalId reads an ID field from the JSON body, which is nil when it is not set
+func optionalId(data map[string]interface{}, field string) (*int8, error) {
+	value, exists := data[field]
+	if !exists || value == nil {
+		return nil, nil
+	}
+
+	id, ok := value.(float64)
+	if !ok || id != float64(int8(id)) {
+		return nil, fmt.Errorf("%s must be a valid ID", field)
+	}
+
+	idValue := int8(id)
+	return &idValue, nil
+}
+
+// optionalLongId reads an optional line or lot ID from the JSON body. They are 64 bit, unlike the other IDs.
+func optionalLongId(data map[string]interface{}, field string) (*int64, error) {
+	value, exists := data[field]
+	if !exists || value == nil {
+		return nil, nil
+	}
+
+	id, ok := utils.ParseJSONId(value)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a valid ID", field)
+	}
+
+	return &id, nil
+}
+
+// parseReturnLine reads a line from the JSON body
+func parseReturnLine(lineData map[string]interface{}) (schemas.ReturnLine, error) {
+	if err := utils.CheckRequiredFields(lineData, []string{"item_id", "quantity"}); err != nil {
+		return schemas.ReturnLine{}, err
+	}
+
+	itemId, err := optionalId(lineData, "item_id")
+	if err != nil {
+		return schemas.ReturnLine{}, err
+	}
+
+	quantity, ok := lineData["quantity"].(float64)
+	if !ok || quantity != float64(int(quantity)) || quantity <= 0 {
+		return schemas.ReturnLine{}, fmt.Errorf("quantity must be a whole number greater than 0")
+	}
+
+	line := schemas.ReturnLine{ItemId: *itemId, Quantity: int(quantity)}
+
+	if line.LotId, err = optionalLongId(lineData, "lot_id"); err != nil {
+		return schemas.ReturnLine{}, err
+	}
+	if line.SalesOrderLineId, err = optionalLongId(lineData, "sales_order_line_id"); err != nil {
+		return schemas.ReturnLine{}, err
+	}
+
+	// Without a reason the line has the reason of the return
+	if reason, exists := lineData["reason"].(string); exists {
+		line.Reason = &reason
+	}
+
+	return line, nil
+}
+
+// getLineIdFromContext returns the line ID of the path
+func getLineIdFromContext(context *gin.Context) (int64, error) {
+	return strconv.ParseInt(context.Param("lineId"), 10, 64)
+}
+
+// GetReturnsHandler returns the returns, filtered by type, status and sales-order-id
+func GetReturnsHandler(context *gin.Context) {
+	returnType := context.Query("type")
+	status := context.Query("status")
+
+	var salesOrderId *int8
+	if salesOrderIdStr := context.Query("sales-order-id"); salesOrderIdStr != "" {
+		parsedId, err := strconv.ParseInt(salesOrderIdStr, 10, 8)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid sales order ID",
+			})
+			return
+		}
+		id := int8(parsedId)
+		salesOrderId = &id
+	}
+
+	returns, err := GetReturns(returnType, status, salesOrderId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve returns", "type", returnType, "status", status, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving returns", "type", returnType, "status", status, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve returns",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Returns retrieved successfully",
+		Data:    returns,
+	})
+}
+
+func GetReturnHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	ret, err := GetReturn(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve return", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving return", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve return",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return retrieved successfully",
+		Data:    ret,
+	})
+}
+
+// CreateReturnHandler opens a customer or supplier return, optionally with its lines
+func CreateReturnHandler(context *gin.Context) {
+	var returnData map[string]interface{}
+	if err := context.ShouldBindJSON(&returnData); err != nil {
+		slog.Error("Failed to parse JSON of new return", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(returnData, []string{"type", "reason"})
+	if err != nil {
+		slog.Error("Missing required fields in return data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	returnType, typeOk := returnData["type"].(string)
+	reason, reasonOk := returnData["reason"].(string)
+	if !typeOk || !reasonOk {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "type and reason must be strings",
+		})
+		return
+	}
+
+	newReturn := schemas.Return{Type: returnType, Reason: reason}
+
+	for field, target := range map[string]**int8{
+		"customer_id":    &newReturn.CustomerId,
+		"sales_order_id": &newReturn.SalesOrderId,
+		"supplier_id":    &newReturn.SupplierId,
+	} {
+		id, err := optionalId(returnData, field)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		*target = id
+	}
+
+	if reference, exists := returnData["purchase_order_reference"].(string); exists {
+		newReturn.PurchaseOrderReference = &reference
+	}
+	if notes, exists := returnData["notes"].(string); exists {
+		newReturn.Notes = &notes
+	}
+
+	lines := []schemas.ReturnLine{}
+	if linesData, exists := returnData["lines"]; exists {
+		lineList, ok := linesData.([]interface{})
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "lines must be an array",
+			})
+			return
+		}
+
+		for index, lineData := range lineList {
+			lineMap, ok := lineData.(map[string]interface{})
+			if !ok {
+				context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+					Success: false,
+					Message: fmt.Sprintf("Line %d must be an object", index+1),
+				})
+				return
+			}
+
+			line, err := parseReturnLine(lineMap)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+					Success: false,
+					Message: fmt.Sprintf("Line %d: %s", index+1, err.Error()),
+				})
+				return
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	ret, err := CreateReturn(newReturn, lines)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create return", "type", returnType, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating return", "type", returnType, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create return",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Return created successfully",
+		Data:    ret,
+	})
+}
+
+func UpdateReturnHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of return update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	ret, err := UpdateReturn(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update return", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating return", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update return",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return updated successfully",
+		Data:    ret,
+	})
+}
+
+func CancelReturnHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	ret, err := CancelReturn(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to cancel return", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when cancelling return", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to cancel return",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return cancelled successfully",
+		Data:    ret,
+	})
+}
+
+func AddReturnLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var lineData map[string]interface{}
+	if err := context.ShouldBindJSON(&lineData); err != nil {
+		slog.Error("Failed to parse JSON of new return line", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	line, err := parseReturnLine(lineData)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ret, err := AddReturnLine(id, line)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to add return line", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when adding return line", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to add return line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Return line added successfully",
+		Data:    ret,
+	})
+}
+
+func UpdateReturnLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lineId, err := getLineIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get line ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid line ID",
+		})
+		return
+	}
+
+	var lineData map[string]interface{}
+	if err := context.ShouldBindJSON(&lineData); err != nil {
+		slog.Error("Failed to parse JSON of return line update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	for _, field := range lineFields {
+		if value, exists := lineData[field]; exists {
+			updates[field] = value
+		}
+	}
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	if quantity, exists := updates["quantity"]; exists {
+		quantityValue, ok := quantity.(float64)
+		if !ok || quantityValue != float64(int(quantityValue)) || quantityValue <= 0 {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "quantity must be a whole number greater than 0",
+			})
+			return
+		}
+	}
+
+	ret, err := UpdateReturnLine(id, lineId, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update return line", "id", id, "line_id", lineId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating return line", "id", id, "line_id", lineId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update return line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return line updated successfully",
+		Data:    ret,
+	})
+}
+
+func DeleteReturnLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lineId, err := getLineIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get line ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid line ID",
+		})
+		return
+	}
+
+	ret, err := DeleteReturnLine(id, lineId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to remove return line", "id", id, "line_id", lineId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when removing return line", "id", id, "line_id", lineId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to remove return line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return line removed successfully",
+		Data:    ret,
+	})
+}
+
+// InspectReturnLineHandler records the outcome (restock, scrap or return_to_supplier) of a line
+func InspectReturnLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lineId, err := getLineIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get line ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid line ID",
+		})
+		return
+	}
+
+	var inspection schemas.ReturnInspection
+	if err := context.ShouldBindJSON(&inspection); err != nil {
+		slog.Error("Failed to parse JSON of return line inspection", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	ret, err := InspectReturnLine(id, lineId, inspection)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to inspect return line", "id", id, "line_id", lineId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when inspecting return line", "id", id, "line_id", lineId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to inspect return line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return line inspected successfully",
+		Data:    ret,
+	})
+}
+
+// CompleteReturnHandler completes the return and returns it with the recorded stock movements
+func CompleteReturnHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	completion, err := CompleteReturn(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to complete return", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when completing return", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to complete return",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Return completed successfully",
+		Data:    completion,
+	})
+}
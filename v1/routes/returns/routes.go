@@ -0,0 +1,21 @@
+package returns
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupReturnRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetReturnsHandler)
+	routes.GET("/:id", GetReturnHandler)
+
+	routes.PATCH("/:id", UpdateReturnHandler)
+	routes.POST("/", CreateReturnHandler)
+
+	routes.POST("/:id/lines", AddReturnLineHandler)
+	routes.PATCH("/:id/lines/:lineId", UpdateReturnLineHandler)
+	routes.DELETE("/:id/lines/:lineId", DeleteReturnLineHandler)
+	routes.POST("/:id/lines/:lineId/inspect", InspectReturnLineHandler)
+
+	routes.POST("/:id/complete", CompleteReturnHandler)
+	routes.POST("/:id/cancel", CancelReturnHandler)
+}
@@ -0,0 +1,652 @@
+package returns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// returnSelect embeds the customer or supplier and the lines with their items of a return
+const returnSelect = "*, customer:customers(id, name), supplier:suppliers(id, name), lines:return_lines(*, item:items(id, name, sku))"
+
+var returnTypes = map[string]struct{}{
+	schemas.ReturnTypeCustomer: {},
+	schemas.ReturnTypeSupplier: {},
+}
+
+var returnReasons = map[string]struct{}{
+	schemas.ReturnReasonDamaged:        {},
+	schemas.ReturnReasonDefective:      {},
+	schemas.ReturnReasonWrongItem:      {},
+	schemas.ReturnReasonNotAsDescribed: {},
+	schemas.ReturnReasonUnwanted:       {},
+	schemas.ReturnReasonExpired:        {},
+	schemas.ReturnReasonOther:          {},
+}
+
+var returnOutcomes = map[string]struct{}{
+	schemas.ReturnOutcomeRestock:          {},
+	schemas.ReturnOutcomeScrap:            {},
+	schemas.ReturnOutcomeReturnToSupplier: {},
+}
+
+// ValidateReturnReason returns a bad request error when the reason is unknown
+func ValidateReturnReason(reason string) error {
+	if _, valid := returnReasons[reason]; valid {
+		return nil
+	}
+
+	return &schemas.CustomError{
+		Code:    http.StatusBadRequest,
+		Message: fmt.Sprintf("Invalid return reason: %s", reason),
+		Details: fmt.Sprintf("Got unknown return reason %q", reason),
+	}
+}
+
+// GetReturns returns the returns, optionally filtered by type, status and sales order
+func GetReturns(returnType string, status string, salesOrderId *int8) ([]schemas.Return, error) {
+	client := db.Connect()
+
+	query := client.
+		From("returns").
+		Select("*, customer:customers(id, name), supplier:suppliers(id, name)", "", false)
+
+	if returnType != "" {
+		query = query.Eq("type", returnType)
+	}
+	if status != "" {
+		query = query.Eq("status", status)
+	}
+	if salesOrderId != nil {
+		query = query.Eq("sales_order_id", fmt.Sprintf("%d", *salesOrderId))
+	}
+
+	data, _, err := query.
+		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving returns"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving %q returns with status %q: %v", returnType, status, err),
+		}
+	}
+
+	var returns []schemas.Return
+	err = json.Unmarshal(data, &returns)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse return data",
+			Details: fmt.Sprintf("Error parsing return data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if returns == nil {
+		returns = []schemas.Return{}
+	}
+
+	return returns, nil
+}
+
+// GetReturn returns the return with its customer or supplier and its lines
+func GetReturn(id int8) (schemas.Return, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("returns").
+		Select(returnSelect, "", false).
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the return"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Return not found"
+			}
+		}
+
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving return with ID %d: %v", id, err),
+		}
+	}
+
+	var ret schemas.Return
+	err = json.Unmarshal(data, &ret)
+	if err != nil {
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse return data",
+			Details: fmt.Sprintf("Error parsing return data for ID %d: %v", id, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if ret.Lines == nil {
+		ret.Lines = []schemas.ReturnLine{}
+	}
+	slices.SortFunc(ret.Lines, func(a, b schemas.ReturnLine) int {
+		return int(a.Id) - int(b.Id)
+	})
+
+	return ret, nil
+}
+
+// checkReturnStatus returns a conflict error when the return does not have one of the statuses
+func checkReturnStatus(ret schemas.Return, action string, statuses ...string) error {
+	if slices.Contains(statuses, ret.Status) {
+		return nil
+	}
+
+	return &schemas.CustomError{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("Can not %s a return that is %s", action, ret.Status),
+		Details: fmt.Sprintf("Attempted to %s return %d with status %s", action, ret.Id, ret.Status),
+	}
+}
+
+// CreateReturn opens a return with the given lines.
+// A customer return needs a customer and a supplier return needs a supplier.
+func CreateReturn(ret schemas.Return, lines []schemas.ReturnLine) (schemas.Return, error) {
+	client := db.Connect()
+
+	if _, valid := returnTypes[ret.Type]; !valid {
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid return type: %s", ret.Type),
+			Details: fmt.Sprintf("Attempted to create a return with unknown type %q", ret.Type),
+		}
+	}
+	if err := ValidateReturnReason(ret.Reason); err != nil {
+		return schemas.Return{}, err
+	}
+
+	if ret.Type == schemas.ReturnTypeCustomer && (ret.CustomerId == nil || ret.SupplierId != nil || ret.PurchaseOrderReference != nil) {
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "A customer return needs a customer_id and can not have a supplier_id or purchase_order_reference",
+			Details: "Attempted to create a customer return with supplier fields or without a customer",
+		}
+	}
+	if ret.Type == schemas.ReturnTypeSupplier && (ret.SupplierId == nil || ret.CustomerId != nil || ret.SalesOrderId != nil) {
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "A supplier return needs a supplier_id and can not have a customer_id or sales_order_id",
+			Details: "Attempted to create a supplier return with customer fields or without a supplier",
+		}
+	}
+
+	data, _, err := client.
+		From("returns").
+		Insert(map[string]interface{}{
+			"type":                     ret.Type,
+			"status":                   schemas.ReturnStatusOpen,
+			"customer_id":              ret.CustomerId,
+			"sales_order_id":           ret.SalesOrderId,
+			"supplier_id":              ret.SupplierId,
+			"purchase_order_reference": ret.PurchaseOrderReference,
+			"reason":                   ret.Reason,
+			"notes":                    ret.Notes,
+			"created_at":               utils.GetCurrentISODate(),
+			"updated_at":               utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the return"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by check_return are written for the user
+			if code == http.StatusBadRequest || code == http.StatusNotFound {
+				message = utils.ParsePostgresError(err).Message
+			} else if code == http.StatusUnprocessableEntity {
+				message = "The customer, supplier or sales order does not exist"
+			}
+		}
+
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating %s return: %v", ret.Type, err),
+		}
+	}
+
+	var createdReturn schemas.Return
+	err = json.Unmarshal(data, &createdReturn)
+	if err != nil {
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse return data",
+			Details: fmt.Sprintf("Error parsing return data while creating return: %v", err),
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := insertReturnLine(createdReturn.Id, line); err != nil {
+			// The return has not been used yet, so we remove it again with the lines added so far
+			client.From("returns").Delete("", "").Eq("id", fmt.Sprintf("%d", createdReturn.Id)).Execute()
+			return schemas.Return{}, err
+		}
+	}
+
+	return GetReturn(createdReturn.Id)
+}
+
+// UpdateReturn updates the header of an open return
+func UpdateReturn(id int8, updates map[string]interface{}) (schemas.Return, error) {
+	client := db.Connect()
+
+	ret, err := GetReturn(id)
+	if err != nil {
+		return schemas.Return{}, err
+	}
+	if err := checkReturnStatus(ret, "update", schemas.ReturnStatusOpen); err != nil {
+		return schemas.Return{}, err
+	}
+
+	if reason, exists := updates["reason"]; exists {
+		reasonStr, _ := reason.(string)
+		if err := ValidateReturnReason(reasonStr); err != nil {
+			return schemas.Return{}, err
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	// The status filter makes sure the return was not completed in the meantime
+	_, _, err = client.
+		From("returns").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.ReturnStatusOpen).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the return"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				code = http.StatusConflict
+				message = "The return was changed in the meantime, try again"
+			} else if code == http.StatusBadRequest {
+				message = utils.ParsePostgresError(err).Message
+			}
+		}
+
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating return with ID %d: %v", id, err),
+		}
+	}
+
+	return GetReturn(id)
+}
+
+// CancelReturn cancels an open return, which frees the returned quantities of its sales order lines
+func CancelReturn(id int8) (schemas.Return, error) {
+	client := db.Connect()
+
+	ret, err := GetReturn(id)
+	if err != nil {
+		return schemas.Return{}, err
+	}
+	if err := checkReturnStatus(ret, "cancel", schemas.ReturnStatusOpen); err != nil {
+		return schemas.Return{}, err
+	}
+
+	// The status filter makes sure the return was not completed in the meantime
+	_, _, err = client.
+		From("returns").
+		Update(map[string]interface{}{
+			"status":       schemas.ReturnStatusCancelled,
+			"cancelled_at": utils.GetCurrentISODate(),
+			"updated_at":   utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.ReturnStatusOpen).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while trying to cancel the return"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				code = http.StatusConflict
+				message = "The return was changed in the meantime, try again"
+			}
+		}
+
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error cancelling return %d: %v", id, err),
+		}
+	}
+
+	return GetReturn(id)
+}
+
+// insertReturnLine adds a line to a return without checking its status
+func insertReturnLine(id int8, line schemas.ReturnLine) (schemas.ReturnLine, error) {
+	client := db.Connect()
+
+	if line.Reason != nil {
+		if err := ValidateReturnReason(*line.Reason); err != nil {
+			return schemas.ReturnLine{}, err
+		}
+	}
+
+	data, _, err := client.
+		From("return_lines").
+		Insert(map[string]interface{}{
+			"return_id":           id,
+			"item_id":             line.ItemId,
+			"lot_id":              line.LotId,
+			"quantity":            line.Quantity,
+			"sales_order_line_id": line.SalesOrderLineId,
+			"reason":              line.Reason,
+			"created_at":          utils.GetCurrentISODate(),
+			"updated_at":          utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while adding the return line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by check_return_line are written for the user
+			if code == http.StatusBadRequest || code == http.StatusNotFound {
+				message = utils.ParsePostgresError(err).Message
+			} else if code == http.StatusUnprocessableEntity {
+				message = "The item or lot does not exist"
+			}
+		}
+
+		return schemas.ReturnLine{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error adding item %d to return %d: %v", line.ItemId, id, err),
+		}
+	}
+
+	var createdLine schemas.ReturnLine
+	err = json.Unmarshal(data, &createdLine)
+	if err != nil {
+		return schemas.ReturnLine{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse return line data",
+			Details: fmt.Sprintf("Error parsing return line data while adding to return %d: %v", id, err),
+		}
+	}
+
+	return createdLine, nil
+}
+
+// AddReturnLine adds a line to an open return
+func AddReturnLine(id int8, line schemas.ReturnLine) (schemas.Return, error) {
+	ret, err := GetReturn(id)
+	if err != nil {
+		return schemas.Return{}, err
+	}
+	if err := checkReturnStatus(ret, "change the lines of", schemas.ReturnStatusOpen); err != nil {
+		return schemas.Return{}, err
+	}
+
+	if _, err := insertReturnLine(id, line); err != nil {
+		return schemas.Return{}, err
+	}
+
+	return GetReturn(id)
+}
+
+// updateReturnLine updates a line of an open return
+func updateReturnLine(id int8, lineId int64, updates map[string]interface{}, action string) (schemas.Return, error) {
+	client := db.Connect()
+
+	ret, err := GetReturn(id)
+	if err != nil {
+		return schemas.Return{}, err
+	}
+	if err := checkReturnStatus(ret, action, schemas.ReturnStatusOpen); err != nil {
+		return schemas.Return{}, err
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	_, _, err = client.
+		From("return_lines").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", lineId)).
+		Eq("return_id", fmt.Sprintf("%d", id)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the return line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Return line not found"
+			} else if code == http.StatusBadRequest {
+				message = utils.ParsePostgresError(err).Message
+			} else if code == http.StatusUnprocessableEntity {
+				message = "The item or lot does not exist"
+			}
+		}
+
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating line %d of return %d: %v", lineId, id, err),
+		}
+	}
+
+	return GetReturn(id)
+}
+
+// UpdateReturnLine updates the quantity, lot, reason or sales order line of a line of an open return
+func UpdateReturnLine(id int8, lineId int64, updates map[string]interface{}) (schemas.Return, error) {
+	if reason, exists := updates["reason"]; exists && reason != nil {
+		reasonStr, _ := reason.(string)
+		if err := ValidateReturnReason(reasonStr); err != nil {
+			return schemas.Return{}, err
+		}
+	}
+
+	return updateReturnLine(id, lineId, updates, "change the lines of")
+}
+
+// InspectReturnLine records the outcome of inspecting a line of an open return.
+// The stock only moves when the return is completed, so a line can be inspected again until then.
+func InspectReturnLine(id int8, lineId int64, inspection schemas.ReturnInspection) (schemas.Return, error) {
+	if _, valid := returnOutcomes[inspection.Outcome]; !valid {
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid inspection outcome: %s", inspection.Outcome),
+			Details: fmt.Sprintf("Attempted to inspect line %d of return %d with unknown outcome %q", lineId, id, inspection.Outcome),
+		}
+	}
+
+	return updateReturnLine(id, lineId, map[string]interface{}{
+		"outcome":         inspection.Outcome,
+		"inspection_note": inspection.Note,
+		"inspected_by":    inspection.InspectedBy,
+		"inspected_at":    utils.GetCurrentISODate(),
+	}, "inspect")
+}
+
+// DeleteReturnLine removes a line from an open return
+func DeleteReturnLine(id int8, lineId int64) (schemas.Return, error) {
+	client := db.Connect()
+
+	ret, err := GetReturn(id)
+	if err != nil {
+		return schemas.Return{}, err
+	}
+	if err := checkReturnStatus(ret, "change the lines of", schemas.ReturnStatusOpen); err != nil {
+		return schemas.Return{}, err
+	}
+
+	_, _, err = client.
+		From("return_lines").
+		Delete("", "").
+		Eq("id", fmt.Sprintf("%d", lineId)).
+		Eq("return_id", fmt.Sprintf("%d", id)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the return line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Return line not found"
+			}
+		}
+
+		return schemas.Return{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting line %d of return %d: %v", lineId, id, err),
+		}
+	}
+
+	return GetReturn(id)
+}
+
+// CompleteReturn applies the stock movements of the inspection outcomes and completes the return.
+// Every line must be inspected first.
+func CompleteReturn(id int8) (schemas.ReturnCompletion, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "complete_return", map[string]interface{}{
+		"return_id": id,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while completing the return"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by complete_return and apply_stock_movements are written for the user
+			if code == http.StatusBadRequest || code == http.StatusNotFound {
+				message = utils.ParsePostgresError(err).Message
+			}
+		}
+
+		return schemas.ReturnCompletion{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error completing return %d: %v", id, err),
+		}
+	}
+
+	var completion schemas.ReturnCompletion
+	err = json.Unmarshal(data, &completion)
+	if err != nil {
+		return schemas.ReturnCompletion{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse return data",
+			Details: fmt.Sprintf("Error parsing the completion of return %d: %v", id, err),
+		}
+	}
+
+	completedReturn, err := GetReturn(id)
+	if err != nil {
+		return schemas.ReturnCompletion{}, err
+	}
+	completion.Return = completedReturn
+
+	// We make sure that an empty array is returned instead of null
+	if completion.Movements == nil {
+		completion.Movements = []schemas.StockMovement{}
+	}
+
+	return completion, nil
+}
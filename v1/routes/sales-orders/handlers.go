@@ -0,0 +1,622 @@
+package salesorders
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify.
+// The status and its timestamps only change through confirm, pick, ship and cancel.
+var protectedFields = []string{
+	"id", "status", "confirmed_at", "picked_at", "shipped_at", "cancelled_at",
+	"created_at", "updated_at", "customer", "lines", "total_minor",
+}
+
+// lineFields contains the fields of a line that can be updated
+var lineFields = []string{"quantity", "unit_price_minor", "lot_id", "serial_numbers"}
+
+// parseSalesOrderLine reads a line from the JSON body. The unit price defaults to 0.
+func parseSalesOrderLine(lineData map[string]interface{}) (schemas.SalesOrderLine, error) {
+	if err := utils.CheckRequiredFields(lineData, []string{"item_id", "quantity"}); err != nil {
+		return schemas.SalesOrderLine{}, err
+	}
+
+	itemId, ok := lineData["item_id"].(float64)
+	if !ok || itemId != float64(int8(itemId)) {
+		return schemas.SalesOrderLine{}, fmt.Errorf("item_id must be a valid ID")
+	}
+
+	line := schemas.SalesOrderLine{ItemId: int8(itemId)}
+	if err := validateLineFields(lineData); err != nil {
+		return schemas.SalesOrderLine{}, err
+	}
+
+	line.Quantity = int(lineData["quantity"].(float64))
+	if unitPrice, exists := lineData["unit_price_minor"].(float64); exists {
+		line.UnitPriceMinor = int64(unitPrice)
+	}
+
+	if lotId, valid := utils.ParseJSONId(lineData["lot_id"]); valid {
+		line.LotId = &lotId
+	}
+
+	if serialNumbers, exists := lineData["serial_numbers"].([]interface{}); exists {
+		for _, serialNumber := range serialNumbers {
+			line.SerialNumbers = append(line.SerialNumbers, serialNumber.(string))
+		}
+	}
+
+	return line, nil
+}
+
+// validateLineFields checks the quantity, unit price, lot and serial numbers of a line when they are set
+func validateLineFields(lineData map[string]interface{}) error {
+	if quantity, exists := lineData["quantity"]; exists {
+		quantityValue, ok := quantity.(float64)
+		if !ok || quantityValue != float64(int(quantityValue)) || quantityValue <= 0 {
+			return fmt.Errorf("quantity must be a whole number greater than 0")
+		}
+	}
+
+	if unitPrice, exists := lineData["unit_price_minor"]; exists {
+		unitPriceValue, ok := unitPrice.(float64)
+		if !ok || unitPriceValue != float64(int64(unitPriceValue)) || unitPriceValue < 0 {
+			return fmt.Errorf("unit_price_minor must be a whole number of at least 0")
+		}
+	}
+
+	if lotId, exists := lineData["lot_id"]; exists && lotId != nil {
+		if _, ok := utils.ParseJSONId(lotId); !ok {
+			return fmt.Errorf("lot_id must be a valid ID")
+		}
+	}
+
+	if serialNumbers, exists := lineData["serial_numbers"]; exists && serialNumbers != nil {
+		values, ok := serialNumbers.([]interface{})
+		if !ok {
+			return fmt.Errorf("serial_numbers must be an array of serial numbers")
+		}
+
+		seen := map[string]bool{}
+		for _, value := range values {
+			serialNumber, ok := value.(string)
+			if !ok || strings.TrimSpace(serialNumber) == "" {
+				return fmt.Errorf("serial_numbers must be an array of serial numbers")
+			}
+			if seen[serialNumber] {
+				return fmt.Errorf("serial number %s is on the line more than once", serialNumber)
+			}
+			seen[serialNumber] = true
+		}
+	}
+
+	return nil
+}
+
+// getLineIdFromContext returns the line ID of the path
+func getLineIdFromContext(context *gin.Context) (int64, error) {
+	return strconv.ParseInt(context.Param("lineId"), 10, 64)
+}
+
+// GetSalesOrdersHandler returns the sales orders, filtered by status and customer-id
+func GetSalesOrdersHandler(context *gin.Context) {
+	status := context.Query("status")
+
+	var customerId *int8
+	if customerIdStr := context.Query("customer-id"); customerIdStr != "" {
+		parsedId, err := strconv.ParseInt(customerIdStr, 10, 8)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid customer ID",
+			})
+			return
+		}
+		id := int8(parsedId)
+		customerId = &id
+	}
+
+	orders, err := GetSalesOrders(status, customerId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve sales orders", "status", status, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving sales orders", "status", status, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve sales orders",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales orders retrieved successfully",
+		Data:    orders,
+	})
+}
+
+func GetSalesOrderHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve sales order", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving sales order", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve sales order",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order retrieved successfully",
+		Data:    order,
+	})
+}
+
+// CreateSalesOrderHandler creates a draft order, optionally with its lines
+func CreateSalesOrderHandler(context *gin.Context) {
+	var orderData map[string]interface{}
+	if err := context.ShouldBindJSON(&orderData); err != nil {
+		slog.Error("Failed to parse JSON of new sales order", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(orderData, []string{"customer_id"})
+	if err != nil {
+		slog.Error("Missing required fields in sales order data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	customerId, ok := orderData["customer_id"].(float64)
+	if !ok || customerId != float64(int8(customerId)) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "customer_id must be a valid ID",
+		})
+		return
+	}
+
+	newOrder := schemas.SalesOrder{CustomerId: int8(customerId)}
+
+	// Without a currency the order is in the base currency
+	if currency, exists := orderData["currency"].(string); exists {
+		newOrder.Currency = currency
+	}
+	if reference, exists := orderData["reference"].(string); exists {
+		newOrder.Reference = &reference
+	}
+	if notes, exists := orderData["notes"].(string); exists {
+		newOrder.Notes = &notes
+	}
+
+	lines := []schemas.SalesOrderLine{}
+	if linesData, exists := orderData["lines"]; exists {
+		lineList, ok := linesData.([]interface{})
+		if !ok {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "lines must be an array",
+			})
+			return
+		}
+
+		for index, lineData := range lineList {
+			lineMap, ok := lineData.(map[string]interface{})
+			if !ok {
+				context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+					Success: false,
+					Message: fmt.Sprintf("Line %d must be an object", index+1),
+				})
+				return
+			}
+
+			line, err := parseSalesOrderLine(lineMap)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+					Success: false,
+					Message: fmt.Sprintf("Line %d: %s", index+1, err.Error()),
+				})
+				return
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	order, err := CreateSalesOrder(newOrder, lines)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create sales order", "customer_id", newOrder.CustomerId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating sales order", "customer_id", newOrder.CustomerId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create sales order",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order created successfully",
+		Data:    order,
+	})
+}
+
+func UpdateSalesOrderHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of sales order update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	order, err := UpdateSalesOrder(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update sales order", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating sales order", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update sales order",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order updated successfully",
+		Data:    order,
+	})
+}
+
+func DeleteSalesOrderHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteSalesOrder(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete sales order", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when deleting sales order", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete sales order",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order deleted successfully",
+	})
+}
+
+func AddSalesOrderLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var lineData map[string]interface{}
+	if err := context.ShouldBindJSON(&lineData); err != nil {
+		slog.Error("Failed to parse JSON of new sales order line", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	line, err := parseSalesOrderLine(lineData)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	order, err := AddSalesOrderLine(id, line)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to add sales order line", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when adding sales order line", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to add sales order line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order line added successfully",
+		Data:    order,
+	})
+}
+
+func UpdateSalesOrderLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lineId, err := getLineIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get line ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid line ID",
+		})
+		return
+	}
+
+	var lineData map[string]interface{}
+	if err := context.ShouldBindJSON(&lineData); err != nil {
+		slog.Error("Failed to parse JSON of sales order line update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	// Only the quantity, unit price, lot and serial numbers can change, another item is a new line
+	updates := map[string]interface{}{}
+	for _, field := range lineFields {
+		if value, exists := lineData[field]; exists {
+			updates[field] = value
+		}
+	}
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	if err := validateLineFields(updates); err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	order, err := UpdateSalesOrderLine(id, lineId, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update sales order line", "id", id, "line_id", lineId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating sales order line", "id", id, "line_id", lineId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update sales order line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order line updated successfully",
+		Data:    order,
+	})
+}
+
+func DeleteSalesOrderLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lineId, err := getLineIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get line ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid line ID",
+		})
+		return
+	}
+
+	order, err := DeleteSalesOrderLine(id, lineId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to remove sales order line", "id", id, "line_id", lineId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when removing sales order line", "id", id, "line_id", lineId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to remove sales order line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Sales order line removed successfully",
+		Data:    order,
+	})
+}
+
+// transitionSalesOrderHandler returns a handler that moves the order to its next status
+func transitionSalesOrderHandler(transitionFunc func(id int8) (schemas.SalesOrder, error), name string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id, err := utils.GetIdFromContext(context)
+		if err != nil {
+			slog.Error("Failed to get ID from context", "error", err)
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid ID",
+			})
+			return
+		}
+
+		order, err := transitionFunc(id)
+		if err != nil {
+			if utils.IsCustomError(err) {
+				customErr := err.(*schemas.CustomError)
+				slog.Error("Failed to "+name+" sales order", "id", id, "error", customErr.Details)
+				context.JSON(customErr.Code, schemas.ApiResponse{
+					Success: false,
+					Message: customErr.Message,
+				})
+				return
+			}
+
+			slog.Error("Unexpected error when trying to "+name+" sales order", "id", id, "error", err)
+			context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+				Success: false,
+				Message: "Failed to " + name + " sales order",
+			})
+			return
+		}
+
+		context.JSON(http.StatusOK, schemas.ApiResponse{
+			Success: true,
+			Message: "Sales order " + order.Status + " successfully",
+			Data:    order,
+		})
+	}
+}
+
+var ConfirmSalesOrderHandler = transitionSalesOrderHandler(ConfirmSalesOrder, "confirm")
+
+var PickSalesOrderHandler = transitionSalesOrderHandler(PickSalesOrder, "pick")
+
+var ShipSalesOrderHandler = transitionSalesOrderHandler(ShipSalesOrder, "ship")
+
+var CancelSalesOrderHandler = transitionSalesOrderHandler(CancelSalesOrder, "cancel")
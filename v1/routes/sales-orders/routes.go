@@ -0,0 +1,23 @@
+package salesorders
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSalesOrderRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetSalesOrdersHandler)
+	routes.GET("/:id", GetSalesOrderHandler)
+
+	routes.PATCH("/:id", UpdateSalesOrderHandler)
+	routes.POST("/", CreateSalesOrderHandler)
+	routes.DELETE("/:id", DeleteSalesOrderHandler)
+
+	routes.POST("/:id/lines", AddSalesOrderLineHandler)
+	routes.PATCH("/:id/lines/:lineId", UpdateSalesOrderLineHandler)
+	routes.DELETE("/:id/lines/:lineId", DeleteSalesOrderLineHandler)
+
+	routes.POST("/:id/confirm", ConfirmSalesOrderHandler)
+	routes.POST("/:id/pick", PickSalesOrderHandler)
+	routes.POST("/:id/ship", ShipSalesOrderHandler)
+	routes.POST("/:id/cancel", CancelSalesOrderHandler)
+}
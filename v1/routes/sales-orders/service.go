@@ -0,0 +1,610 @@
+package salesorders
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// orderSelect embeds the customer and the lines with their items of an order
+const orderSelect = "*, customer:customers(id, name), lines:sales_order_lines(*, item:items(id, name, sku))"
+
+// GetSalesOrders returns the sales orders, optionally filtered by status and customer
+func GetSalesOrders(status string, customerId *int8) ([]schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	query := client.
+		From("sales_orders").
+		Select("*, customer:customers(id, name)", "", false)
+
+	if status != "" {
+		query = query.Eq("status", status)
+	}
+	if customerId != nil {
+		query = query.Eq("customer_id", fmt.Sprintf("%d", *customerId))
+	}
+
+	data, _, err := query.
+		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving sales orders"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving sales orders with status %q: %v", status, err),
+		}
+	}
+
+	var orders []schemas.SalesOrder
+	err = json.Unmarshal(data, &orders)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse sales order data",
+			Details: fmt.Sprintf("Error parsing sales order data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if orders == nil {
+		orders = []schemas.SalesOrder{}
+	}
+
+	return orders, nil
+}
+
+// GetSalesOrder returns the order with its customer, lines and total
+func GetSalesOrder(id int8) (schemas.SalesOrder, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("sales_orders").
+		Select(orderSelect, "", false).
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the sales order"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Sales order not found"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving sales order with ID %d: %v", id, err),
+		}
+	}
+
+	var order schemas.SalesOrder
+	err = json.Unmarshal(data, &order)
+	if err != nil {
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse sales order data",
+			Details: fmt.Sprintf("Error parsing sales order data for ID %d: %v", id, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if order.Lines == nil {
+		order.Lines = []schemas.SalesOrderLine{}
+	}
+	slices.SortFunc(order.Lines, func(a, b schemas.SalesOrderLine) int {
+		return int(a.Id) - int(b.Id)
+	})
+
+	var total int64
+	for _, line := range order.Lines {
+		total += int64(line.Quantity) * line.UnitPriceMinor
+	}
+	order.TotalMinor = &total
+
+	return order, nil
+}
+
+// checkSalesOrderStatus returns a conflict error when the order does not have one of the statuses
+func checkSalesOrderStatus(order schemas.SalesOrder, action string, statuses ...string) error {
+	if slices.Contains(statuses, order.Status) {
+		return nil
+	}
+
+	return &schemas.CustomError{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("Can not %s a sales order that is %s", action, order.Status),
+		Details: fmt.Sprintf("Attempted to %s sales order %d with status %s", action, order.Id, order.Status),
+	}
+}
+
+// CreateSalesOrder creates a draft order with the given lines
+func CreateSalesOrder(order schemas.SalesOrder, lines []schemas.SalesOrderLine) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	order.Currency = strings.ToUpper(strings.TrimSpace(order.Currency))
+	if order.Currency == "" {
+		order.Currency = utils.GetBaseCurrency()
+	}
+
+	data, _, err := client.
+		From("sales_orders").
+		Insert(map[string]interface{}{
+			"customer_id": order.CustomerId,
+			"status":      schemas.SalesOrderStatusDraft,
+			"currency":    order.Currency,
+			"reference":   order.Reference,
+			"notes":       order.Notes,
+			"created_at":  utils.GetCurrentISODate(),
+			"updated_at":  utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the sales order"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusUnprocessableEntity {
+				message = "The customer or currency does not exist"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating sales order for customer %d: %v", order.CustomerId, err),
+		}
+	}
+
+	var createdOrder schemas.SalesOrder
+	err = json.Unmarshal(data, &createdOrder)
+	if err != nil {
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse sales order data",
+			Details: fmt.Sprintf("Error parsing sales order data while creating sales order: %v", err),
+		}
+	}
+
+	if len(lines) > 0 {
+		rows := make([]map[string]interface{}, 0, len(lines))
+		for _, line := range lines {
+			rows = append(rows, map[string]interface{}{
+				"order_id":         createdOrder.Id,
+				"item_id":          line.ItemId,
+				"quantity":         line.Quantity,
+				"unit_price_minor": line.UnitPriceMinor,
+				"lot_id":           line.LotId,
+				"serial_numbers":   line.SerialNumbers,
+				"created_at":       utils.GetCurrentISODate(),
+				"updated_at":       utils.GetCurrentISODate(),
+			})
+		}
+
+		_, _, err = client.
+			From("sales_order_lines").
+			Insert(rows, false, "", "", "").
+			Execute()
+
+		if err != nil {
+			// The order is still a draft without lines, so we remove it again
+			client.From("sales_orders").Delete("", "").Eq("id", fmt.Sprintf("%d", createdOrder.Id)).Execute()
+
+			// Set the default error code and message
+			code := http.StatusInternalServerError
+			message := "An error occurred while adding the sales order lines"
+
+			// Check if the error is a Postgres error
+			// If true we update the code and message accordingly
+			if status := utils.PostgresToHTTPError(err); status != nil {
+				code = *status
+				message = utils.PostgresErrorMessage(err, message)
+
+				if code == http.StatusConflict {
+					message = "An item can only be on one line of the order"
+				} else if code == http.StatusUnprocessableEntity {
+					message = "One of the items does not exist"
+				}
+			}
+
+			return schemas.SalesOrder{}, &schemas.CustomError{
+				Code:    code,
+				Message: message,
+				Details: fmt.Sprintf("Error adding %d lines to sales order %d: %v", len(lines), createdOrder.Id, err),
+			}
+		}
+	}
+
+	return GetSalesOrder(createdOrder.Id)
+}
+
+// UpdateSalesOrder updates the header of a draft order
+func UpdateSalesOrder(id int8, updates map[string]interface{}) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		return schemas.SalesOrder{}, err
+	}
+	if err := checkSalesOrderStatus(order, "update", schemas.SalesOrderStatusDraft); err != nil {
+		return schemas.SalesOrder{}, err
+	}
+
+	if currency, exists := updates["currency"].(string); exists {
+		updates["currency"] = strings.ToUpper(strings.TrimSpace(currency))
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	// The status filter makes sure the order was not confirmed in the meantime
+	_, _, err = client.
+		From("sales_orders").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.SalesOrderStatusDraft).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the sales order"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				code = http.StatusConflict
+				message = "The sales order was changed in the meantime, try again"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "The customer or currency does not exist"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating sales order with ID %d: %v", id, err),
+		}
+	}
+
+	return GetSalesOrder(id)
+}
+
+// DeleteSalesOrder deletes a draft order and its lines
+func DeleteSalesOrder(id int8) error {
+	client := db.Connect()
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		return err
+	}
+	if err := checkSalesOrderStatus(order, "delete", schemas.SalesOrderStatusDraft); err != nil {
+		return err
+	}
+
+	_, _, err = client.
+		From("sales_orders").
+		Delete("", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.SalesOrderStatusDraft).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the sales order"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Sales order not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting sales order with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+// AddSalesOrderLine adds a line to a draft order
+func AddSalesOrderLine(id int8, line schemas.SalesOrderLine) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		return schemas.SalesOrder{}, err
+	}
+	if err := checkSalesOrderStatus(order, "change the lines of", schemas.SalesOrderStatusDraft); err != nil {
+		return schemas.SalesOrder{}, err
+	}
+
+	_, _, err = client.
+		From("sales_order_lines").
+		Insert(map[string]interface{}{
+			"order_id":         id,
+			"item_id":          line.ItemId,
+			"quantity":         line.Quantity,
+			"unit_price_minor": line.UnitPriceMinor,
+			"lot_id":           line.LotId,
+			"serial_numbers":   line.SerialNumbers,
+			"created_at":       utils.GetCurrentISODate(),
+			"updated_at":       utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while adding the sales order line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = "The item is already on the order"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Item not found"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error adding item %d to sales order %d: %v", line.ItemId, id, err),
+		}
+	}
+
+	return GetSalesOrder(id)
+}
+
+// UpdateSalesOrderLine updates the quantity, price, lot or serial numbers of a line of a draft order
+func UpdateSalesOrderLine(id int8, lineId int64, updates map[string]interface{}) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		return schemas.SalesOrder{}, err
+	}
+	if err := checkSalesOrderStatus(order, "change the lines of", schemas.SalesOrderStatusDraft); err != nil {
+		return schemas.SalesOrder{}, err
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	_, _, err = client.
+		From("sales_order_lines").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", lineId)).
+		Eq("order_id", fmt.Sprintf("%d", id)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the sales order line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Sales order line not found"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating line %d of sales order %d: %v", lineId, id, err),
+		}
+	}
+
+	return GetSalesOrder(id)
+}
+
+// DeleteSalesOrderLine removes a line from a draft order
+func DeleteSalesOrderLine(id int8, lineId int64) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		return schemas.SalesOrder{}, err
+	}
+	if err := checkSalesOrderStatus(order, "change the lines of", schemas.SalesOrderStatusDraft); err != nil {
+		return schemas.SalesOrder{}, err
+	}
+
+	_, _, err = client.
+		From("sales_order_lines").
+		Delete("", "").
+		Eq("id", fmt.Sprintf("%d", lineId)).
+		Eq("order_id", fmt.Sprintf("%d", id)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the sales order line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Sales order line not found"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting line %d of sales order %d: %v", lineId, id, err),
+		}
+	}
+
+	return GetSalesOrder(id)
+}
+
+// transitionSalesOrder calls the Postgres function that moves the order to its next status.
+// The functions lock the order, so concurrent transitions can not reserve or issue the stock twice.
+func transitionSalesOrder(id int8, function string, action string) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	_, err := db.Rpc(client, function, map[string]interface{}{
+		"order_id": id,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := fmt.Sprintf("An error occurred while trying to %s the sales order", action)
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by the order, reservation and stock functions are written for the user
+			if code == http.StatusBadRequest || code == http.StatusNotFound {
+				message = utils.ParsePostgresError(err).Message
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error trying to %s sales order %d: %v", action, id, err),
+		}
+	}
+
+	return GetSalesOrder(id)
+}
+
+// ConfirmSalesOrder reserves the stock of every line of a draft order
+func ConfirmSalesOrder(id int8) (schemas.SalesOrder, error) {
+	return transitionSalesOrder(id, "confirm_sales_order", "confirm")
+}
+
+// ShipSalesOrder fulfills the reservations and issues the stock of a picked order
+func ShipSalesOrder(id int8) (schemas.SalesOrder, error) {
+	return transitionSalesOrder(id, "ship_sales_order", "ship")
+}
+
+// CancelSalesOrder releases the reservations of an order that has not been shipped
+func CancelSalesOrder(id int8) (schemas.SalesOrder, error) {
+	return transitionSalesOrder(id, "cancel_sales_order", "cancel")
+}
+
+// PickSalesOrder marks a confirmed order as picked. The stock stays reserved until the order ships.
+func PickSalesOrder(id int8) (schemas.SalesOrder, error) {
+	client := db.Connect()
+
+	order, err := GetSalesOrder(id)
+	if err != nil {
+		return schemas.SalesOrder{}, err
+	}
+	if err := checkSalesOrderStatus(order, "pick", schemas.SalesOrderStatusConfirmed); err != nil {
+		return schemas.SalesOrder{}, err
+	}
+
+	// The status filter makes sure the order was not cancelled in the meantime
+	_, _, err = client.
+		From("sales_orders").
+		Update(map[string]interface{}{
+			"status":     schemas.SalesOrderStatusPicked,
+			"picked_at":  utils.GetCurrentISODate(),
+			"updated_at": utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", schemas.SalesOrderStatusConfirmed).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while trying to pick the sales order"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				code = http.StatusConflict
+				message = "The sales order was changed in the meantime, try again"
+			}
+		}
+
+		return schemas.SalesOrder{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error picking sales order %d: %v", id, err),
+		}
+	}
+
+	return GetSalesOrder(id)
+}
@@ -0,0 +1,20 @@
+package serials
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSerialRoutes(routes *gin.RouterGroup) {
+	routes.GET("", FindSerialsHandler)
+	routes.GET("/:id", GetSerialHandler)
+	routes.GET("/:id/history", GetSerialHistoryHandler)
+
+	routes.PATCH("/:id", UpdateSerialHandler)
+}
+
+// SetupItemSerialRoutes sets up the serial routes nested below an item
+func SetupItemSerialRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetItemSerialsHandler)
+
+	routes.POST("/", CreateItemSerialHandler)
+}
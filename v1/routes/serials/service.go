@@ -0,0 +1,414 @@
+package serials
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/items"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+var serialStatuses = map[string]struct{}{
+	schemas.SerialStatusInStock:  {},
+	schemas.SerialStatusIssued:   {},
+	schemas.SerialStatusInRepair: {},
+	schemas.SerialStatusRetired:  {},
+}
+
+// validateStatus checks that the status is one of the known serial statuses
+func validateStatus(status string) error {
+	if _, valid := serialStatuses[status]; !valid {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid status: %s, expected in_stock, issued, in_repair or retired", status),
+			Details: fmt.Sprintf("Unknown serial status %q", status),
+		}
+	}
+
+	return nil
+}
+
+func GetItemSerial(id int64) (schemas.ItemSerial, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("item_serials").
+		Select("*", "", false).
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the serial"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Serial not found"
+			}
+		}
+
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving serial with ID %d: %v", id, err),
+		}
+	}
+
+	var serial schemas.ItemSerial
+	err = json.Unmarshal(data, &serial)
+	if err != nil {
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse serial data",
+			Details: fmt.Sprintf("Error parsing serial data for ID %d: %v", id, err),
+		}
+	}
+
+	history, err := GetItemSerialHistory(id)
+	if err != nil {
+		return schemas.ItemSerial{}, err
+	}
+	serial.History = history
+
+	return serial, nil
+}
+
+// GetItemSerialHistory returns the status and location changes of the serial, oldest first
+func GetItemSerialHistory(id int64) ([]schemas.ItemSerialEvent, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("item_serial_events").
+		Select("*", "", false).
+		Eq("serial_id", idStr).
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the serial history"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving history of serial with ID %d: %v", id, err),
+		}
+	}
+
+	var history []schemas.ItemSerialEvent
+	err = json.Unmarshal(data, &history)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse serial history data",
+			Details: fmt.Sprintf("Error parsing history data for serial ID %d: %v", id, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if history == nil {
+		history = []schemas.ItemSerialEvent{}
+	}
+
+	return history, nil
+}
+
+// GetItemSerials returns the serials of the item, optionally only those with the given status
+func GetItemSerials(itemId int8, status string) ([]schemas.ItemSerial, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", itemId)
+
+	query := client.
+		From("item_serials").
+		Select("*", "", false).
+		Eq("item_id", idStr)
+
+	if status != "" {
+		if err := validateStatus(status); err != nil {
+			return nil, err
+		}
+		query = query.Eq("status", status)
+	}
+
+	data, _, err := query.
+		Order("serial_number", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the item serials"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving serials for item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var serials []schemas.ItemSerial
+	err = json.Unmarshal(data, &serials)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse serial data",
+			Details: fmt.Sprintf("Error parsing serial data for item ID %d: %v", itemId, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if serials == nil {
+		serials = []schemas.ItemSerial{}
+	}
+
+	return serials, nil
+}
+
+// FindSerials looks up serials across all items by serial number and status.
+// Empty arguments are not filtered on.
+func FindSerials(serialNumber string, status string) ([]schemas.ItemSerial, error) {
+	client := db.Connect()
+
+	query := client.
+		From("item_serials").
+		Select("*, item:items(id, name, sku)", "", false)
+
+	if serialNumber != "" {
+		query = query.Eq("serial_number", strings.TrimSpace(serialNumber))
+	}
+
+	if status != "" {
+		if err := validateStatus(status); err != nil {
+			return nil, err
+		}
+		query = query.Eq("status", status)
+	}
+
+	data, _, err := query.
+		Order("serial_number", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving serials"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving serials with number %q and status %q: %v", serialNumber, status, err),
+		}
+	}
+
+	var serials []schemas.ItemSerial
+	err = json.Unmarshal(data, &serials)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse serial data",
+			Details: fmt.Sprintf("Error parsing serial data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if serials == nil {
+		serials = []schemas.ItemSerial{}
+	}
+
+	return serials, nil
+}
+
+// CreateItemSerial registers a new unit of a serialised item
+func CreateItemSerial(itemId int8, serial schemas.ItemSerial) (schemas.ItemSerial, error) {
+	client := db.Connect()
+
+	item, err := items.GetItem(itemId)
+	if err != nil {
+		return schemas.ItemSerial{}, err
+	}
+
+	if !item.Serialized {
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "The item is not serialised",
+			Details: fmt.Sprintf("Attempted to add serial %q to item %d, which is not serialised", serial.SerialNumber, itemId),
+		}
+	}
+
+	if serial.Status == "" {
+		serial.Status = schemas.SerialStatusInStock
+	}
+	if err := validateStatus(serial.Status); err != nil {
+		return schemas.ItemSerial{}, err
+	}
+
+	serial.SerialNumber = strings.TrimSpace(serial.SerialNumber)
+
+	data, _, err := client.
+		From("item_serials").
+		Insert(map[string]interface{}{
+			"item_id":       itemId,
+			"serial_number": serial.SerialNumber,
+			"status":        serial.Status,
+			"location":      serial.Location,
+			"note":          serial.Note,
+			"created_at":    utils.GetCurrentISODate(),
+			"updated_at":    utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the serial"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = fmt.Sprintf("Serial %s already exists for this item", serial.SerialNumber)
+			}
+		}
+
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating serial for item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var createdSerial schemas.ItemSerial
+	err = json.Unmarshal(data, &createdSerial)
+	if err != nil {
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse serial data",
+			Details: fmt.Sprintf("Error parsing serial data while creating serial for item %d: %v", itemId, err),
+		}
+	}
+
+	return createdSerial, nil
+}
+
+// UpdateItemSerial changes the status, location or note of a serial.
+// The change is recorded in the serial history by the database. Retired serials can not be changed.
+func UpdateItemSerial(id int64, updates map[string]interface{}) (schemas.ItemSerial, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	current, err := GetItemSerial(id)
+	if err != nil {
+		return schemas.ItemSerial{}, err
+	}
+
+	if current.Status == schemas.SerialStatusRetired {
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Retired serials can not be changed",
+			Details: fmt.Sprintf("Attempted to update retired serial %d", id),
+		}
+	}
+
+	if status, exists := updates["status"]; exists {
+		statusStr, ok := status.(string)
+		if !ok {
+			return schemas.ItemSerial{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid status",
+				Details: fmt.Sprintf("Expected a string for status of serial %d, got %v", id, status),
+			}
+		}
+
+		if err := validateStatus(statusStr); err != nil {
+			return schemas.ItemSerial{}, err
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("item_serials").
+		Update(updates, "", "").
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the serial"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Serial not found"
+			} else if code == http.StatusConflict {
+				message = "A serial with this number already exists for this item"
+			}
+		}
+
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating serial with ID %d: %v", id, err),
+		}
+	}
+
+	var updatedSerial schemas.ItemSerial
+	err = json.Unmarshal(data, &updatedSerial)
+	if err != nil {
+		return schemas.ItemSerial{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse serial data",
+			Details: fmt.Sprintf("Error parsing serial data for ID %d: %v", id, err),
+		}
+	}
+
+	return updatedSerial, nil
+}
@@ -0,0 +1,548 @@
+package stocktakes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+func GetStocktakesHandler(context *gin.Context) {
+	status := context.Query("status")
+
+	stocktakes, err := GetStocktakes(status)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve stocktakes", "status", status, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving stocktakes", "status", status, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve stocktakes",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Stocktakes retrieved successfully",
+		Data:    stocktakes,
+	})
+}
+
+func GetStocktakeHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	stocktake, err := GetStocktake(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve stocktake", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving stocktake", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve stocktake",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Stocktake retrieved successfully",
+		Data:    stocktake,
+	})
+}
+
+func CreateStocktakeHandler(context *gin.Context) {
+	var stocktakeData map[string]interface{}
+	if err := context.ShouldBindJSON(&stocktakeData); err != nil {
+		slog.Error("Failed to parse JSON of new stocktake", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(stocktakeData, []string{"name"})
+	if err != nil {
+		slog.Error("Missing required fields in stocktake data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	name, ok := stocktakeData["name"].(string)
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "name must be a string",
+		})
+		return
+	}
+
+	newStocktake := schemas.Stocktake{Name: name}
+
+	if scope, exists := stocktakeData["scope"].(string); exists {
+		newStocktake.Scope = scope
+	}
+
+	if location, exists := stocktakeData["location"].(string); exists {
+		newStocktake.Location = &location
+	}
+
+	if categoryId, exists := stocktakeData["category_id"].(float64); exists {
+		categoryIdInt := int8(categoryId)
+		newStocktake.CategoryId = &categoryIdInt
+	}
+
+	if note, exists := stocktakeData["note"].(string); exists {
+		newStocktake.Note = &note
+	}
+
+	stocktake, err := CreateStocktake(newStocktake)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create stocktake", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating stocktake", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create stocktake",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Stocktake created successfully",
+		Data:    stocktake,
+	})
+}
+
+// GetStocktakeLinesHandler returns the lines for the variance review.
+// The lines can be filtered by status, and only-variances=true leaves out the lines without a variance.
+func GetStocktakeLinesHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	status := context.Query("status")
+	onlyVariances := context.Query("only-variances") == "true"
+
+	lines, err := GetStocktakeLines(id, status, onlyVariances)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve stocktake lines", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving stocktake lines", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve stocktake lines",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Stocktake lines retrieved successfully",
+		Data:    lines,
+	})
+}
+
+// countSheetCSV writes the count sheet with an empty column for the counted quantity.
+// The expected quantity is left out, so the counters count blind.
+func countSheetCSV(lines []schemas.StocktakeLine) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{{"line_id", "location", "sku", "item", "lot_number", "expiry_date", "unit", "counted_quantity"}}
+	for _, line := range lines {
+		var location, sku, name, unit, lotNumber, expiryDate string
+		if line.Item != nil {
+			if line.Item.Location != nil {
+				location = *line.Item.Location
+			}
+			if line.Item.Sku != nil {
+				sku = *line.Item.Sku
+			}
+			name = line.Item.Name
+			unit = line.Item.BaseUnit
+		}
+		if line.Lot != nil {
+			lotNumber = line.Lot.LotNumber
+			if line.Lot.ExpiryDate != nil {
+				expiryDate = *line.Lot.ExpiryDate
+			}
+		}
+
+		rows = append(rows, []string{strconv.Itoa(int(line.Id)), location, sku, name, lotNumber, expiryDate, unit, ""})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// GetStocktakeSheetHandler returns the count sheet, in count order, as JSON or CSV
+func GetStocktakeSheetHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	format := context.DefaultQuery("format", FormatJSON)
+	if format != FormatJSON && format != FormatCSV {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid format: %s", format),
+		})
+		return
+	}
+
+	lines, err := GetStocktakeLines(id, "", false)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve count sheet", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving count sheet", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve count sheet",
+		})
+		return
+	}
+
+	if format == FormatJSON {
+		context.JSON(http.StatusOK, schemas.ApiResponse{
+			Success: true,
+			Message: "Count sheet retrieved successfully",
+			Data:    lines,
+		})
+		return
+	}
+
+	data, err := countSheetCSV(lines)
+	if err != nil {
+		slog.Error("Failed to write count sheet as CSV", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve count sheet",
+		})
+		return
+	}
+
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("stocktake-%d.csv", id)))
+	context.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// countEntryRequest is the body for entering counts
+type countEntryRequest struct {
+	Counter string                        `json:"counter"`
+	Counts  []schemas.StocktakeCountEntry `json:"counts"`
+}
+
+func EnterStocktakeCountsHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var request countEntryRequest
+	if err := context.ShouldBindJSON(&request); err != nil {
+		slog.Error("Failed to parse JSON of stocktake counts", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	counts, err := EnterStocktakeCounts(id, request.Counter, request.Counts)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to enter stocktake counts", "id", id, "counter", request.Counter, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when entering stocktake counts", "id", id, "counter", request.Counter, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to enter counts",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Counts entered successfully",
+		Data:    counts,
+	})
+}
+
+// ReviewStocktakeLineHandler sets or clears (null) the counted quantity of a line
+func ReviewStocktakeLineHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	lineIdStr := context.Param("lineId")
+	lineId, err := strconv.ParseInt(lineIdStr, 10, 64)
+	if err != nil {
+		slog.Error("Failed to get line ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid line ID",
+		})
+		return
+	}
+
+	var reviewData map[string]interface{}
+	if err := context.ShouldBindJSON(&reviewData); err != nil {
+		slog.Error("Failed to parse JSON of stocktake line review", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	countedQuantity, exists := reviewData["counted_quantity"]
+	if !exists {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Missing required field: counted_quantity",
+		})
+		return
+	}
+
+	var quantity *int
+	if countedQuantity != nil {
+		quantityValue, ok := countedQuantity.(float64)
+		if !ok || quantityValue != float64(int(quantityValue)) {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "counted_quantity must be a whole number or null",
+			})
+			return
+		}
+		quantityInt := int(quantityValue)
+		quantity = &quantityInt
+	}
+
+	line, err := ReviewStocktakeLine(id, lineId, quantity)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to review stocktake line", "id", id, "line_id", lineId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when reviewing stocktake line", "id", id, "line_id", lineId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to review stocktake line",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Stocktake line reviewed successfully",
+		Data:    line,
+	})
+}
+
+// stocktakeStatusHandler returns a handler that moves the stocktake to another status
+func stocktakeStatusHandler(transition func(id int8) (schemas.Stocktake, error), name string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id, err := utils.GetIdFromContext(context)
+		if err != nil {
+			slog.Error("Failed to get ID from context", "error", err)
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Invalid ID",
+			})
+			return
+		}
+
+		stocktake, err := transition(id)
+		if err != nil {
+			if utils.IsCustomError(err) {
+				customErr := err.(*schemas.CustomError)
+				slog.Error("Failed to "+name+" stocktake", "id", id, "error", customErr.Details)
+				context.JSON(customErr.Code, schemas.ApiResponse{
+					Success: false,
+					Message: customErr.Message,
+				})
+				return
+			}
+
+			slog.Error("Unexpected error when trying to "+name+" stocktake", "id", id, "error", err)
+			context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+				Success: false,
+				Message: "Failed to " + name + " stocktake",
+			})
+			return
+		}
+
+		context.JSON(http.StatusOK, schemas.ApiResponse{
+			Success: true,
+			Message: "Stocktake status changed to " + stocktake.Status,
+			Data:    stocktake,
+		})
+	}
+}
+
+var SubmitStocktakeHandler = stocktakeStatusHandler(SubmitStocktakeForReview, "submit")
+
+var ReopenStocktakeHandler = stocktakeStatusHandler(ReopenStocktake, "reopen")
+
+var CancelStocktakeHandler = stocktakeStatusHandler(CancelStocktake, "cancel")
+
+func ApproveStocktakeHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var approvalData map[string]interface{}
+	if err := context.ShouldBindJSON(&approvalData); err != nil {
+		slog.Error("Failed to parse JSON of stocktake approval", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err = utils.CheckRequiredFields(approvalData, []string{"approved_by"})
+	if err != nil {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	approvedBy, _ := approvalData["approved_by"].(string)
+
+	approval, err := ApproveStocktake(id, approvedBy)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to approve stocktake", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when approving stocktake", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to approve stocktake",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Stocktake approved successfully",
+		Data:    approval,
+	})
+}
@@ -0,0 +1,21 @@
+package stocktakes
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStocktakeRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetStocktakesHandler)
+	routes.GET("/:id", GetStocktakeHandler)
+	routes.GET("/:id/sheet", GetStocktakeSheetHandler)
+	routes.GET("/:id/lines", GetStocktakeLinesHandler)
+
+	routes.POST("/", CreateStocktakeHandler)
+	routes.POST("/:id/counts", EnterStocktakeCountsHandler)
+	routes.PATCH("/:id/lines/:lineId", ReviewStocktakeLineHandler)
+
+	routes.POST("/:id/submit", SubmitStocktakeHandler)
+	routes.POST("/:id/reopen", ReopenStocktakeHandler)
+	routes.POST("/:id/approve", ApproveStocktakeHandler)
+	routes.POST("/:id/cancel", CancelStocktakeHandler)
+}
@@ -0,0 +1,767 @@
+package stocktakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// lineSelect embeds the item, lot and counts of a count line
+const lineSelect = "*, item:items(id, name, sku, location, base_unit), lot:item_lots(id, lot_number, expiry_date), counts:stocktake_counts(*)"
+
+// linePageSize is the number of lines fetched per request, which stays below the PostgREST row limit
+const linePageSize = 1000
+
+func GetStocktakes(status string) ([]schemas.Stocktake, error) {
+	client := db.Connect()
+
+	query := client.
+		From("stocktakes").
+		Select("*", "", false)
+
+	if status != "" {
+		query = query.Eq("status", status)
+	}
+
+	data, _, err := query.
+		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving stocktakes"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving stocktakes with status %q: %v", status, err),
+		}
+	}
+
+	var stocktakes []schemas.Stocktake
+	err = json.Unmarshal(data, &stocktakes)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stocktake data",
+			Details: fmt.Sprintf("Error parsing stocktake data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if stocktakes == nil {
+		stocktakes = []schemas.Stocktake{}
+	}
+
+	return stocktakes, nil
+}
+
+// getStocktake returns the stocktake without its summary
+func getStocktake(id int8) (schemas.Stocktake, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("stocktakes").
+		Select("*", "", false).
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the stocktake"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Stocktake not found"
+			}
+		}
+
+		return schemas.Stocktake{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving stocktake with ID %d: %v", id, err),
+		}
+	}
+
+	var stocktake schemas.Stocktake
+	err = json.Unmarshal(data, &stocktake)
+	if err != nil {
+		return schemas.Stocktake{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stocktake data",
+			Details: fmt.Sprintf("Error parsing stocktake data for ID %d: %v", id, err),
+		}
+	}
+
+	return stocktake, nil
+}
+
+// GetStocktake returns the stocktake with a summary of its lines
+func GetStocktake(id int8) (schemas.Stocktake, error) {
+	stocktake, err := getStocktake(id)
+	if err != nil {
+		return schemas.Stocktake{}, err
+	}
+
+	lines, err := GetStocktakeLines(id, "", false)
+	if err != nil {
+		return schemas.Stocktake{}, err
+	}
+
+	summary := SummarizeStocktakeLines(lines)
+	stocktake.Summary = &summary
+
+	return stocktake, nil
+}
+
+// CreateStocktake creates the stocktake and its count sheet with the current quantities of the items in scope
+func CreateStocktake(stocktake schemas.Stocktake) (schemas.Stocktake, error) {
+	client := db.Connect()
+
+	if stocktake.Scope == "" {
+		stocktake.Scope = schemas.StocktakeScopeFull
+	}
+
+	switch stocktake.Scope {
+	case schemas.StocktakeScopeFull:
+		stocktake.Location = nil
+		stocktake.CategoryId = nil
+	case schemas.StocktakeScopeLocation:
+		if stocktake.Location == nil || strings.TrimSpace(*stocktake.Location) == "" {
+			return schemas.Stocktake{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "location is required for a stocktake by location",
+				Details: "Attempted to create a stocktake by location without a location",
+			}
+		}
+		location := strings.TrimSpace(*stocktake.Location)
+		stocktake.Location = &location
+		stocktake.CategoryId = nil
+	case schemas.StocktakeScopeCategory:
+		if stocktake.CategoryId == nil {
+			return schemas.Stocktake{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "category_id is required for a stocktake by category",
+				Details: "Attempted to create a stocktake by category without a category",
+			}
+		}
+		stocktake.Location = nil
+	default:
+		return schemas.Stocktake{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid scope, must be %s, %s or %s", schemas.StocktakeScopeFull, schemas.StocktakeScopeLocation, schemas.StocktakeScopeCategory),
+			Details: fmt.Sprintf("Invalid stocktake scope %q", stocktake.Scope),
+		}
+	}
+
+	data, err := db.Rpc(client, "create_stocktake", map[string]interface{}{
+		"stocktake_name":        strings.TrimSpace(stocktake.Name),
+		"stocktake_scope":       stocktake.Scope,
+		"stocktake_location":    stocktake.Location,
+		"stocktake_category_id": stocktake.CategoryId,
+		"stocktake_note":        stocktake.Note,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the stocktake"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusUnprocessableEntity {
+				message = "Category not found"
+			}
+		}
+
+		return schemas.Stocktake{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating stocktake %q: %v", stocktake.Name, err),
+		}
+	}
+
+	var createdStocktake schemas.Stocktake
+	err = json.Unmarshal(data, &createdStocktake)
+	if err != nil {
+		return schemas.Stocktake{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stocktake data",
+			Details: fmt.Sprintf("Error parsing stocktake data while creating stocktake: %v", err),
+		}
+	}
+
+	return GetStocktake(createdStocktake.Id)
+}
+
+// resolveStocktakeLine sets the status, final quantity and variance of the line from its counts.
+// A quantity set during review wins, otherwise the counters must agree.
+func resolveStocktakeLine(line *schemas.StocktakeLine) {
+	line.Status = schemas.StocktakeLineUncounted
+	line.FinalQuantity = nil
+	line.Variance = nil
+
+	switch {
+	case line.CountedQuantity != nil:
+		line.Status = schemas.StocktakeLineReviewed
+		line.FinalQuantity = line.CountedQuantity
+	case len(line.Counts) > 0:
+		line.Status = schemas.StocktakeLineAgreed
+		quantity := line.Counts[0].Quantity
+		for _, count := range line.Counts[1:] {
+			if count.Quantity != quantity {
+				line.Status = schemas.StocktakeLineConflict
+			}
+		}
+		if line.Status == schemas.StocktakeLineAgreed {
+			line.FinalQuantity = &quantity
+		}
+	}
+
+	if line.FinalQuantity != nil {
+		variance := *line.FinalQuantity - line.ExpectedQuantity
+		line.Variance = &variance
+	}
+}
+
+// SummarizeStocktakeLines counts the lines per status
+func SummarizeStocktakeLines(lines []schemas.StocktakeLine) schemas.StocktakeSummary {
+	summary := schemas.StocktakeSummary{Lines: len(lines)}
+	for _, line := range lines {
+		switch line.Status {
+		case schemas.StocktakeLineUncounted:
+			summary.Uncounted++
+		case schemas.StocktakeLineAgreed:
+			summary.Agreed++
+		case schemas.StocktakeLineConflict:
+			summary.Conflicts++
+		case schemas.StocktakeLineReviewed:
+			summary.Reviewed++
+		}
+
+		if line.Variance != nil && *line.Variance != 0 {
+			summary.Variances++
+		}
+	}
+	return summary
+}
+
+// GetStocktakeLines returns the count lines in count sheet order: by location, item name and lot number.
+// Lines can be filtered by status, and onlyVariances leaves out the lines that match the expected quantity.
+func GetStocktakeLines(id int8, status string, onlyVariances bool) ([]schemas.StocktakeLine, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	var lines []schemas.StocktakeLine
+	for start := 0; ; start += linePageSize {
+		data, _, err := client.
+			From("stocktake_lines").
+			Select(lineSelect, "", false).
+			Eq("stocktake_id", idStr).
+			Order("id", &postgrest.OrderOpts{Ascending: true}).
+			Range(start, start+linePageSize-1, "").
+			Execute()
+
+		if err != nil {
+			// Set the default error code and message
+			code := http.StatusInternalServerError
+			message := "An error occurred while retrieving the stocktake lines"
+
+			// Check if the error is a Postgres error
+			// If true we update the code and message accordingly
+			if status := utils.PostgresToHTTPError(err); status != nil {
+				code = *status
+				message = utils.PostgresErrorMessage(err, message)
+			}
+
+			return nil, &schemas.CustomError{
+				Code:    code,
+				Message: message,
+				Details: fmt.Sprintf("Error retrieving lines from row %d of stocktake %d: %v", start, id, err),
+			}
+		}
+
+		var page []schemas.StocktakeLine
+		err = json.Unmarshal(data, &page)
+		if err != nil {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to parse stocktake line data",
+				Details: fmt.Sprintf("Error parsing lines of stocktake %d: %v", id, err),
+			}
+		}
+
+		lines = append(lines, page...)
+		if len(page) < linePageSize {
+			break
+		}
+	}
+
+	filtered := []schemas.StocktakeLine{}
+	for _, line := range lines {
+		// Counts are listed by counter
+		sort.Slice(line.Counts, func(i, j int) bool { return line.Counts[i].Counter < line.Counts[j].Counter })
+		if line.Counts == nil {
+			line.Counts = []schemas.StocktakeCount{}
+		}
+
+		resolveStocktakeLine(&line)
+
+		if status != "" && line.Status != status {
+			continue
+		}
+		if onlyVariances && (line.Variance == nil || *line.Variance == 0) {
+			continue
+		}
+		filtered = append(filtered, line)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return lineSortKey(filtered[i]) < lineSortKey(filtered[j])
+	})
+
+	return filtered, nil
+}
+
+// lineSortKey returns the key that orders the count sheet. Lines without a location come last.
+func lineSortKey(line schemas.StocktakeLine) string {
+	location, name, lotNumber := "\uffff", "", ""
+	if line.Item != nil {
+		if line.Item.Location != nil {
+			location = strings.ToLower(*line.Item.Location)
+		}
+		name = strings.ToLower(line.Item.Name)
+	}
+	if line.Lot != nil {
+		lotNumber = line.Lot.LotNumber
+	}
+	return location + "\x00" + name + "\x00" + lotNumber
+}
+
+// getStocktakeLine returns a single line of the stocktake with its counts
+func getStocktakeLine(id int8, lineId int64) (schemas.StocktakeLine, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("stocktake_lines").
+		Select(lineSelect, "", false).
+		Eq("id", fmt.Sprintf("%d", lineId)).
+		Eq("stocktake_id", fmt.Sprintf("%d", id)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the stocktake line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Stocktake line not found"
+			}
+		}
+
+		return schemas.StocktakeLine{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving line %d of stocktake %d: %v", lineId, id, err),
+		}
+	}
+
+	var line schemas.StocktakeLine
+	err = json.Unmarshal(data, &line)
+	if err != nil {
+		return schemas.StocktakeLine{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stocktake line data",
+			Details: fmt.Sprintf("Error parsing line %d of stocktake %d: %v", lineId, id, err),
+		}
+	}
+
+	if line.Counts == nil {
+		line.Counts = []schemas.StocktakeCount{}
+	}
+	resolveStocktakeLine(&line)
+
+	return line, nil
+}
+
+// checkStocktakeStatus returns an error unless the stocktake has one of the statuses
+func checkStocktakeStatus(stocktake schemas.Stocktake, action string, statuses ...string) error {
+	if slices.Contains(statuses, stocktake.Status) {
+		return nil
+	}
+
+	return &schemas.CustomError{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("Can not %s a stocktake that is %s", action, stocktake.Status),
+		Details: fmt.Sprintf("Attempted to %s stocktake %d with status %s", action, stocktake.Id, stocktake.Status),
+	}
+}
+
+// EnterStocktakeCounts records the quantities counted by a counter. Counting a line again replaces the earlier count.
+func EnterStocktakeCounts(id int8, counter string, entries []schemas.StocktakeCountEntry) ([]schemas.StocktakeCount, error) {
+	client := db.Connect()
+
+	counter = strings.TrimSpace(counter)
+	if counter == "" {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "counter is required",
+			Details: fmt.Sprintf("Attempted to enter counts for stocktake %d without a counter", id),
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "At least one count is required",
+			Details: fmt.Sprintf("Attempted to enter no counts for stocktake %d", id),
+		}
+	}
+
+	stocktake, err := getStocktake(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStocktakeStatus(stocktake, "count", schemas.StocktakeStatusCounting); err != nil {
+		return nil, err
+	}
+
+	lines, err := GetStocktakeLines(id, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	lineIds := map[int64]bool{}
+	for _, line := range lines {
+		lineIds[line.Id] = true
+	}
+
+	now := utils.GetCurrentISODate()
+	rows := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		if !lineIds[entry.LineId] {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Line %d is not part of this stocktake", entry.LineId),
+				Details: fmt.Sprintf("Line %d is not part of stocktake %d", entry.LineId, id),
+			}
+		}
+		if entry.Quantity < 0 {
+			return nil, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "Counted quantities must be at least 0",
+				Details: fmt.Sprintf("Invalid counted quantity %d for line %d of stocktake %d", entry.Quantity, entry.LineId, id),
+			}
+		}
+
+		rows = append(rows, map[string]interface{}{
+			"line_id":    entry.LineId,
+			"counter":    counter,
+			"quantity":   entry.Quantity,
+			"updated_at": now,
+		})
+	}
+
+	data, _, err := client.
+		From("stocktake_counts").
+		Insert(rows, true, "line_id,counter", "", "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while entering the counts"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error entering %d counts by %q for stocktake %d: %v", len(rows), counter, id, err),
+		}
+	}
+
+	var counts []schemas.StocktakeCount
+	err = json.Unmarshal(data, &counts)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stocktake count data",
+			Details: fmt.Sprintf("Error parsing counts of stocktake %d: %v", id, err),
+		}
+	}
+
+	return counts, nil
+}
+
+// ReviewStocktakeLine sets the counted quantity of a line during review, which overrides the counts.
+// A nil quantity clears it, so the counts decide again.
+func ReviewStocktakeLine(id int8, lineId int64, countedQuantity *int) (schemas.StocktakeLine, error) {
+	client := db.Connect()
+
+	if countedQuantity != nil && *countedQuantity < 0 {
+		return schemas.StocktakeLine{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "counted_quantity must be at least 0",
+			Details: fmt.Sprintf("Invalid counted quantity %d for line %d of stocktake %d", *countedQuantity, lineId, id),
+		}
+	}
+
+	stocktake, err := getStocktake(id)
+	if err != nil {
+		return schemas.StocktakeLine{}, err
+	}
+	if err := checkStocktakeStatus(stocktake, "review", schemas.StocktakeStatusCounting, schemas.StocktakeStatusReview); err != nil {
+		return schemas.StocktakeLine{}, err
+	}
+
+	_, _, err = client.
+		From("stocktake_lines").
+		Update(map[string]interface{}{
+			"counted_quantity": countedQuantity,
+			"updated_at":       utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", fmt.Sprintf("%d", lineId)).
+		Eq("stocktake_id", fmt.Sprintf("%d", id)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while reviewing the stocktake line"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Stocktake line not found"
+			}
+		}
+
+		return schemas.StocktakeLine{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error reviewing line %d of stocktake %d: %v", lineId, id, err),
+		}
+	}
+
+	return getStocktakeLine(id, lineId)
+}
+
+// setStocktakeStatus moves the stocktake to the status, when it has one of the allowed statuses
+func setStocktakeStatus(id int8, status string, action string, allowed ...string) (schemas.Stocktake, error) {
+	client := db.Connect()
+
+	stocktake, err := getStocktake(id)
+	if err != nil {
+		return schemas.Stocktake{}, err
+	}
+	if err := checkStocktakeStatus(stocktake, action, allowed...); err != nil {
+		return schemas.Stocktake{}, err
+	}
+
+	// The status filter makes sure the status did not change in the meantime
+	_, _, err = client.
+		From("stocktakes").
+		Update(map[string]interface{}{
+			"status":     status,
+			"updated_at": utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Eq("status", stocktake.Status).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := fmt.Sprintf("An error occurred while trying to %s the stocktake", action)
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				code = http.StatusConflict
+				message = "The stocktake was changed in the meantime, try again"
+			}
+		}
+
+		return schemas.Stocktake{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error setting status of stocktake %d to %s: %v", id, status, err),
+		}
+	}
+
+	return GetStocktake(id)
+}
+
+// SubmitStocktakeForReview ends the counting, after which the variances can be reviewed
+func SubmitStocktakeForReview(id int8) (schemas.Stocktake, error) {
+	return setStocktakeStatus(id, schemas.StocktakeStatusReview, "submit", schemas.StocktakeStatusCounting)
+}
+
+// ReopenStocktake returns a stocktake in review to counting, e.g. to recount conflicting lines
+func ReopenStocktake(id int8) (schemas.Stocktake, error) {
+	return setStocktakeStatus(id, schemas.StocktakeStatusCounting, "reopen", schemas.StocktakeStatusReview)
+}
+
+func CancelStocktake(id int8) (schemas.Stocktake, error) {
+	return setStocktakeStatus(id, schemas.StocktakeStatusCancelled, "cancel", schemas.StocktakeStatusCounting, schemas.StocktakeStatusReview)
+}
+
+// ApproveStocktake posts the variances as adjustment movements and approves the stocktake.
+// All lines must be counted and conflicts must be resolved during review.
+// The variance is relative to the expected quantity, so stock that moved during the count is kept.
+func ApproveStocktake(id int8, approvedBy string) (schemas.StocktakeApproval, error) {
+	client := db.Connect()
+
+	approvedBy = strings.TrimSpace(approvedBy)
+	if approvedBy == "" {
+		return schemas.StocktakeApproval{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "approved_by is required",
+			Details: fmt.Sprintf("Attempted to approve stocktake %d without an approver", id),
+		}
+	}
+
+	stocktake, err := getStocktake(id)
+	if err != nil {
+		return schemas.StocktakeApproval{}, err
+	}
+	if err := checkStocktakeStatus(stocktake, "approve", schemas.StocktakeStatusReview); err != nil {
+		return schemas.StocktakeApproval{}, err
+	}
+
+	lines, err := GetStocktakeLines(id, "", false)
+	if err != nil {
+		return schemas.StocktakeApproval{}, err
+	}
+
+	summary := SummarizeStocktakeLines(lines)
+	if summary.Uncounted > 0 || summary.Conflicts > 0 {
+		return schemas.StocktakeApproval{}, &schemas.CustomError{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("The stocktake has %d uncounted and %d conflicting lines", summary.Uncounted, summary.Conflicts),
+			Details: fmt.Sprintf("Attempted to approve stocktake %d with %d uncounted and %d conflicting lines", id, summary.Uncounted, summary.Conflicts),
+		}
+	}
+
+	referenceType := "stocktake"
+	referenceId := int64(id)
+	note := fmt.Sprintf("Stocktake %s", stocktake.Name)
+
+	movements := []schemas.StockMovement{}
+	for _, line := range lines {
+		if *line.Variance == 0 {
+			continue
+		}
+
+		movements = append(movements, schemas.StockMovement{
+			ItemId:        line.ItemId,
+			LotId:         line.LotId,
+			Quantity:      *line.Variance,
+			Type:          schemas.StockMovementAdjustment,
+			ReferenceType: &referenceType,
+			ReferenceId:   &referenceId,
+			Note:          &note,
+		})
+	}
+
+	data, err := db.Rpc(client, "approve_stocktake", map[string]interface{}{
+		"stocktake_id": id,
+		"approver":     approvedBy,
+		"movements":    movements,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while approving the stocktake"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			// The errors raised by approve_stocktake and apply_stock_movements are written for the user
+			if code == http.StatusBadRequest || code == http.StatusNotFound {
+				message = utils.ParsePostgresError(err).Message
+			}
+		}
+
+		return schemas.StocktakeApproval{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error approving stocktake %d with %d adjustments: %v", id, len(movements), err),
+		}
+	}
+
+	var recorded []schemas.StockMovement
+	err = json.Unmarshal(data, &recorded)
+	if err != nil {
+		return schemas.StocktakeApproval{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse stock movement data",
+			Details: fmt.Sprintf("Error parsing the adjustments of stocktake %d: %v", id, err),
+		}
+	}
+
+	approvedStocktake, err := GetStocktake(id)
+	if err != nil {
+		return schemas.StocktakeApproval{}, err
+	}
+
+	return schemas.StocktakeApproval{
+		Stocktake: approvedStocktake,
+		Movements: recorded,
+	}, nil
+}
@@ -29,6 +29,7 @@ func GetSupplierContactInfo(supplierId int8) ([]schemas.SupplierContactInfo, err
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				return []schemas.SupplierContactInfo{}, nil
@@ -54,3 +55,54 @@ func GetSupplierContactInfo(supplierId int8) ([]schemas.SupplierContactInfo, err
 
 	return supplierContactInfo, nil
 }
+
+// GetContactInfoOfSuppliers returns the contact info of all the given suppliers in one query
+func GetContactInfoOfSuppliers(supplierIds []int8) ([]schemas.SupplierContactInfo, error) {
+	if len(supplierIds) == 0 {
+		return []schemas.SupplierContactInfo{}, nil
+	}
+
+	client := db.Connect()
+
+	idStrs := make([]string, len(supplierIds))
+	for i, id := range supplierIds {
+		idStrs[i] = fmt.Sprintf("%d", id)
+	}
+
+	data, _, err := client.
+		From("supplier_contact_information").
+		Select("*", "", false).
+		In("supplier_id", idStrs).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the supplier contact info"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving supplier contact info for %d suppliers: %v", len(supplierIds), err),
+		}
+	}
+
+	var supplierContactInfo []schemas.SupplierContactInfo
+	err = json.Unmarshal(data, &supplierContactInfo)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse supplier contact info data",
+			Details: fmt.Sprintf("Error parsing supplier contact info data for %d suppliers: %v", len(supplierIds), err),
+		}
+	}
+
+	return supplierContactInfo, nil
+}
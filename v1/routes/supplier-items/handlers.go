@@ -0,0 +1,283 @@
+package supplieritems
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify
+var protectedFields = []string{"id", "supplier_id", "item_id", "created_at", "updated_at", "supplier", "item"}
+
+// catalogueSide describes from which side the supplier catalogue is accessed.
+// Below /items/:id the id is the item and the other id is the supplier, and the other way around below /suppliers/:id.
+type catalogueSide struct {
+	// otherParam is the path parameter holding the id of the other side
+	otherParam string
+	// otherField is the body field holding the id of the other side when creating an entry
+	otherField string
+	// ids returns the supplier and item id from the id of this side and the other side
+	ids func(id int8, otherId int8) (supplierId int8, itemId int8)
+	// list returns the catalogue entries of this side
+	list func(id int8) ([]schemas.SupplierItem, error)
+}
+
+var itemSide = catalogueSide{
+	otherParam: "supplierId",
+	otherField: "supplier_id",
+	ids:        func(id int8, otherId int8) (int8, int8) { return otherId, id },
+	list:       GetItemSuppliers,
+}
+
+var supplierSide = catalogueSide{
+	otherParam: "itemId",
+	otherField: "item_id",
+	ids:        func(id int8, otherId int8) (int8, int8) { return id, otherId },
+	list:       GetSupplierCatalogue,
+}
+
+func (side catalogueSide) getHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	supplierItems, err := side.list(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve supplier items", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving supplier items", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve supplier items",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier items retrieved successfully",
+		Data:    supplierItems,
+	})
+}
+
+func (side catalogueSide) createHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var supplierItemData map[string]interface{}
+	if err := context.ShouldBindJSON(&supplierItemData); err != nil {
+		slog.Error("Failed to parse JSON of new supplier item", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err = utils.CheckRequiredFields(supplierItemData, []string{side.otherField, "price"})
+	if err != nil {
+		slog.Error("Missing required fields in supplier item data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// The price can be given as a decimal or in minor units of the currency
+	if _, hasPrice := supplierItemData["price"]; !hasPrice {
+		if _, hasPriceMinor := supplierItemData["price_minor"]; !hasPriceMinor {
+			context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+				Success: false,
+				Message: "Missing required field: price or price_minor",
+			})
+			return
+		}
+	}
+
+	otherId, ok := supplierItemData[side.otherField].(float64)
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid " + side.otherField,
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(supplierItemData, protectedFields)
+	supplierId, itemId := side.ids(id, int8(otherId))
+
+	supplierItem, err := CreateSupplierItem(supplierId, itemId, supplierItemData)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create supplier item", "supplier_id", supplierId, "item_id", itemId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating supplier item", "supplier_id", supplierId, "item_id", itemId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create supplier item",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier item created successfully",
+		Data:    supplierItem,
+	})
+}
+
+// getIds reads the supplier and item id from the path
+func (side catalogueSide) getIds(context *gin.Context) (int8, int8, bool) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return 0, 0, false
+	}
+
+	otherIdStr := context.Param(side.otherParam)
+	otherId, err := strconv.ParseInt(otherIdStr, 10, 8)
+	if err != nil {
+		slog.Error("Failed to get "+side.otherParam+" from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid " + side.otherField,
+		})
+		return 0, 0, false
+	}
+
+	supplierId, itemId := side.ids(id, int8(otherId))
+	return supplierId, itemId, true
+}
+
+func (side catalogueSide) updateHandler(context *gin.Context) {
+	supplierId, itemId, ok := side.getIds(context)
+	if !ok {
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of supplier item update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	supplierItem, err := UpdateSupplierItem(supplierId, itemId, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update supplier item", "supplier_id", supplierId, "item_id", itemId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating supplier item", "supplier_id", supplierId, "item_id", itemId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update supplier item",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier item updated successfully",
+		Data:    supplierItem,
+	})
+}
+
+func (side catalogueSide) deleteHandler(context *gin.Context) {
+	supplierId, itemId, ok := side.getIds(context)
+	if !ok {
+		return
+	}
+
+	err := DeleteSupplierItem(supplierId, itemId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete supplier item", "supplier_id", supplierId, "item_id", itemId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when deleting supplier item", "supplier_id", supplierId, "item_id", itemId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete supplier item",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier item deleted successfully",
+	})
+}
+
+var (
+	GetItemSuppliersHandler   = itemSide.getHandler
+	CreateItemSupplierHandler = itemSide.createHandler
+	UpdateItemSupplierHandler = itemSide.updateHandler
+	DeleteItemSupplierHandler = itemSide.deleteHandler
+
+	GetSupplierItemsHandler   = supplierSide.getHandler
+	CreateSupplierItemHandler = supplierSide.createHandler
+	UpdateSupplierItemHandler = supplierSide.updateHandler
+	DeleteSupplierItemHandler = supplierSide.deleteHandler
+)
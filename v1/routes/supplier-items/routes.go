@@ -0,0 +1,23 @@
+package supplieritems
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// SetupItemSupplierRoutes sets up the supplier catalogue routes below /items/:id/suppliers
+func SetupItemSupplierRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetItemSuppliersHandler)
+
+	routes.POST("/", CreateItemSupplierHandler)
+	routes.PATCH("/:supplierId", UpdateItemSupplierHandler)
+	routes.DELETE("/:supplierId", DeleteItemSupplierHandler)
+}
+
+// SetupSupplierItemRoutes sets up the supplier catalogue routes below /suppliers/:id/items
+func SetupSupplierItemRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetSupplierItemsHandler)
+
+	routes.POST("/", CreateSupplierItemHandler)
+	routes.PATCH("/:itemId", UpdateSupplierItemHandler)
+	routes.DELETE("/:itemId", DeleteSupplierItemHandler)
+}
@@ -0,0 +1,283 @@
+package supplieritems
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// currencyRegex matches ISO 4217 currency codes
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// getSupplierItems returns the catalogue entries matching the column, with the embedded relation
+func getSupplierItems(column string, id int8, embed string) ([]schemas.SupplierItem, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("supplier_items").
+		Select("*, "+embed, "", false).
+		Eq(column, idStr).
+		Order("is_preferred", &postgrest.OrderOpts{Ascending: false}).
+		Order("price", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the supplier catalogue"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving supplier items with %s %d: %v", column, id, err),
+		}
+	}
+
+	var supplierItems []schemas.SupplierItem
+	err = json.Unmarshal(data, &supplierItems)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse supplier item data",
+			Details: fmt.Sprintf("Error parsing supplier items with %s %d: %v", column, id, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if supplierItems == nil {
+		supplierItems = []schemas.SupplierItem{}
+	}
+
+	return supplierItems, nil
+}
+
+// GetItemSuppliers returns the suppliers of the item, preferred supplier first and then by price
+func GetItemSuppliers(itemId int8) ([]schemas.SupplierItem, error) {
+	return getSupplierItems("item_id", itemId, "supplier:suppliers(id, name)")
+}
+
+// GetSupplierCatalogue returns the items the supplier sells
+func GetSupplierCatalogue(supplierId int8) ([]schemas.SupplierItem, error) {
+	return getSupplierItems("supplier_id", supplierId, "item:items(id, name, sku)")
+}
+
+// validateSupplierItemFields checks the values of the fields that can be set on a catalogue entry
+func validateSupplierItemFields(fields map[string]interface{}) error {
+	if price, exists := fields["price"]; exists {
+		if priceValue, ok := price.(float64); !ok || priceValue < 0 {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "price must be a number of at least 0",
+				Details: fmt.Sprintf("Invalid supplier item price %v", price),
+			}
+		}
+	}
+
+	if priceMinor, exists := fields["price_minor"]; exists {
+		if priceValue, ok := priceMinor.(float64); !ok || priceValue < 0 || priceValue != float64(int64(priceValue)) {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "price_minor must be a whole number of at least 0",
+				Details: fmt.Sprintf("Invalid supplier item price in minor units %v", priceMinor),
+			}
+		}
+	}
+
+	if currency, exists := fields["currency"]; exists {
+		currencyStr, ok := currency.(string)
+		if !ok || !currencyRegex.MatchString(strings.ToUpper(currencyStr)) {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "currency must be a three letter ISO 4217 code",
+				Details: fmt.Sprintf("Invalid supplier item currency %v", currency),
+			}
+		}
+		fields["currency"] = strings.ToUpper(currencyStr)
+	}
+
+	if leadTime, exists := fields["lead_time_days"]; exists {
+		if leadTimeValue, ok := leadTime.(float64); !ok || leadTimeValue < 0 || leadTimeValue != float64(int(leadTimeValue)) {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "lead_time_days must be a whole number of at least 0",
+				Details: fmt.Sprintf("Invalid supplier item lead time %v", leadTime),
+			}
+		}
+	}
+
+	if minOrderQuantity, exists := fields["min_order_quantity"]; exists {
+		if minValue, ok := minOrderQuantity.(float64); !ok || minValue < 1 || minValue != float64(int(minValue)) {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "min_order_quantity must be a whole number of at least 1",
+				Details: fmt.Sprintf("Invalid supplier item minimum order quantity %v", minOrderQuantity),
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateSupplierItem adds an item to the catalogue of a supplier
+func CreateSupplierItem(supplierId int8, itemId int8, fields map[string]interface{}) (schemas.SupplierItem, error) {
+	client := db.Connect()
+
+	if err := validateSupplierItemFields(fields); err != nil {
+		return schemas.SupplierItem{}, err
+	}
+
+	fields["supplier_id"] = supplierId
+	fields["item_id"] = itemId
+	fields["created_at"] = utils.GetCurrentISODate()
+	fields["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("supplier_items").
+		Insert(fields, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while adding the item to the supplier catalogue"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = "The supplier already sells this item"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Supplier, item or unit not found"
+			}
+		}
+
+		return schemas.SupplierItem{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error adding item %d to the catalogue of supplier %d: %v", itemId, supplierId, err),
+		}
+	}
+
+	var supplierItem schemas.SupplierItem
+	err = json.Unmarshal(data, &supplierItem)
+	if err != nil {
+		return schemas.SupplierItem{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse supplier item data",
+			Details: fmt.Sprintf("Error parsing supplier item data for supplier %d and item %d: %v", supplierId, itemId, err),
+		}
+	}
+
+	return supplierItem, nil
+}
+
+func UpdateSupplierItem(supplierId int8, itemId int8, updates map[string]interface{}) (schemas.SupplierItem, error) {
+	client := db.Connect()
+
+	if err := validateSupplierItemFields(updates); err != nil {
+		return schemas.SupplierItem{}, err
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("supplier_items").
+		Update(updates, "", "").
+		Eq("supplier_id", fmt.Sprintf("%d", supplierId)).
+		Eq("item_id", fmt.Sprintf("%d", itemId)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the supplier item"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "The supplier does not sell this item"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "Unit not found"
+			}
+		}
+
+		return schemas.SupplierItem{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating item %d in the catalogue of supplier %d: %v", itemId, supplierId, err),
+		}
+	}
+
+	var supplierItem schemas.SupplierItem
+	err = json.Unmarshal(data, &supplierItem)
+	if err != nil {
+		return schemas.SupplierItem{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse supplier item data",
+			Details: fmt.Sprintf("Error parsing supplier item data for supplier %d and item %d: %v", supplierId, itemId, err),
+		}
+	}
+
+	return supplierItem, nil
+}
+
+func DeleteSupplierItem(supplierId int8, itemId int8) error {
+	client := db.Connect()
+
+	_, _, err := client.
+		From("supplier_items").
+		Delete("", "").
+		Eq("supplier_id", fmt.Sprintf("%d", supplierId)).
+		Eq("item_id", fmt.Sprintf("%d", itemId)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while removing the item from the supplier catalogue"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "The supplier does not sell this item"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error removing item %d from the catalogue of supplier %d: %v", itemId, supplierId, err),
+		}
+	}
+
+	return nil
+}
@@ -9,8 +9,38 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// protectedFields contains fields that the user should not be able to modify
-var protectedFields = []string{"id", "created_at", "updated_at", "deleted_at"}
+// ProtectedFields contains fields that the user should not be able to modify
+var ProtectedFields = []string{"id", "contact_info", "created_at", "updated_at", "deleted_at"}
+
+func GetSuppliersHandler(context *gin.Context) {
+	name := context.Query("name")
+
+	suppliers, err := GetSuppliers(name)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve suppliers", "name", name, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving suppliers", "name", name, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve suppliers",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Suppliers retrieved successfully",
+		Data:    suppliers,
+	})
+}
 
 func GetSupplierHandler(context *gin.Context) {
 	id, err := utils.GetIdFromContext(context)
@@ -49,3 +79,166 @@ func GetSupplierHandler(context *gin.Context) {
 		Data:    item,
 	})
 }
+
+func UpdateSupplierHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of supplier update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, ProtectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	supplier, err := UpdateSupplier(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update supplier", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating supplier", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update supplier",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier updated successfully",
+		Data:    supplier,
+	})
+}
+
+func CreateSupplierHandler(context *gin.Context) {
+	var supplierData map[string]interface{}
+	if err := context.ShouldBindJSON(&supplierData); err != nil {
+		slog.Error("Failed to parse JSON of new supplier", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	err := utils.CheckRequiredFields(supplierData, []string{"name"})
+	if err != nil {
+		slog.Error("Missing required fields in supplier data", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	name, ok := supplierData["name"].(string)
+	if !ok {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "name must be a string",
+		})
+		return
+	}
+
+	newSupplier := schemas.Supplier{Name: name}
+
+	if website, exists := supplierData["website"].(string); exists {
+		newSupplier.Website = website
+	}
+	if address, exists := supplierData["address"].(string); exists {
+		newSupplier.Address = address
+	}
+	if vatNumber, exists := supplierData["vat_number"].(string); exists {
+		newSupplier.VatNumber = vatNumber
+	}
+
+	supplier, err := CreateSupplier(newSupplier)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create supplier", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating supplier", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create supplier",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier created successfully",
+		Data:    supplier,
+	})
+}
+
+func DeleteSupplierHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteSupplier(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete supplier", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Failed to delete supplier", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete supplier",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Supplier deleted successfully",
+	})
+}
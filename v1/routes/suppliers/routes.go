@@ -5,5 +5,10 @@ import (
 )
 
 func SetupSupplierRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetSuppliersHandler)
 	routes.GET("/:id", GetSupplierHandler)
+
+	routes.PATCH("/:id", UpdateSupplierHandler)
+	routes.POST("/", CreateSupplierHandler)
+	routes.DELETE("/:id", DeleteSupplierHandler)
 }
@@ -4,13 +4,93 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
 	suppliercontactinfo "github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/supplier-contact-info"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
 	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
 )
 
+// GetSuppliers returns the suppliers by name, with their contact info. An empty name returns all suppliers.
+func GetSuppliers(name string) ([]schemas.Supplier, error) {
+	client := db.Connect()
+
+	query := client.
+		From("suppliers").
+		Select("*", "", false).
+		Is("deleted_at", "null")
+
+	if name = strings.TrimSpace(name); name != "" {
+		query = query.Ilike("name", "%"+name+"%")
+	}
+
+	data, _, err := query.
+		Order("name", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving suppliers"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving suppliers matching %q: %v", name, err),
+		}
+	}
+
+	var suppliers []schemas.Supplier
+	err = json.Unmarshal(data, &suppliers)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse supplier data",
+			Details: fmt.Sprintf("Error parsing supplier data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if suppliers == nil {
+		return []schemas.Supplier{}, nil
+	}
+
+	supplierIds := make([]int8, len(suppliers))
+	for i, supplier := range suppliers {
+		supplierIds[i] = supplier.Id
+	}
+
+	contactInfo, err := suppliercontactinfo.GetContactInfoOfSuppliers(supplierIds)
+	if err != nil {
+		return nil, err
+	}
+
+	contactInfoBySupplier := map[int8][]schemas.SupplierContactInfo{}
+	for _, contact := range contactInfo {
+		contactInfoBySupplier[contact.SupplierId] = append(contactInfoBySupplier[contact.SupplierId], contact)
+	}
+
+	for i := range suppliers {
+		suppliers[i].ContactInfo = contactInfoBySupplier[suppliers[i].Id]
+
+		// We make sure that the contact info is an empty array before returning it
+		if suppliers[i].ContactInfo == nil {
+			suppliers[i].ContactInfo = []schemas.SupplierContactInfo{}
+		}
+	}
+
+	return suppliers, nil
+}
+
 func GetSupplier(id int8) (schemas.Supplier, error) {
 	client := db.Connect()
 	idStr := fmt.Sprintf("%d", id)
@@ -32,6 +112,7 @@ func GetSupplier(id int8) (schemas.Supplier, error) {
 		// If true we update the code and message accordingly
 		if status := utils.PostgresToHTTPError(err); status != nil {
 			code = *status
+			message = utils.PostgresErrorMessage(err, message)
 
 			if code == http.StatusNotFound {
 				message = "Supplier not found"
@@ -74,3 +155,157 @@ func GetSupplier(id int8) (schemas.Supplier, error) {
 
 	return supplier, nil
 }
+
+func UpdateSupplier(id int8, updates map[string]interface{}) (schemas.Supplier, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	if name, exists := updates["name"]; exists {
+		if nameStr, ok := name.(string); !ok || strings.TrimSpace(nameStr) == "" {
+			return schemas.Supplier{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "name can not be empty",
+				Details: fmt.Sprintf("Invalid name %v for supplier %d", name, id),
+			}
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	_, _, err := client.
+		From("suppliers").
+		Update(updates, "", "").
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the supplier"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Supplier not found"
+			}
+		}
+
+		return schemas.Supplier{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating supplier with ID %d: %v", id, err),
+		}
+	}
+
+	// The supplier is retrieved again to include its contact info
+	return GetSupplier(id)
+}
+
+// DeleteSupplier soft deletes the supplier. It is kept until purge_records removes it after the retention period.
+func DeleteSupplier(id int8) error {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	_, _, err := client.
+		From("suppliers").
+		Update(map[string]interface{}{
+			"deleted_at": utils.GetCurrentISODate(),
+			"updated_at": utils.GetCurrentISODate(),
+		}, "", "").
+		Eq("id", idStr).
+		Is("deleted_at", "null").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the supplier"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Supplier not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting supplier with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+func CreateSupplier(supplier schemas.Supplier) (schemas.Supplier, error) {
+	client := db.Connect()
+
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return schemas.Supplier{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "name can not be empty",
+			Details: "Attempted to create a supplier without a name",
+		}
+	}
+
+	data, _, err := client.
+		From("suppliers").
+		Insert(map[string]interface{}{
+			"name":       supplier.Name,
+			"website":    supplier.Website,
+			"address":    supplier.Address,
+			"vat_number": supplier.VatNumber,
+			"created_at": utils.GetCurrentISODate(),
+			"updated_at": utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the supplier"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.Supplier{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating supplier %q: %v", supplier.Name, err),
+		}
+	}
+
+	var createdSupplier schemas.Supplier
+	err = json.Unmarshal(data, &createdSupplier)
+	if err != nil {
+		return schemas.Supplier{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse supplier data",
+			Details: fmt.Sprintf("Error parsing supplier data while creating supplier: %v", err),
+		}
+	}
+
+	// A new supplier has no contact info yet
+	createdSupplier.ContactInfo = []schemas.SupplierContactInfo{}
+
+	return createdSupplier, nil
+}
@@ -0,0 +1,23 @@
+package units
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupUnitRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetUnitsHandler)
+
+	routes.PATCH("/:id", UpdateUnitHandler)
+	routes.POST("/", CreateUnitHandler)
+	routes.DELETE("/:id", DeleteUnitHandler)
+}
+
+// SetupItemUnitRoutes sets up the unit routes nested below an item
+func SetupItemUnitRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetItemUnitsHandler)
+	routes.GET("/convert", ConvertQuantityHandler)
+
+	routes.POST("/", CreateItemUnitHandler)
+	routes.PATCH("/:unitId", UpdateItemUnitHandler)
+	routes.DELETE("/:unitId", DeleteItemUnitHandler)
+}
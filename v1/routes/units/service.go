@@ -0,0 +1,493 @@
+package units
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/attributes"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/routes/items"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+func GetUnits() ([]schemas.UnitOfMeasure, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("units_of_measure").
+		Select("*", "", false).
+		Order("code", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving units of measure"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving units of measure: %v", err),
+		}
+	}
+
+	var units []schemas.UnitOfMeasure
+	err = json.Unmarshal(data, &units)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse units of measure data",
+			Details: fmt.Sprintf("Error parsing units of measure data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if units == nil {
+		units = []schemas.UnitOfMeasure{}
+	}
+
+	return units, nil
+}
+
+func CreateUnit(unit schemas.UnitOfMeasure) (schemas.UnitOfMeasure, error) {
+	client := db.Connect()
+
+	// Unit codes follow the same rules as attribute keys
+	if !attributes.KeyRegex.MatchString(unit.Code) {
+		return schemas.UnitOfMeasure{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid unit code, must be lowercase letters, digits and underscores",
+			Details: fmt.Sprintf("Unit code %q does not match %s", unit.Code, attributes.KeyRegex.String()),
+		}
+	}
+
+	data, _, err := client.
+		From("units_of_measure").
+		Insert(map[string]interface{}{
+			"code":       unit.Code,
+			"name":       unit.Name,
+			"created_at": utils.GetCurrentISODate(),
+			"updated_at": utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the unit of measure"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = fmt.Sprintf("A unit with code %s already exists", unit.Code)
+			}
+		}
+
+		return schemas.UnitOfMeasure{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating unit of measure: %v", err),
+		}
+	}
+
+	var createdUnit schemas.UnitOfMeasure
+	err = json.Unmarshal(data, &createdUnit)
+	if err != nil {
+		return schemas.UnitOfMeasure{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse unit of measure data",
+			Details: fmt.Sprintf("Error parsing unit of measure data while creating unit: %v", err),
+		}
+	}
+
+	return createdUnit, nil
+}
+
+func UpdateUnit(id int8, updates map[string]interface{}) (schemas.UnitOfMeasure, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("units_of_measure").
+		Update(updates, "", "").
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the unit of measure"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Unit of measure not found"
+			}
+		}
+
+		return schemas.UnitOfMeasure{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating unit of measure with ID %d: %v", id, err),
+		}
+	}
+
+	var updatedUnit schemas.UnitOfMeasure
+	err = json.Unmarshal(data, &updatedUnit)
+	if err != nil {
+		return schemas.UnitOfMeasure{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse unit of measure data",
+			Details: fmt.Sprintf("Error parsing unit of measure data for ID %d: %v", id, err),
+		}
+	}
+
+	return updatedUnit, nil
+}
+
+// DeleteUnit deletes a unit of measure. Units that are still used by items
+// can not be deleted, which the database reports as a foreign key violation.
+func DeleteUnit(id int8) error {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	_, _, err := client.
+		From("units_of_measure").
+		Delete("", "").
+		Eq("id", idStr).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the unit of measure"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Unit of measure not found"
+			} else if code == http.StatusUnprocessableEntity {
+				message = "The unit of measure is still used by items"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting unit of measure with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+func GetItemUnits(itemId int8) ([]schemas.ItemUnit, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", itemId)
+
+	data, _, err := client.
+		From("item_units").
+		Select("*", "", false).
+		Eq("item_id", idStr).
+		Order("factor", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the item units"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving units for item with ID %d: %v", itemId, err),
+		}
+	}
+
+	var itemUnits []schemas.ItemUnit
+	err = json.Unmarshal(data, &itemUnits)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item unit data",
+			Details: fmt.Sprintf("Error parsing unit data for item ID %d: %v", itemId, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if itemUnits == nil {
+		itemUnits = []schemas.ItemUnit{}
+	}
+
+	return itemUnits, nil
+}
+
+// CreateItemUnit adds a unit to the item. The base unit of the item always has the factor 1,
+// so it can not be added as an item unit.
+func CreateItemUnit(itemId int8, itemUnit schemas.ItemUnit) (schemas.ItemUnit, error) {
+	client := db.Connect()
+
+	item, err := items.GetItem(itemId)
+	if err != nil {
+		return schemas.ItemUnit{}, err
+	}
+
+	if itemUnit.UnitCode == item.BaseUnit {
+		return schemas.ItemUnit{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s is the base unit of the item", itemUnit.UnitCode),
+			Details: fmt.Sprintf("Attempted to add base unit %q as unit of item %d", itemUnit.UnitCode, itemId),
+		}
+	}
+
+	data, _, err := client.
+		From("item_units").
+		Insert(map[string]interface{}{
+			"item_id":          itemId,
+			"unit_code":        itemUnit.UnitCode,
+			"factor":           itemUnit.Factor,
+			"is_purchase_unit": itemUnit.IsPurchaseUnit,
+			"is_sales_unit":    itemUnit.IsSalesUnit,
+			"created_at":       utils.GetCurrentISODate(),
+			"updated_at":       utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while adding the item unit"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusConflict {
+				message = "The unit is already defined for this item, or the item already has a default purchase or sales unit"
+			} else if code == http.StatusUnprocessableEntity {
+				message = fmt.Sprintf("Unknown unit of measure: %s", itemUnit.UnitCode)
+			}
+		}
+
+		return schemas.ItemUnit{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error adding unit %q to item with ID %d: %v", itemUnit.UnitCode, itemId, err),
+		}
+	}
+
+	var createdUnit schemas.ItemUnit
+	err = json.Unmarshal(data, &createdUnit)
+	if err != nil {
+		return schemas.ItemUnit{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item unit data",
+			Details: fmt.Sprintf("Error parsing unit data while adding unit to item %d: %v", itemId, err),
+		}
+	}
+
+	return createdUnit, nil
+}
+
+func UpdateItemUnit(itemId int8, unitId int8, updates map[string]interface{}) (schemas.ItemUnit, error) {
+	client := db.Connect()
+
+	if factor, exists := updates["factor"]; exists {
+		factorValue, ok := factor.(float64)
+		if !ok || factorValue <= 0 {
+			return schemas.ItemUnit{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "factor must be a positive number",
+				Details: fmt.Sprintf("Invalid factor %v for unit %d of item %d", factor, unitId, itemId),
+			}
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	data, _, err := client.
+		From("item_units").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", unitId)).
+		Eq("item_id", fmt.Sprintf("%d", itemId)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the item unit"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Item unit not found"
+			} else if code == http.StatusConflict {
+				message = "The item already has a default purchase or sales unit"
+			}
+		}
+
+		return schemas.ItemUnit{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating unit %d of item with ID %d: %v", unitId, itemId, err),
+		}
+	}
+
+	var updatedUnit schemas.ItemUnit
+	err = json.Unmarshal(data, &updatedUnit)
+	if err != nil {
+		return schemas.ItemUnit{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse item unit data",
+			Details: fmt.Sprintf("Error parsing unit data for unit %d: %v", unitId, err),
+		}
+	}
+
+	return updatedUnit, nil
+}
+
+func DeleteItemUnit(itemId int8, unitId int8) error {
+	client := db.Connect()
+
+	_, _, err := client.
+		From("item_units").
+		Delete("", "").
+		Eq("id", fmt.Sprintf("%d", unitId)).
+		Eq("item_id", fmt.Sprintf("%d", itemId)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while removing the item unit"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Item unit not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error removing unit %d of item with ID %d: %v", unitId, itemId, err),
+		}
+	}
+
+	return nil
+}
+
+// GetUnitFactor returns the number of base units of the item in one of the unit.
+// An empty unit is the base unit of the item.
+func GetUnitFactor(itemId int8, unit string) (float64, error) {
+	client := db.Connect()
+
+	var unitParam interface{}
+	if unit != "" {
+		unitParam = unit
+	}
+
+	data, err := db.Rpc(client, "item_unit_factor", map[string]interface{}{
+		"target_item_id": itemId,
+		"unit":           unitParam,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the unit conversion"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = fmt.Sprintf("Unit %s is not defined for this item", unit)
+			}
+		}
+
+		return 0, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving factor of unit %q for item with ID %d: %v", unit, itemId, err),
+		}
+	}
+
+	var factor float64
+	err = json.Unmarshal(data, &factor)
+	if err != nil {
+		return 0, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse unit conversion data",
+			Details: fmt.Sprintf("Error parsing factor of unit %q for item ID %d: %v", unit, itemId, err),
+		}
+	}
+
+	return factor, nil
+}
+
+// ConvertQuantity converts a quantity of the item from one unit to another.
+// Empty units are the base unit of the item.
+func ConvertQuantity(itemId int8, quantity float64, from string, to string) (float64, error) {
+	fromFactor, err := GetUnitFactor(itemId, from)
+	if err != nil {
+		return 0, err
+	}
+
+	toFactor, err := GetUnitFactor(itemId, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity * fromFactor / toFactor, nil
+}
@@ -0,0 +1,421 @@
+package webhooks
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// protectedFields contains fields that the user should not be able to modify
+var protectedFields = []string{"id", "created_at", "updated_at"}
+
+// getDeliveryIdFromContext returns the delivery ID of the path
+func getDeliveryIdFromContext(context *gin.Context) (int64, error) {
+	return strconv.ParseInt(context.Param("deliveryId"), 10, 64)
+}
+
+func GetSubscriptionsHandler(context *gin.Context) {
+	subscriptions, err := GetSubscriptions()
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve webhook subscriptions", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving webhook subscriptions", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve webhook subscriptions",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook subscriptions retrieved successfully",
+		Data:    subscriptions,
+	})
+}
+
+func GetSubscriptionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	subscription, err := GetSubscription(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve webhook subscription", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving webhook subscription", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve webhook subscription",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook subscription retrieved successfully",
+		Data:    subscription,
+	})
+}
+
+// CreateSubscriptionHandler creates a subscription and returns it with its secret, which is not shown again
+func CreateSubscriptionHandler(context *gin.Context) {
+	var subscriptionData struct {
+		Url         string   `json:"url"`
+		Secret      string   `json:"secret"`
+		EventTypes  []string `json:"event_types"`
+		Active      *bool    `json:"active"`
+		Description *string  `json:"description"`
+	}
+	if err := context.ShouldBindJSON(&subscriptionData); err != nil {
+		slog.Error("Failed to parse JSON of new webhook subscription", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	newSubscription := schemas.WebhookSubscription{
+		Url:         subscriptionData.Url,
+		Secret:      subscriptionData.Secret,
+		EventTypes:  subscriptionData.EventTypes,
+		Active:      true,
+		Description: subscriptionData.Description,
+	}
+	if subscriptionData.Active != nil {
+		newSubscription.Active = *subscriptionData.Active
+	}
+
+	subscription, err := CreateSubscription(newSubscription)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to create webhook subscription", "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when creating webhook subscription", "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to create webhook subscription",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook subscription created successfully",
+		Data:    subscription,
+	})
+}
+
+func UpdateSubscriptionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := context.ShouldBindJSON(&updates); err != nil {
+		slog.Error("Failed to parse JSON of webhook subscription update", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid JSON in body.",
+		})
+		return
+	}
+
+	utils.RemoveProtectedFields(updates, protectedFields)
+	if len(updates) == 0 {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "No valid fields to update",
+		})
+		return
+	}
+
+	subscription, err := UpdateSubscription(id, updates)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to update webhook subscription", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when updating webhook subscription", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to update webhook subscription",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook subscription updated successfully",
+		Data:    subscription,
+	})
+}
+
+func DeleteSubscriptionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	err = DeleteSubscription(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to delete webhook subscription", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when deleting webhook subscription", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to delete webhook subscription",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook subscription deleted successfully",
+	})
+}
+
+// PingSubscriptionHandler queues a webhook.ping delivery to test the receiver of the subscription
+func PingSubscriptionHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	delivery, err := PingSubscription(id)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to ping webhook subscription", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when pinging webhook subscription", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to ping webhook subscription",
+		})
+		return
+	}
+
+	context.JSON(http.StatusAccepted, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook ping queued successfully",
+		Data:    delivery,
+	})
+}
+
+// GetDeliveriesHandler returns the delivery log of the subscription, filtered by status
+func GetDeliveriesHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", "50"))
+	if err != nil || !utils.InRange(limit, 1, 500) {
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid limit, must be between 1 and 500",
+		})
+		return
+	}
+
+	status := context.Query("status")
+
+	deliveries, err := GetDeliveries(id, status, limit)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve webhook deliveries", "id", id, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving webhook deliveries", "id", id, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve webhook deliveries",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook deliveries retrieved successfully",
+		Data:    deliveries,
+	})
+}
+
+func GetDeliveryHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	deliveryId, err := getDeliveryIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get delivery ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid delivery ID",
+		})
+		return
+	}
+
+	delivery, err := GetDelivery(id, deliveryId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to retrieve webhook delivery", "id", id, "delivery_id", deliveryId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when retrieving webhook delivery", "id", id, "delivery_id", deliveryId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to retrieve webhook delivery",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook delivery retrieved successfully",
+		Data:    delivery,
+	})
+}
+
+// ReplayDeliveryHandler queues the event of a delivery again
+func ReplayDeliveryHandler(context *gin.Context) {
+	id, err := utils.GetIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid ID",
+		})
+		return
+	}
+
+	deliveryId, err := getDeliveryIdFromContext(context)
+	if err != nil {
+		slog.Error("Failed to get delivery ID from context", "error", err)
+		context.JSON(http.StatusBadRequest, schemas.ApiResponse{
+			Success: false,
+			Message: "Invalid delivery ID",
+		})
+		return
+	}
+
+	replay, err := ReplayDelivery(id, deliveryId)
+	if err != nil {
+		if utils.IsCustomError(err) {
+			customErr := err.(*schemas.CustomError)
+			slog.Error("Failed to replay webhook delivery", "id", id, "delivery_id", deliveryId, "error", customErr.Details)
+			context.JSON(customErr.Code, schemas.ApiResponse{
+				Success: false,
+				Message: customErr.Message,
+			})
+			return
+		}
+
+		slog.Error("Unexpected error when replaying webhook delivery", "id", id, "delivery_id", deliveryId, "error", err)
+		context.JSON(http.StatusInternalServerError, schemas.ApiResponse{
+			Success: false,
+			Message: "Failed to replay webhook delivery",
+		})
+		return
+	}
+
+	context.JSON(http.StatusAccepted, schemas.ApiResponse{
+		Success: true,
+		Message: "Webhook delivery replay queued successfully",
+		Data:    replay,
+	})
+}
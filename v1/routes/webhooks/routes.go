@@ -0,0 +1,19 @@
+package webhooks
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func SetupWebhookRoutes(routes *gin.RouterGroup) {
+	routes.GET("", GetSubscriptionsHandler)
+	routes.GET("/:id", GetSubscriptionHandler)
+
+	routes.PATCH("/:id", UpdateSubscriptionHandler)
+	routes.POST("/", CreateSubscriptionHandler)
+	routes.DELETE("/:id", DeleteSubscriptionHandler)
+
+	routes.POST("/:id/ping", PingSubscriptionHandler)
+	routes.GET("/:id/deliveries", GetDeliveriesHandler)
+	routes.GET("/:id/deliveries/:deliveryId", GetDeliveryHandler)
+	routes.POST("/:id/deliveries/:deliveryId/replay", ReplayDeliveryHandler)
+}
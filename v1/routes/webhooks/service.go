@@ -0,0 +1,652 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// subscriptionSelect leaves out the secret, which is only returned when the subscription is created
+const subscriptionSelect = "id, url, event_types, active, description, created_at, updated_at"
+
+// AllEvents subscribes to every event type
+const AllEvents = "*"
+
+// eventTypes contains the event types that can be subscribed to
+var eventTypes = map[string]struct{}{
+	AllEvents:                    {},
+	schemas.EventItemCreated:     {},
+	schemas.EventItemUpdated:     {},
+	schemas.EventItemDeleted:     {},
+	schemas.EventStockChanged:    {},
+	schemas.EventStockLow:        {},
+	schemas.EventSupplierCreated: {},
+	schemas.EventSupplierUpdated: {},
+	schemas.EventSupplierDeleted: {},
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) string {
+	bytes := make([]byte, n)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// validateSubscription checks the URL and event types of a subscription
+func validateSubscription(subscriptionUrl string, types []string) error {
+	parsed, err := url.Parse(subscriptionUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "url must be an absolute http or https URL",
+			Details: fmt.Sprintf("Invalid webhook URL %q", subscriptionUrl),
+		}
+	}
+
+	if err := validateTargetHost(subscriptionUrl, parsed.Hostname()); err != nil {
+		return err
+	}
+
+	if len(types) == 0 {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "At least one event type is required",
+			Details: fmt.Sprintf("Webhook subscription for %s has no event types", subscriptionUrl),
+		}
+	}
+
+	for _, eventType := range types {
+		if _, valid := eventTypes[eventType]; !valid {
+			return &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid event type: %s", eventType),
+				Details: fmt.Sprintf("Webhook subscription for %s has unknown event type %q", subscriptionUrl, eventType),
+			}
+		}
+	}
+
+	return nil
+}
+
+func GetSubscriptions() ([]schemas.WebhookSubscription, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("webhook_subscriptions").
+		Select(subscriptionSelect, "", false).
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving webhook subscriptions"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving webhook subscriptions: %v", err),
+		}
+	}
+
+	var subscriptions []schemas.WebhookSubscription
+	err = json.Unmarshal(data, &subscriptions)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook subscription data",
+			Details: fmt.Sprintf("Error parsing webhook subscription data: %v", err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if subscriptions == nil {
+		subscriptions = []schemas.WebhookSubscription{}
+	}
+
+	return subscriptions, nil
+}
+
+func GetSubscription(id int8) (schemas.WebhookSubscription, error) {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	data, _, err := client.
+		From("webhook_subscriptions").
+		Select(subscriptionSelect, "", false).
+		Eq("id", idStr).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the webhook subscription"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Webhook subscription not found"
+			}
+		}
+
+		return schemas.WebhookSubscription{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving webhook subscription with ID %d: %v", id, err),
+		}
+	}
+
+	var subscription schemas.WebhookSubscription
+	err = json.Unmarshal(data, &subscription)
+	if err != nil {
+		return schemas.WebhookSubscription{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook subscription data",
+			Details: fmt.Sprintf("Error parsing webhook subscription data for ID %d: %v", id, err),
+		}
+	}
+
+	return subscription, nil
+}
+
+// CreateSubscription creates a subscription. Without a secret a random one is generated.
+// The returned subscription is the only one that includes the secret.
+func CreateSubscription(subscription schemas.WebhookSubscription) (schemas.WebhookSubscription, error) {
+	client := db.Connect()
+
+	subscription.Url = strings.TrimSpace(subscription.Url)
+	if err := validateSubscription(subscription.Url, subscription.EventTypes); err != nil {
+		return schemas.WebhookSubscription{}, err
+	}
+
+	if subscription.Secret == "" {
+		subscription.Secret = "whsec_" + randomHex(24)
+	} else if len(subscription.Secret) < 16 {
+		return schemas.WebhookSubscription{}, &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "secret must be at least 16 characters",
+			Details: fmt.Sprintf("Webhook subscription for %s has a secret of %d characters", subscription.Url, len(subscription.Secret)),
+		}
+	}
+
+	data, _, err := client.
+		From("webhook_subscriptions").
+		Insert(map[string]interface{}{
+			"url":         subscription.Url,
+			"secret":      subscription.Secret,
+			"event_types": subscription.EventTypes,
+			"active":      subscription.Active,
+			"description": subscription.Description,
+			"created_at":  utils.GetCurrentISODate(),
+			"updated_at":  utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while creating the webhook subscription"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.WebhookSubscription{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error creating webhook subscription for %s: %v", subscription.Url, err),
+		}
+	}
+
+	var createdSubscription schemas.WebhookSubscription
+	err = json.Unmarshal(data, &createdSubscription)
+	if err != nil {
+		return schemas.WebhookSubscription{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook subscription data",
+			Details: fmt.Sprintf("Error parsing webhook subscription data while creating subscription: %v", err),
+		}
+	}
+
+	return createdSubscription, nil
+}
+
+func UpdateSubscription(id int8, updates map[string]interface{}) (schemas.WebhookSubscription, error) {
+	client := db.Connect()
+
+	current, err := GetSubscription(id)
+	if err != nil {
+		return schemas.WebhookSubscription{}, err
+	}
+
+	subscriptionUrl := current.Url
+	if value, exists := updates["url"]; exists {
+		urlStr, _ := value.(string)
+		subscriptionUrl = strings.TrimSpace(urlStr)
+		updates["url"] = subscriptionUrl
+	}
+
+	types := current.EventTypes
+	if value, exists := updates["event_types"]; exists {
+		typeList, ok := value.([]interface{})
+		if !ok {
+			return schemas.WebhookSubscription{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "event_types must be an array of strings",
+				Details: fmt.Sprintf("Invalid event types %v for webhook subscription %d", value, id),
+			}
+		}
+
+		types = []string{}
+		for _, eventType := range typeList {
+			eventTypeStr, _ := eventType.(string)
+			types = append(types, eventTypeStr)
+		}
+	}
+
+	if err := validateSubscription(subscriptionUrl, types); err != nil {
+		return schemas.WebhookSubscription{}, err
+	}
+
+	if secret, exists := updates["secret"]; exists {
+		if secretStr, ok := secret.(string); !ok || len(secretStr) < 16 {
+			return schemas.WebhookSubscription{}, &schemas.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: "secret must be at least 16 characters",
+				Details: fmt.Sprintf("Invalid secret for webhook subscription %d", id),
+			}
+		}
+	}
+
+	// Add updated_at field
+	updates["updated_at"] = utils.GetCurrentISODate()
+
+	_, _, err = client.
+		From("webhook_subscriptions").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", id)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while updating the webhook subscription"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Webhook subscription not found"
+			}
+		}
+
+		return schemas.WebhookSubscription{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error updating webhook subscription with ID %d: %v", id, err),
+		}
+	}
+
+	return GetSubscription(id)
+}
+
+// DeleteSubscription deletes the subscription and its delivery log
+func DeleteSubscription(id int8) error {
+	client := db.Connect()
+	idStr := fmt.Sprintf("%d", id)
+
+	_, _, err := client.
+		From("webhook_subscriptions").
+		Delete("", "").
+		Eq("id", idStr).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while deleting the webhook subscription"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Webhook subscription not found"
+			}
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error deleting webhook subscription with ID %d: %v", id, err),
+		}
+	}
+
+	return nil
+}
+
+// queueDeliveries queues the event for the subscriptions
+func queueDeliveries(event schemas.Event, subscriptionIds []int8) ([]schemas.WebhookDelivery, error) {
+	client := db.Connect()
+
+	rows := make([]map[string]interface{}, 0, len(subscriptionIds))
+	for _, subscriptionId := range subscriptionIds {
+		rows = append(rows, map[string]interface{}{
+			"subscription_id": subscriptionId,
+			"event_id":        event.Id,
+			"event_type":      event.Type,
+			"payload":         event,
+			"status":          schemas.WebhookDeliveryPending,
+			"next_attempt_at": utils.GetCurrentISODate(),
+			"created_at":      utils.GetCurrentISODate(),
+			"updated_at":      utils.GetCurrentISODate(),
+		})
+	}
+
+	data, _, err := client.
+		From("webhook_deliveries").
+		Insert(rows, false, "", "", "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while queueing webhook deliveries"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error queueing %s event %s for %d subscriptions: %v", event.Type, event.Id, len(subscriptionIds), err),
+		}
+	}
+
+	var deliveries []schemas.WebhookDelivery
+	err = json.Unmarshal(data, &deliveries)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook delivery data",
+			Details: fmt.Sprintf("Error parsing queued deliveries of event %s: %v", event.Id, err),
+		}
+	}
+
+	return deliveries, nil
+}
+
+// PublishEvent queues the event for every active subscription to its type
+func PublishEvent(event schemas.Event) ([]schemas.WebhookDelivery, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("webhook_subscriptions").
+		Select("id", "", false).
+		Eq("active", "true").
+		Overlaps("event_types", []string{event.Type, AllEvents}).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving webhook subscriptions"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving the subscriptions to %s: %v", event.Type, err),
+		}
+	}
+
+	var subscriptions []struct {
+		Id int8 `json:"id"`
+	}
+	err = json.Unmarshal(data, &subscriptions)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook subscription data",
+			Details: fmt.Sprintf("Error parsing the subscriptions to %s: %v", event.Type, err),
+		}
+	}
+
+	if len(subscriptions) == 0 {
+		return []schemas.WebhookDelivery{}, nil
+	}
+
+	subscriptionIds := make([]int8, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscriptionIds = append(subscriptionIds, subscription.Id)
+	}
+
+	return queueDeliveries(event, subscriptionIds)
+}
+
+// PingSubscription queues a webhook.ping event for the subscription only, to test the receiver
+func PingSubscription(id int8) (schemas.WebhookDelivery, error) {
+	subscription, err := GetSubscription(id)
+	if err != nil {
+		return schemas.WebhookDelivery{}, err
+	}
+
+	// Pings are not recorded in the outbox, so they get an ID of their own
+	event := schemas.Event{
+		Id:        "ping_" + randomHex(16),
+		Type:      schemas.EventWebhookPing,
+		CreatedAt: utils.GetCurrentISODate(),
+		Data: map[string]interface{}{
+			"subscription_id": subscription.Id,
+			"url":             subscription.Url,
+		},
+	}
+
+	deliveries, err := queueDeliveries(event, []int8{subscription.Id})
+	if err != nil {
+		return schemas.WebhookDelivery{}, err
+	}
+
+	return deliveries[0], nil
+}
+
+// GetDeliveries returns the latest deliveries of the subscription, newest first, optionally filtered by status
+func GetDeliveries(subscriptionId int8, status string, limit int) ([]schemas.WebhookDelivery, error) {
+	client := db.Connect()
+
+	query := client.
+		From("webhook_deliveries").
+		Select("*", "", false).
+		Eq("subscription_id", fmt.Sprintf("%d", subscriptionId))
+
+	if status != "" {
+		query = query.Eq("status", status)
+	}
+
+	data, _, err := query.
+		Order("id", &postgrest.OrderOpts{Ascending: false}).
+		Limit(limit, "").
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving webhook deliveries"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving deliveries of webhook subscription %d: %v", subscriptionId, err),
+		}
+	}
+
+	var deliveries []schemas.WebhookDelivery
+	err = json.Unmarshal(data, &deliveries)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook delivery data",
+			Details: fmt.Sprintf("Error parsing deliveries of webhook subscription %d: %v", subscriptionId, err),
+		}
+	}
+
+	// We make sure that an empty array is returned instead of null
+	if deliveries == nil {
+		deliveries = []schemas.WebhookDelivery{}
+	}
+
+	return deliveries, nil
+}
+
+func GetDelivery(subscriptionId int8, deliveryId int64) (schemas.WebhookDelivery, error) {
+	client := db.Connect()
+
+	data, _, err := client.
+		From("webhook_deliveries").
+		Select("*", "", false).
+		Eq("id", fmt.Sprintf("%d", deliveryId)).
+		Eq("subscription_id", fmt.Sprintf("%d", subscriptionId)).
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while retrieving the webhook delivery"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+
+			if code == http.StatusNotFound {
+				message = "Webhook delivery not found"
+			}
+		}
+
+		return schemas.WebhookDelivery{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error retrieving delivery %d of webhook subscription %d: %v", deliveryId, subscriptionId, err),
+		}
+	}
+
+	var delivery schemas.WebhookDelivery
+	err = json.Unmarshal(data, &delivery)
+	if err != nil {
+		return schemas.WebhookDelivery{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook delivery data",
+			Details: fmt.Sprintf("Error parsing webhook delivery data for ID %d: %v", deliveryId, err),
+		}
+	}
+
+	return delivery, nil
+}
+
+// ReplayDelivery queues the event of a delivery again as a new delivery, with the same event ID so
+// receivers can recognise it. The original delivery and its log stay as they are.
+func ReplayDelivery(subscriptionId int8, deliveryId int64) (schemas.WebhookDelivery, error) {
+	client := db.Connect()
+
+	original, err := GetDelivery(subscriptionId, deliveryId)
+	if err != nil {
+		return schemas.WebhookDelivery{}, err
+	}
+
+	data, _, err := client.
+		From("webhook_deliveries").
+		Insert(map[string]interface{}{
+			"subscription_id": original.SubscriptionId,
+			"event_id":        original.EventId,
+			"event_type":      original.EventType,
+			"payload":         original.Payload,
+			"status":          schemas.WebhookDeliveryPending,
+			"next_attempt_at": utils.GetCurrentISODate(),
+			"replay_of":       original.Id,
+			"created_at":      utils.GetCurrentISODate(),
+			"updated_at":      utils.GetCurrentISODate(),
+		}, false, "", "", "").
+		Single().
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while replaying the webhook delivery"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return schemas.WebhookDelivery{}, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error replaying delivery %d of webhook subscription %d: %v", deliveryId, subscriptionId, err),
+		}
+	}
+
+	var replay schemas.WebhookDelivery
+	err = json.Unmarshal(data, &replay)
+	if err != nil {
+		return schemas.WebhookDelivery{}, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook delivery data",
+			Details: fmt.Sprintf("Error parsing the replay of delivery %d: %v", deliveryId, err),
+		}
+	}
+
+	return replay, nil
+}
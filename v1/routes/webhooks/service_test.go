@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"testing"
+)
+
+func TestValidateSubscriptionUrl(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_URLS", "")
+
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{url: "https://93.184.215.14/hooks", valid: true},
+		{url: "http://[2606:4700:4700::1111]:8080/hooks", valid: true},
+		{url: "ftp://93.184.215.14/hooks", valid: false},
+		{url: "/hooks", valid: false},
+		{url: "https://localhost/hooks", valid: false},
+		{url: "https://LOCALHOST./hooks", valid: false},
+		{url: "https://api.localhost/hooks", valid: false},
+		{url: "http://127.0.0.1:8080/hooks", valid: false},
+		{url: "http://[::1]/hooks", valid: false},
+		{url: "http://10.0.0.5/hooks", valid: false},
+		{url: "http://172.16.3.4/hooks", valid: false},
+		{url: "http://192.168.1.10/hooks", valid: false},
+		{url: "http://169.254.169.254/latest/meta-data", valid: false},
+		{url: "http://[fe80::1]/hooks", valid: false},
+		{url: "http://[fd00::1]/hooks", valid: false},
+		{url: "http://0.0.0.0/hooks", valid: false},
+		{url: "http://224.0.0.1/hooks", valid: false},
+	}
+
+	for _, test := range tests {
+		err := validateSubscription(test.url, []string{AllEvents})
+		if test.valid && err != nil {
+			t.Errorf("validateSubscription(%s) returned error: %v", test.url, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("validateSubscription(%s) returned no error", test.url)
+		}
+	}
+}
+
+func TestValidateSubscriptionAllowPrivate(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_URLS", "true")
+
+	for _, url := range []string{"http://localhost:9000/hooks", "http://10.0.0.5/hooks"} {
+		if err := validateSubscription(url, []string{AllEvents}); err != nil {
+			t.Errorf("validateSubscription(%s) with private URLs allowed returned error: %v", url, err)
+		}
+	}
+
+	if err := validateSubscription("ftp://10.0.0.5/hooks", []string{AllEvents}); err == nil {
+		t.Error("validateSubscription of an ftp URL with private URLs allowed returned no error")
+	}
+}
+
+func TestGuardDial(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_URLS", "")
+
+	if err := guardDial("tcp4", "93.184.215.14:443", nil); err != nil {
+		t.Errorf("guardDial of a public address returned error: %v", err)
+	}
+	for _, address := range []string{"127.0.0.1:80", "169.254.169.254:80", "[::1]:443", "10.1.2.3:8080"} {
+		if err := guardDial("tcp", address, nil); err == nil {
+			t.Errorf("guardDial(%s) returned no error", address)
+		}
+	}
+}
+
+func TestValidateSubscriptionEventTypes(t *testing.T) {
+	if err := validateSubscription("https://93.184.215.14/hooks", nil); err == nil {
+		t.Error("validateSubscription without event types returned no error")
+	}
+	if err := validateSubscription("https://93.184.215.14/hooks", []string{"item.unknown"}); err == nil {
+		t.Error("validateSubscription with an unknown event type returned no error")
+	}
+}
@@ -0,0 +1,52 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers sent with every delivery
+const (
+	HeaderEventId   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm of the signature header
+const signaturePrefix = "sha256="
+
+// SignPayload returns the signature header value of a delivery: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" with the secret of the subscription. Signing the timestamp lets
+// receivers reject old deliveries that are sent again by someone else.
+func SignPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature of a received delivery, and that its timestamp is within tolerance.
+// A tolerance of 0 does not check the timestamp.
+func VerifySignature(secret string, timestampHeader string, signatureHeader string, body []byte, tolerance time.Duration) bool {
+	timestamp, err := strconv.ParseInt(strings.TrimSpace(timestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return false
+		}
+	}
+
+	expected := SignPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signatureHeader)))
+}
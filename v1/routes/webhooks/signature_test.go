@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignPayload(t *testing.T) {
+	body := []byte(`{"id":1}`)
+
+	// HMAC-SHA256 of `1700000000.{"id":1}` with the key "secret"
+	const want = "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+	if got := SignPayload("secret", 1700000000, body); got != want {
+		t.Fatalf("SignPayload = %s, want %s", got, want)
+	}
+
+	if SignPayload("other", 1700000000, body) == want {
+		t.Error("SignPayload does not depend on the secret")
+	}
+	if SignPayload("secret", 1700000001, body) == want {
+		t.Error("SignPayload does not depend on the timestamp")
+	}
+	if SignPayload("secret", 1700000000, []byte(`{"id":2}`)) == want {
+		t.Error("SignPayload does not depend on the body")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now().Unix()
+	old := now - 600
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		tolerance time.Duration
+		valid     bool
+	}{
+		{name: "valid", timestamp: strconv.FormatInt(now, 10), signature: SignPayload("secret", now, body), tolerance: time.Minute, valid: true},
+		{name: "surrounding spaces", timestamp: " " + strconv.FormatInt(now, 10), signature: SignPayload("secret", now, body) + " ", tolerance: time.Minute, valid: true},
+		{name: "other secret", timestamp: strconv.FormatInt(now, 10), signature: SignPayload("other", now, body), tolerance: time.Minute, valid: false},
+		{name: "other timestamp", timestamp: strconv.FormatInt(now+1, 10), signature: SignPayload("secret", now, body), tolerance: time.Minute, valid: false},
+		{name: "invalid timestamp", timestamp: "yesterday", signature: SignPayload("secret", now, body), tolerance: time.Minute, valid: false},
+		{name: "too old", timestamp: strconv.FormatInt(old, 10), signature: SignPayload("secret", old, body), tolerance: time.Minute, valid: false},
+		{name: "old without tolerance", timestamp: strconv.FormatInt(old, 10), signature: SignPayload("secret", old, body), tolerance: 0, valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := VerifySignature("secret", test.timestamp, test.signature, body, test.tolerance); got != test.valid {
+				t.Errorf("VerifySignature = %v, want %v", got, test.valid)
+			}
+		})
+	}
+}
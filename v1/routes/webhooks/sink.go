@@ -0,0 +1,18 @@
+package webhooks
+
+import (
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// Sink queues the events of the outbox as webhook deliveries.
+// An event that is dispatched again is queued again, so receivers should ignore event IDs they have seen.
+type Sink struct{}
+
+func (Sink) Name() string {
+	return "webhooks"
+}
+
+func (Sink) Deliver(event schemas.Event) error {
+	_, err := PublishEvent(event)
+	return err
+}
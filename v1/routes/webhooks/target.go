@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+// resolveTimeout is how long the host of a subscription URL may take to resolve
+const resolveTimeout = 5 * time.Second
+
+// AllowPrivateTargets returns whether webhooks may be sent to loopback, private and link-local addresses,
+// e.g. WEBHOOK_ALLOW_PRIVATE_URLS=true for receivers on the same network. They are blocked by default,
+// so a subscription can not make the server call its own network or the metadata service of the cloud.
+func AllowPrivateTargets() bool {
+	allow, err := strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE_URLS"))
+	return err == nil && allow
+}
+
+// isBlockedIP reports whether an address is outside the public internet
+func isBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified()
+}
+
+// validateTargetHost checks that the host of a subscription URL resolves to public addresses only
+func validateTargetHost(subscriptionUrl string, host string) error {
+	invalid := func(details string) error {
+		return &schemas.CustomError{
+			Code:    http.StatusBadRequest,
+			Message: "url must point to a public host",
+			Details: fmt.Sprintf("Invalid webhook URL %q: %s", subscriptionUrl, details),
+		}
+	}
+
+	if AllowPrivateTargets() {
+		return nil
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return invalid("localhost is not allowed")
+	}
+
+	if ip := net.ParseIP(name); ip != nil {
+		if isBlockedIP(ip) {
+			return invalid(fmt.Sprintf("address %s is not public", ip))
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, name)
+	if err != nil {
+		return invalid(fmt.Sprintf("host %s could not be resolved: %v", name, err))
+	}
+	for _, address := range addresses {
+		if isBlockedIP(address.IP) {
+			return invalid(fmt.Sprintf("host %s resolves to %s, which is not public", name, address.IP))
+		}
+	}
+
+	return nil
+}
+
+// guardDial refuses connections to addresses that are not public. The host of a subscription can resolve to
+// another address after it was validated, and a receiver can redirect, so every connection is checked.
+func guardDial(network string, address string, _ syscall.RawConn) error {
+	if AllowPrivateTargets() {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isBlockedIP(ip) {
+		return fmt.Errorf("webhook delivery to %s is not allowed, the address is not public", host)
+	}
+	return nil
+}
+
+// newDeliveryClient returns the HTTP client of the delivery worker, which only connects to public addresses
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{Timeout: deliveryTimeout, Control: guardDial}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialled instead of the receiver
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: deliveryTimeout, Transport: transport}
+}
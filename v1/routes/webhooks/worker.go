@@ -0,0 +1,264 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	db "github.com/MattyMcF4tty/InventoryManager-backend/v1/database"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/utils"
+)
+
+const (
+	// DefaultDeliveryInterval is used when WEBHOOK_DELIVERY_INTERVAL is not set
+	DefaultDeliveryInterval = 5 * time.Second
+	// DefaultRetryBase is used when WEBHOOK_RETRY_BASE is not set
+	DefaultRetryBase = 30 * time.Second
+	// DefaultMaxAttempts is used when WEBHOOK_MAX_ATTEMPTS is not set
+	DefaultMaxAttempts = 8
+	// maxRetryDelay caps the exponential backoff
+	maxRetryDelay = 6 * time.Hour
+	// deliveryBatchSize is the number of deliveries claimed at a time
+	deliveryBatchSize = 50
+	// deliveryTimeout is how long a receiver gets to answer
+	deliveryTimeout = 10 * time.Second
+	// maxErrorLength is the number of characters of a failed response that is kept in the log
+	maxErrorLength = 500
+)
+
+// GetDeliveryInterval returns how often due deliveries are sent, e.g. WEBHOOK_DELIVERY_INTERVAL=10s
+func GetDeliveryInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("WEBHOOK_DELIVERY_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultDeliveryInterval
+}
+
+// GetRetryBase returns the delay before the first retry, which doubles with every attempt
+func GetRetryBase() time.Duration {
+	if base, err := time.ParseDuration(os.Getenv("WEBHOOK_RETRY_BASE")); err == nil && base > 0 {
+		return base
+	}
+	return DefaultRetryBase
+}
+
+// GetMaxAttempts returns the number of attempts after which a delivery fails
+func GetMaxAttempts() int {
+	if attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		return attempts
+	}
+	return DefaultMaxAttempts
+}
+
+// RetryDelay returns the delay after the given number of failed attempts: base, 2*base, 4*base and so on
+func RetryDelay(attempts int, base time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// claimedDelivery is a delivery with the URL and secret of its subscription
+type claimedDelivery struct {
+	schemas.WebhookDelivery
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// claimDeliveries leases the due deliveries. The lease outlasts the timeout of every delivery in the batch.
+func claimDeliveries() ([]claimedDelivery, error) {
+	client := db.Connect()
+
+	data, err := db.Rpc(client, "claim_webhook_deliveries", map[string]interface{}{
+		"batch_size":    deliveryBatchSize,
+		"lease_seconds": int(deliveryTimeout.Seconds())*deliveryBatchSize + 60,
+	})
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while claiming webhook deliveries"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return nil, &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error claiming webhook deliveries: %v", err),
+		}
+	}
+
+	var claimed []claimedDelivery
+	err = json.Unmarshal(data, &claimed)
+	if err != nil {
+		return nil, &schemas.CustomError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to parse webhook delivery data",
+			Details: fmt.Sprintf("Error parsing claimed webhook deliveries: %v", err),
+		}
+	}
+
+	return claimed, nil
+}
+
+// sendDelivery posts the signed payload to the receiver.
+// It returns the status code of the response, if any, and an error unless the receiver answered 2xx.
+func sendDelivery(httpClient *http.Client, delivery claimedDelivery) (*int, error) {
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequest(http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "InventoryManager-Webhooks/1")
+	request.Header.Set(HeaderEventId, delivery.EventId)
+	request.Header.Set(HeaderEventType, delivery.EventType)
+	request.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.Id, 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, SignPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode >= 200 && statusCode < 300 {
+		return &statusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorLength))
+	return &statusCode, fmt.Errorf("receiver answered %d: %s", statusCode, body)
+}
+
+// recordAttempt stores the result of an attempt. A failed attempt is retried with exponential backoff
+// until the maximum number of attempts is reached.
+func recordAttempt(delivery claimedDelivery, statusCode *int, sendErr error) error {
+	client := db.Connect()
+
+	attempts := delivery.Attempts + 1
+	now := time.Now().UTC()
+
+	updates := map[string]interface{}{
+		"attempts":         attempts,
+		"last_attempt_at":  now.Format(time.RFC3339),
+		"last_status_code": statusCode,
+		"updated_at":       utils.GetCurrentISODate(),
+	}
+
+	if sendErr == nil {
+		updates["status"] = schemas.WebhookDeliverySucceeded
+		updates["delivered_at"] = now.Format(time.RFC3339)
+		updates["last_error"] = nil
+	} else {
+		lastError := sendErr.Error()
+		if len(lastError) > maxErrorLength {
+			lastError = lastError[:maxErrorLength]
+		}
+		updates["last_error"] = lastError
+
+		if attempts >= GetMaxAttempts() {
+			updates["status"] = schemas.WebhookDeliveryFailed
+		} else {
+			updates["next_attempt_at"] = now.Add(RetryDelay(attempts, GetRetryBase())).Format(time.RFC3339)
+		}
+	}
+
+	_, _, err := client.
+		From("webhook_deliveries").
+		Update(updates, "", "").
+		Eq("id", fmt.Sprintf("%d", delivery.Id)).
+		Execute()
+
+	if err != nil {
+		// Set the default error code and message
+		code := http.StatusInternalServerError
+		message := "An error occurred while recording the webhook delivery"
+
+		// Check if the error is a Postgres error
+		// If true we update the code and message accordingly
+		if status := utils.PostgresToHTTPError(err); status != nil {
+			code = *status
+			message = utils.PostgresErrorMessage(err, message)
+		}
+
+		return &schemas.CustomError{
+			Code:    code,
+			Message: message,
+			Details: fmt.Sprintf("Error recording attempt %d of webhook delivery %d: %v", attempts, delivery.Id, err),
+		}
+	}
+
+	return nil
+}
+
+// DeliverDue sends the due deliveries and returns how many were sent successfully
+func DeliverDue(httpClient *http.Client) (int, error) {
+	claimed, err := claimDeliveries()
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, delivery := range claimed {
+		statusCode, sendErr := sendDelivery(httpClient, delivery)
+		if sendErr == nil {
+			succeeded++
+		} else {
+			slog.Warn("Webhook delivery failed", "delivery_id", delivery.Id, "url", delivery.Url, "attempt", delivery.Attempts+1, "error", sendErr)
+		}
+
+		if err := recordAttempt(delivery, statusCode, sendErr); err != nil {
+			return succeeded, err
+		}
+	}
+
+	return succeeded, nil
+}
+
+// StartDeliveryWorker sends the due deliveries every interval, until the context is done
+func StartDeliveryWorker(ctx context.Context, interval time.Duration) {
+	httpClient := newDeliveryClient()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := DeliverDue(httpClient)
+		if err != nil {
+			if utils.IsCustomError(err) {
+				slog.Error("Failed to deliver webhooks", "error", err.(*schemas.CustomError).Details)
+			} else {
+				slog.Error("Unexpected error when delivering webhooks", "error", err)
+			}
+		} else if delivered > 0 {
+			slog.Info("Delivered webhooks", "count", delivered)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
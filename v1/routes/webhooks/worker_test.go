@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	base := 30 * time.Second
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: base},
+		{attempts: 1, want: base},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 8, want: 64 * time.Minute},
+		// 30s doubled 10 times is more than 8 hours, which is capped
+		{attempts: 11, want: maxRetryDelay},
+		{attempts: 1000, want: maxRetryDelay},
+	}
+
+	for _, test := range tests {
+		if got := RetryDelay(test.attempts, base); got != test.want {
+			t.Errorf("RetryDelay(%d, %s) = %s, want %s", test.attempts, base, got, test.want)
+		}
+	}
+}
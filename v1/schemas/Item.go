@@ -3,13 +3,31 @@ package schemas
 type Item struct {
 	Id            int8    `json:"id"`
 	Name          string  `json:"name"`
+	Sku           *string `json:"sku"`
 	Description   string  `json:"description"`
 	PurchasePrice float64 `json:"purchase_price"`
-	Quantity      int8    `json:"quantity"`
-	Category      string  `json:"category"`
-	ImageUrl      *string `json:"image_url,omitempty"`
-	SupplierId    int8    `json:"supplier_id"`
-	Notes         string  `json:"notes"`
+	// PurchasePriceMinor and PurchaseCurrency are leading, PurchasePrice is derived from them
+	PurchasePriceMinor *int64 `json:"purchase_price_minor,omitempty"`
+	PurchaseCurrency   string `json:"purchase_currency,omitempty"`
+	// PurchaseUnitCost is the exact cost per base unit in PurchaseCurrency, which can be a fraction of a
+	// minor unit. It is set by the database.
+	PurchaseUnitCost *float64 `json:"purchase_unit_cost,omitempty"`
+	Quantity         int      `json:"quantity"`
+	// ReservedQuantity and AvailableQuantity are set by the database, Quantity is the stock on hand
+	ReservedQuantity  *int    `json:"reserved_quantity,omitempty"`
+	AvailableQuantity *int    `json:"available_quantity,omitempty"`
+	BaseUnit          string  `json:"base_unit,omitempty"`
+	Category          string  `json:"category"`
+	CategoryId        *int8   `json:"category_id"`
+	ImageUrl          *string `json:"image_url,omitempty"`
+	Location          *string `json:"location"`
+	SupplierId        int8    `json:"supplier_id"`
+	Notes             string  `json:"notes"`
+	Serialized        bool    `json:"serialized"`
+
+	Tags       []string               `json:"tags"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Barcodes   []ItemBarcode          `json:"barcodes,omitempty"`
 
 	CreatedAt string  `json:"created_at"`
 	UpdatedAt string  `json:"updated_at"`
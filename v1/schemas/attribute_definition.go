@@ -0,0 +1,21 @@
+package schemas
+
+const (
+	AttributeTypeString = "string"
+	AttributeTypeNumber = "number"
+	AttributeTypeEnum   = "enum"
+	AttributeTypeDate   = "date"
+)
+
+type AttributeDefinition struct {
+	Id       int8     `json:"id"`
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	DeletedAt *string `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,13 @@
+package schemas
+
+type Category struct {
+	Id          int8       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ParentId    *int8      `json:"parent_id"`
+	Children    []Category `json:"children,omitempty"`
+
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	DeletedAt *string `json:"deleted_at,omitempty"`
+}
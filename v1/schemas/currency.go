@@ -0,0 +1,31 @@
+package schemas
+
+type Currency struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// MinorUnit is the number of decimals of the minor unit, e.g. 2 for EUR and 0 for JPY
+	MinorUnit int `json:"minor_unit"`
+}
+
+// ExchangeRate says that one BaseCurrency is worth Rate QuoteCurrency from the effective date on
+type ExchangeRate struct {
+	Id            int8    `json:"id"`
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+	EffectiveDate string  `json:"effective_date"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// Money is an amount in the minor unit of the currency, e.g. cents for EUR
+type Money struct {
+	AmountMinor int64  `json:"amount_minor"`
+	Currency    string `json:"currency"`
+}
+
+type CurrencyConversion struct {
+	From Money   `json:"from"`
+	To   Money   `json:"to"`
+	Rate float64 `json:"rate"`
+	Date string  `json:"date"`
+}
@@ -0,0 +1,15 @@
+package schemas
+
+type Customer struct {
+	Id        int8    `json:"id"`
+	Name      string  `json:"name"`
+	Email     *string `json:"email"`
+	Phone     *string `json:"phone"`
+	Address   *string `json:"address"`
+	VatNumber *string `json:"vat_number"`
+	Notes     *string `json:"notes"`
+
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	DeletedAt *string `json:"deleted_at,omitempty"`
+}
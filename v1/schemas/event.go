@@ -0,0 +1,61 @@
+package schemas
+
+import "encoding/json"
+
+const (
+	EventItemCreated     = "item.created"
+	EventItemUpdated     = "item.updated"
+	EventItemDeleted     = "item.deleted"
+	EventStockChanged    = "stock.changed"
+	EventStockLow        = "stock.low"
+	EventSupplierCreated = "supplier.created"
+	EventSupplierUpdated = "supplier.updated"
+	EventSupplierDeleted = "supplier.deleted"
+	// EventWebhookPing is only sent to test a webhook subscription
+	EventWebhookPing = "webhook.ping"
+)
+
+const (
+	EventEntityItem     = "item"
+	EventEntitySupplier = "supplier"
+)
+
+// Event is something that happened to the inventory, as it is sent to sinks and webhooks
+type Event struct {
+	Id         string      `json:"id"`
+	Type       string      `json:"type"`
+	EntityType string      `json:"entity_type,omitempty"`
+	EntityId   int64       `json:"entity_id,omitempty"`
+	CreatedAt  string      `json:"created_at"`
+	Data       interface{} `json:"data"`
+}
+
+// OutboxEvent is an event recorded in the outbox, in the same transaction as the change it is about.
+// DeliveredTo holds the sinks that received it, and it is dispatched once every sink received it.
+type OutboxEvent struct {
+	Id            int64           `json:"id"`
+	Type          string          `json:"type"`
+	EntityType    string          `json:"entity_type"`
+	EntityId      int64           `json:"entity_id"`
+	Payload       json.RawMessage `json:"payload"`
+	DeliveredTo   []string        `json:"delivered_to"`
+	DispatchedAt  *string         `json:"dispatched_at"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt string          `json:"next_attempt_at"`
+	LastError     *string         `json:"last_error"`
+
+	CreatedAt string `json:"created_at"`
+}
+
+// StockLevel is the data of the stock.changed and stock.low events
+type StockLevel struct {
+	ItemId            int8    `json:"item_id"`
+	Name              string  `json:"name"`
+	Sku               *string `json:"sku"`
+	CategoryId        *int8   `json:"category_id"`
+	Quantity          int     `json:"quantity"`
+	PreviousQuantity  int     `json:"previous_quantity"`
+	ReservedQuantity  int     `json:"reserved_quantity"`
+	AvailableQuantity int     `json:"available_quantity"`
+	LowStockThreshold int     `json:"low_stock_threshold"`
+}
@@ -0,0 +1,16 @@
+package schemas
+
+const (
+	SymbologyEAN13   = "ean13"
+	SymbologyUPCA    = "upca"
+	SymbologyCode128 = "code128"
+)
+
+type ItemBarcode struct {
+	Id        int64  `json:"id"`
+	ItemId    int8   `json:"item_id"`
+	Code      string `json:"code"`
+	Symbology string `json:"symbology"`
+
+	CreatedAt string `json:"created_at"`
+}
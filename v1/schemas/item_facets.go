@@ -0,0 +1,26 @@
+package schemas
+
+type ItemFacets struct {
+	Categories  []ValueFacet     `json:"categories"`
+	Suppliers   []SupplierFacet  `json:"suppliers"`
+	PriceBands  []PriceBandFacet `json:"price_bands"`
+	StockStatus []ValueFacet     `json:"stock_status"`
+}
+
+type ValueFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type SupplierFacet struct {
+	Id    int8    `json:"id"`
+	Name  *string `json:"name"`
+	Count int64   `json:"count"`
+}
+
+type PriceBandFacet struct {
+	Min      float64  `json:"min"`
+	Max      *float64 `json:"max"`
+	Currency string   `json:"currency"`
+	Count    int64    `json:"count"`
+}
@@ -0,0 +1,7 @@
+package schemas
+
+type ItemListFilters struct {
+	CategoryId *int8
+	Tags       []string
+	Attributes map[string]string
+}
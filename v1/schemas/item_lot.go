@@ -0,0 +1,34 @@
+package schemas
+
+type ItemLot struct {
+	Id         int64   `json:"id"`
+	ItemId     int8    `json:"item_id"`
+	LotNumber  string  `json:"lot_number"`
+	ExpiryDate *string `json:"expiry_date"`
+	Quantity   int     `json:"quantity"`
+	ReceivedAt string  `json:"received_at"`
+
+	// Item is only included when listing lots across items
+	Item *ItemSummary `json:"item,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ItemSummary struct {
+	Id   int8    `json:"id"`
+	Name string  `json:"name"`
+	Sku  *string `json:"sku"`
+}
+
+type LotAllocation struct {
+	Lot      ItemLot `json:"lot"`
+	Quantity int     `json:"quantity"`
+}
+
+type LotIssueSuggestion struct {
+	Requested   int             `json:"requested"`
+	Allocated   int             `json:"allocated"`
+	Shortfall   int             `json:"shortfall"`
+	Allocations []LotAllocation `json:"allocations"`
+}
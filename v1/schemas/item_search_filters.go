@@ -0,0 +1,10 @@
+package schemas
+
+type ItemSearchFilters struct {
+	Category    *string  `json:"filter_category,omitempty"`
+	CategoryId  *int8    `json:"filter_category_id,omitempty"`
+	SupplierId  *int8    `json:"filter_supplier_id,omitempty"`
+	MinPrice    *float64 `json:"filter_min_price,omitempty"`
+	MaxPrice    *float64 `json:"filter_max_price,omitempty"`
+	StockStatus *string  `json:"filter_stock_status,omitempty"`
+}
@@ -0,0 +1,8 @@
+package schemas
+
+type ItemSearchResult struct {
+	Item
+	Rank float64 `json:"rank"`
+	// Highlights are the matching fields as HTML-escaped text, with the matches wrapped in <mark> tags
+	Highlights map[string]string `json:"highlights"`
+}
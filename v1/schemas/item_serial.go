@@ -0,0 +1,36 @@
+package schemas
+
+const (
+	SerialStatusInStock  = "in_stock"
+	SerialStatusIssued   = "issued"
+	SerialStatusInRepair = "in_repair"
+	SerialStatusRetired  = "retired"
+)
+
+type ItemSerial struct {
+	Id           int64   `json:"id"`
+	ItemId       int8    `json:"item_id"`
+	SerialNumber string  `json:"serial_number"`
+	Status       string  `json:"status"`
+	Location     *string `json:"location"`
+	Note         *string `json:"note"`
+
+	// Item is only included when listing serials across items
+	Item *ItemSummary `json:"item,omitempty"`
+	// History is only included when retrieving a single serial
+	History []ItemSerialEvent `json:"history,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ItemSerialEvent struct {
+	Id               int64   `json:"id"`
+	SerialId         int64   `json:"serial_id"`
+	Status           string  `json:"status"`
+	PreviousStatus   *string `json:"previous_status"`
+	Location         *string `json:"location"`
+	PreviousLocation *string `json:"previous_location"`
+	Note             *string `json:"note"`
+	CreatedAt        string  `json:"created_at"`
+}
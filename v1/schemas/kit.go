@@ -0,0 +1,44 @@
+package schemas
+
+type KitComponent struct {
+	Id              int8 `json:"id"`
+	KitItemId       int8 `json:"kit_item_id"`
+	ComponentItemId int8 `json:"component_item_id"`
+	Quantity        int  `json:"quantity"`
+
+	Component *KitComponentItem `json:"component,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type KitComponentItem struct {
+	Id               int8    `json:"id"`
+	Name             string  `json:"name"`
+	Sku              *string `json:"sku"`
+	Quantity         int     `json:"quantity"`
+	PurchasePrice    float64 `json:"purchase_price"`
+	PurchaseUnitCost float64 `json:"purchase_unit_cost"`
+	PurchaseCurrency string  `json:"purchase_currency"`
+}
+
+type KitComponentAvailability struct {
+	ComponentItemId int8   `json:"component_item_id"`
+	Name            string `json:"name"`
+	PerKit          int    `json:"per_kit"`
+	OnHand          int    `json:"on_hand"`
+	Buildable       int    `json:"buildable"`
+}
+
+type KitAvailability struct {
+	KitItemId        int8                       `json:"kit_item_id"`
+	AvailableToBuild int                        `json:"available_to_build"`
+	Components       []KitComponentAvailability `json:"components"`
+}
+
+// KitOperation is the result of assembling or disassembling kits
+type KitOperation struct {
+	KitItemId int8            `json:"kit_item_id"`
+	Quantity  int             `json:"quantity"`
+	Movements []StockMovement `json:"movements"`
+}
@@ -0,0 +1,8 @@
+package schemas
+
+type CursorPage struct {
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
+	Limit      int     `json:"limit"`
+	Count      *int64  `json:"count,omitempty"`
+}
@@ -0,0 +1,12 @@
+package schemas
+
+// PurgeResult is the number of records removed by a purge, or that would be removed by a dry run.
+// Records older than Cutoff are purged.
+type PurgeResult struct {
+	Cutoff            string `json:"cutoff"`
+	DryRun            bool   `json:"dry_run"`
+	Items             int64  `json:"items"`
+	Suppliers         int64  `json:"suppliers"`
+	OutboxEvents      int64  `json:"outbox_events"`
+	WebhookDeliveries int64  `json:"webhook_deliveries"`
+}
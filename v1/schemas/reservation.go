@@ -0,0 +1,28 @@
+package schemas
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+	ReservationStatusFulfilled = "fulfilled"
+)
+
+// StockReservation holds stock of an item for an owner until it is released, fulfilled or expires
+type StockReservation struct {
+	Id            int64   `json:"id"`
+	ItemId        int8    `json:"item_id"`
+	Quantity      int     `json:"quantity"`
+	Owner         string  `json:"owner"`
+	Reason        *string `json:"reason"`
+	Status        string  `json:"status"`
+	ExpiresAt     *string `json:"expires_at"`
+	ReferenceType *string `json:"reference_type"`
+	ReferenceId   *int64  `json:"reference_id"`
+	ReleasedAt    *string `json:"released_at"`
+
+	// Item is only included when listing reservations across items
+	Item *ItemSummary `json:"item,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
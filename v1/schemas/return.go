@@ -0,0 +1,85 @@
+package schemas
+
+const (
+	ReturnTypeCustomer = "customer"
+	ReturnTypeSupplier = "supplier"
+)
+
+const (
+	ReturnStatusOpen      = "open"
+	ReturnStatusCompleted = "completed"
+	ReturnStatusCancelled = "cancelled"
+)
+
+const (
+	ReturnReasonDamaged        = "damaged"
+	ReturnReasonDefective      = "defective"
+	ReturnReasonWrongItem      = "wrong_item"
+	ReturnReasonNotAsDescribed = "not_as_described"
+	ReturnReasonUnwanted       = "unwanted"
+	ReturnReasonExpired        = "expired"
+	ReturnReasonOther          = "other"
+)
+
+const (
+	ReturnOutcomeRestock          = "restock"
+	ReturnOutcomeScrap            = "scrap"
+	ReturnOutcomeReturnToSupplier = "return_to_supplier"
+)
+
+// Return is a return authorisation of goods from a customer or to a supplier.
+// Customer returns can link to the sales order the goods were shipped on.
+type Return struct {
+	Id                     int8    `json:"id"`
+	Type                   string  `json:"type"`
+	Status                 string  `json:"status"`
+	CustomerId             *int8   `json:"customer_id"`
+	SalesOrderId           *int8   `json:"sales_order_id"`
+	SupplierId             *int8   `json:"supplier_id"`
+	PurchaseOrderReference *string `json:"purchase_order_reference"`
+	Reason                 string  `json:"reason"`
+	Notes                  *string `json:"notes"`
+	CompletedAt            *string `json:"completed_at"`
+	CancelledAt            *string `json:"cancelled_at"`
+
+	// Customer, Supplier and Lines are only included when getting a single return
+	Customer *CustomerSummary `json:"customer,omitempty"`
+	Supplier *SupplierSummary `json:"supplier,omitempty"`
+	Lines    []ReturnLine     `json:"lines,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ReturnLine is a returned quantity of an item. Outcome is set when the line is inspected.
+type ReturnLine struct {
+	Id               int64   `json:"id"`
+	ReturnId         int8    `json:"return_id"`
+	ItemId           int8    `json:"item_id"`
+	LotId            *int64  `json:"lot_id"`
+	Quantity         int     `json:"quantity"`
+	SalesOrderLineId *int64  `json:"sales_order_line_id"`
+	Reason           *string `json:"reason"`
+	Outcome          *string `json:"outcome"`
+	InspectionNote   *string `json:"inspection_note"`
+	InspectedBy      *string `json:"inspected_by"`
+	InspectedAt      *string `json:"inspected_at"`
+
+	Item *ItemSummary `json:"item,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ReturnInspection is the outcome of inspecting a return line
+type ReturnInspection struct {
+	Outcome     string  `json:"outcome"`
+	Note        *string `json:"inspection_note"`
+	InspectedBy *string `json:"inspected_by"`
+}
+
+// ReturnCompletion is the result of completing a return
+type ReturnCompletion struct {
+	Return    Return          `json:"return"`
+	Movements []StockMovement `json:"movements"`
+}
@@ -0,0 +1,52 @@
+package schemas
+
+const (
+	SalesOrderStatusDraft     = "draft"
+	SalesOrderStatusConfirmed = "confirmed"
+	SalesOrderStatusPicked    = "picked"
+	SalesOrderStatusShipped   = "shipped"
+	SalesOrderStatusCancelled = "cancelled"
+)
+
+// SalesOrder is an order of a customer. Prices are in minor units of Currency.
+type SalesOrder struct {
+	Id          int8    `json:"id"`
+	CustomerId  int8    `json:"customer_id"`
+	Status      string  `json:"status"`
+	Currency    string  `json:"currency"`
+	Reference   *string `json:"reference"`
+	Notes       *string `json:"notes"`
+	ConfirmedAt *string `json:"confirmed_at"`
+	PickedAt    *string `json:"picked_at"`
+	ShippedAt   *string `json:"shipped_at"`
+	CancelledAt *string `json:"cancelled_at"`
+
+	// Customer, Lines and TotalMinor are only included when getting a single order
+	Customer   *CustomerSummary `json:"customer,omitempty"`
+	Lines      []SalesOrderLine `json:"lines,omitempty"`
+	TotalMinor *int64           `json:"total_minor,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type CustomerSummary struct {
+	Id   int8   `json:"id"`
+	Name string `json:"name"`
+}
+
+type SalesOrderLine struct {
+	Id             int64 `json:"id"`
+	OrderId        int8  `json:"order_id"`
+	ItemId         int8  `json:"item_id"`
+	Quantity       int   `json:"quantity"`
+	UnitPriceMinor int64 `json:"unit_price_minor"`
+	// LotId is the lot to ship from, without it the lots that expire first are shipped.
+	// SerialNumbers are the serials to ship of a serialised item, which are set when shipping when left empty.
+	LotId         *int64       `json:"lot_id"`
+	SerialNumbers []string     `json:"serial_numbers"`
+	Item          *ItemSummary `json:"item,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
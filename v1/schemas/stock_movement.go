@@ -0,0 +1,33 @@
+package schemas
+
+const (
+	StockMovementReceipt        = "receipt"
+	StockMovementIssue          = "issue"
+	StockMovementAdjustment     = "adjustment"
+	StockMovementKitAssembly    = "kit_assembly"
+	StockMovementKitDisassembly = "kit_disassembly"
+	StockMovementCustomerReturn = "customer_return"
+	StockMovementSupplierReturn = "supplier_return"
+	StockMovementScrap          = "scrap"
+)
+
+// StockMovement is a change of the stock of an item.
+// A positive quantity adds stock and a negative quantity removes it.
+// Quantity is in the base unit of the item. When a movement is given in another unit,
+// Unit and UnitQuantity hold the original amount and UnitCost is converted to the base unit.
+// UnitCost is in Currency, which is set to the purchase currency of the item when recorded.
+type StockMovement struct {
+	Id            int64    `json:"id,omitempty"`
+	ItemId        int8     `json:"item_id"`
+	LotId         *int64   `json:"lot_id,omitempty"`
+	Quantity      int      `json:"quantity"`
+	Unit          *string  `json:"unit,omitempty"`
+	UnitQuantity  *float64 `json:"unit_quantity,omitempty"`
+	Type          string   `json:"type"`
+	UnitCost      *float64 `json:"unit_cost,omitempty"`
+	Currency      *string  `json:"currency,omitempty"`
+	ReferenceType *string  `json:"reference_type,omitempty"`
+	ReferenceId   *int64   `json:"reference_id,omitempty"`
+	Note          *string  `json:"note,omitempty"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+}
@@ -0,0 +1,112 @@
+package schemas
+
+const (
+	StocktakeStatusCounting  = "counting"
+	StocktakeStatusReview    = "review"
+	StocktakeStatusApproved  = "approved"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+const (
+	StocktakeScopeFull     = "full"
+	StocktakeScopeLocation = "location"
+	StocktakeScopeCategory = "category"
+)
+
+// The status of a count line follows from its counts
+const (
+	// StocktakeLineUncounted means nobody counted the line yet
+	StocktakeLineUncounted = "uncounted"
+	// StocktakeLineAgreed means all counters counted the same quantity
+	StocktakeLineAgreed = "agreed"
+	// StocktakeLineConflict means the counters disagree, and the line must be reviewed
+	StocktakeLineConflict = "conflict"
+	// StocktakeLineReviewed means the counted quantity was set during review
+	StocktakeLineReviewed = "reviewed"
+)
+
+type Stocktake struct {
+	Id         int8    `json:"id"`
+	Name       string  `json:"name"`
+	Scope      string  `json:"scope"`
+	Location   *string `json:"location"`
+	CategoryId *int8   `json:"category_id"`
+	Status     string  `json:"status"`
+	Note       *string `json:"note"`
+	ApprovedBy *string `json:"approved_by"`
+	ApprovedAt *string `json:"approved_at"`
+
+	// Summary is only included when getting a single stocktake
+	Summary *StocktakeSummary `json:"summary,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// StocktakeSummary counts the lines of a stocktake per status
+type StocktakeSummary struct {
+	Lines     int `json:"lines"`
+	Uncounted int `json:"uncounted"`
+	Agreed    int `json:"agreed"`
+	Conflicts int `json:"conflicts"`
+	Reviewed  int `json:"reviewed"`
+	// Variances is the number of counted lines where the final quantity differs from the expected quantity
+	Variances int `json:"variances"`
+}
+
+// StocktakeLine is a line of the count sheet.
+// ExpectedQuantity is the quantity when the stocktake was created and CountedQuantity is set during review.
+// Status, FinalQuantity and Variance follow from the counts.
+type StocktakeLine struct {
+	Id               int64            `json:"id"`
+	StocktakeId      int8             `json:"stocktake_id"`
+	ItemId           int8             `json:"item_id"`
+	LotId            *int64           `json:"lot_id"`
+	ExpectedQuantity int              `json:"expected_quantity"`
+	CountedQuantity  *int             `json:"counted_quantity"`
+	Item             *StocktakeItem   `json:"item,omitempty"`
+	Lot              *StocktakeLot    `json:"lot,omitempty"`
+	Counts           []StocktakeCount `json:"counts"`
+
+	Status        string `json:"status"`
+	FinalQuantity *int   `json:"final_quantity"`
+	Variance      *int   `json:"variance"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type StocktakeItem struct {
+	Id       int8    `json:"id"`
+	Name     string  `json:"name"`
+	Sku      *string `json:"sku"`
+	Location *string `json:"location"`
+	BaseUnit string  `json:"base_unit"`
+}
+
+type StocktakeLot struct {
+	Id         int8    `json:"id"`
+	LotNumber  string  `json:"lot_number"`
+	ExpiryDate *string `json:"expiry_date"`
+}
+
+type StocktakeCount struct {
+	Id        int64  `json:"id"`
+	LineId    int64  `json:"line_id"`
+	Counter   string `json:"counter"`
+	Quantity  int    `json:"quantity"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// StocktakeCountEntry is a counted quantity for a line, as entered by a counter
+type StocktakeCountEntry struct {
+	LineId   int64 `json:"line_id"`
+	Quantity int   `json:"quantity"`
+}
+
+// StocktakeApproval is the result of approving a stocktake
+type StocktakeApproval struct {
+	Stocktake Stocktake       `json:"stocktake"`
+	Movements []StockMovement `json:"movements"`
+}
@@ -0,0 +1,31 @@
+package schemas
+
+// SupplierItem is the price and terms at which a supplier sells an item.
+// The price is per Unit, which is the base unit of the item when empty.
+// PriceMinor is leading, Price is derived from it.
+type SupplierItem struct {
+	Id               int8    `json:"id"`
+	SupplierId       int8    `json:"supplier_id"`
+	ItemId           int8    `json:"item_id"`
+	SupplierSku      *string `json:"supplier_sku"`
+	Price            float64 `json:"price"`
+	PriceMinor       int64   `json:"price_minor"`
+	Currency         string  `json:"currency"`
+	Unit             *string `json:"unit"`
+	LeadTimeDays     int     `json:"lead_time_days"`
+	MinOrderQuantity int     `json:"min_order_quantity"`
+	IsPreferred      bool    `json:"is_preferred"`
+
+	// Supplier is included when listing the suppliers of an item
+	Supplier *SupplierSummary `json:"supplier,omitempty"`
+	// Item is included when listing the items of a supplier
+	Item *ItemSummary `json:"item,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type SupplierSummary struct {
+	Id   int8   `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,30 @@
+package schemas
+
+type UnitOfMeasure struct {
+	Id        int8   `json:"id"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ItemUnit is a unit an item can be bought, sold or moved in.
+// Factor is the number of base units of the item in one of this unit.
+type ItemUnit struct {
+	Id             int8    `json:"id"`
+	ItemId         int8    `json:"item_id"`
+	UnitCode       string  `json:"unit_code"`
+	Factor         float64 `json:"factor"`
+	IsPurchaseUnit bool    `json:"is_purchase_unit"`
+	IsSalesUnit    bool    `json:"is_sales_unit"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+type UnitConversion struct {
+	ItemId   int8    `json:"item_id"`
+	Quantity float64 `json:"quantity"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Result   float64 `json:"result"`
+}
@@ -0,0 +1,30 @@
+package schemas
+
+const (
+	ValuationMethodFIFO            = "fifo"
+	ValuationMethodWeightedAverage = "weighted_average"
+)
+
+// ItemValuation is the stock value of an item. Quantity is in the base unit of the item,
+// UnitCost is the average cost of one base unit of the remaining stock.
+type ItemValuation struct {
+	ItemId     int8    `json:"item_id"`
+	Name       string  `json:"name"`
+	Sku        *string `json:"sku"`
+	BaseUnit   string  `json:"base_unit"`
+	Quantity   int     `json:"quantity"`
+	UnitCost   float64 `json:"unit_cost"`
+	Value      float64 `json:"value"`
+	ValueMinor int64   `json:"value_minor"`
+}
+
+// ValuationReport is the stock value at the end of AsOf, in Currency
+type ValuationReport struct {
+	AsOf            string          `json:"as_of"`
+	Method          string          `json:"method"`
+	Currency        string          `json:"currency"`
+	Items           []ItemValuation `json:"items"`
+	TotalQuantity   int             `json:"total_quantity"`
+	TotalValue      float64         `json:"total_value"`
+	TotalValueMinor int64           `json:"total_value_minor"`
+}
@@ -0,0 +1,44 @@
+package schemas
+
+import "encoding/json"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends the events of EventTypes to Url, signed with Secret.
+// The secret is only returned when the subscription is created.
+type WebhookSubscription struct {
+	Id          int8     `json:"id"`
+	Url         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	EventTypes  []string `json:"event_types"`
+	Active      bool     `json:"active"`
+	Description *string  `json:"description"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription.
+// Deliveries grow quickly, so their IDs are 64 bit.
+type WebhookDelivery struct {
+	Id             int64           `json:"id"`
+	SubscriptionId int8            `json:"subscription_id"`
+	EventId        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at"`
+	LastAttemptAt  *string         `json:"last_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *string         `json:"delivered_at"`
+	ReplayOf       *int64          `json:"replay_of"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+var digitsRegex = regexp.MustCompile(`^[0-9]+$`)
+
+// skuRegex matches valid SKUs
+var skuRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// NormalizeBarcode removes the whitespace scanners and users tend to add
+func NormalizeBarcode(code string) string {
+	return strings.Join(strings.Fields(code), "")
+}
+
+// DetectSymbology guesses the symbology of a barcode from its format.
+// 12 digits is UPC-A, 13 digits is EAN-13 and everything else is Code128.
+func DetectSymbology(code string) string {
+	if digitsRegex.MatchString(code) {
+		switch len(code) {
+		case 12:
+			return schemas.SymbologyUPCA
+		case 13:
+			return schemas.SymbologyEAN13
+		}
+	}
+	return schemas.SymbologyCode128
+}
+
+// ValidateBarcode checks the length, characters and check digit of the barcode
+func ValidateBarcode(code string, symbology string) error {
+	switch symbology {
+	case schemas.SymbologyEAN13:
+		return validateGTIN(code, 13)
+	case schemas.SymbologyUPCA:
+		return validateGTIN(code, 12)
+	case schemas.SymbologyCode128:
+		if code == "" || len(code) > 80 {
+			return fmt.Errorf("code128 barcodes must be between 1 and 80 characters")
+		}
+		// Code128 can encode the full ASCII range, the check digit is part of the printed symbol
+		for _, char := range code {
+			if char > 127 {
+				return fmt.Errorf("code128 barcodes can only contain ASCII characters")
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown barcode symbology: %s", symbology)
+	}
+}
+
+// validateGTIN validates EAN-13 and UPC-A codes, which share the same check digit algorithm
+func validateGTIN(code string, length int) error {
+	if len(code) != length || !digitsRegex.MatchString(code) {
+		return fmt.Errorf("barcode must be exactly %d digits", length)
+	}
+
+	if GTINCheckDigit(code[:length-1]) != int(code[length-1]-'0') {
+		return fmt.Errorf("barcode %s has an invalid check digit", code)
+	}
+
+	return nil
+}
+
+// GTINCheckDigit calculates the check digit for the digits of a GTIN without its check digit.
+// Digits are weighted 3 and 1 alternating, starting with 3 from the right.
+func GTINCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidateSku checks that the SKU only contains letters, digits, dots, dashes and underscores
+func ValidateSku(sku string) error {
+	if !skuRegex.MatchString(sku) {
+		return fmt.Errorf("sku must be 1-64 characters of letters, digits, dots, dashes and underscores")
+	}
+	return nil
+}
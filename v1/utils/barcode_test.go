@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/MattyMcF4tty/InventoryManager-backend/v1/schemas"
+)
+
+func TestGTINCheckDigit(t *testing.T) {
+	tests := map[string]int{
+		"400638133393": 1,
+		"590123412345": 7,
+		"978030640615": 7,
+		"03600029145":  2,
+		"01234567890":  5,
+		"00000000000":  0,
+	}
+
+	for digits, want := range tests {
+		if got := GTINCheckDigit(digits); got != want {
+			t.Errorf("GTINCheckDigit(%q) = %d, want %d", digits, got, want)
+		}
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		code      string
+		symbology string
+		valid     bool
+	}{
+		{"4006381333931", schemas.SymbologyEAN13, true},
+		{"4006381333932", schemas.SymbologyEAN13, false},
+		{"400638133393", schemas.SymbologyEAN13, false},
+		{"40063813339a1", schemas.SymbologyEAN13, false},
+		{"036000291452", schemas.SymbologyUPCA, true},
+		{"036000291453", schemas.SymbologyUPCA, false},
+		{"0036000291452", schemas.SymbologyUPCA, false},
+		{"ABC-123", schemas.SymbologyCode128, true},
+		{"", schemas.SymbologyCode128, false},
+		{"café", schemas.SymbologyCode128, false},
+		{"4006381333931", "qr", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateBarcode(test.code, test.symbology)
+		if test.valid && err != nil {
+			t.Errorf("ValidateBarcode(%q, %q) returned error: %v", test.code, test.symbology, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("ValidateBarcode(%q, %q) returned no error", test.code, test.symbology)
+		}
+	}
+}
+
+func TestDetectSymbology(t *testing.T) {
+	tests := map[string]string{
+		"4006381333931": schemas.SymbologyEAN13,
+		"036000291452":  schemas.SymbologyUPCA,
+		"12345":         schemas.SymbologyCode128,
+		"ABC-123":       schemas.SymbologyCode128,
+	}
+
+	for code, want := range tests {
+		if got := DetectSymbology(code); got != want {
+			t.Errorf("DetectSymbology(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	CursorNext = "next"
+	CursorPrev = "prev"
+)
+
+// Cursor points at a row in a keyset paginated listing.
+// It is sent to the client as an opaque base64 string.
+type Cursor struct {
+	SortBy    string `json:"s"`
+	Ascending bool   `json:"a"`
+	Value     string `json:"v"`
+	Id        int8   `json:"i"`
+	Direction string `json:"d"`
+}
+
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %s", encoded)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %s", encoded)
+	}
+
+	if cursor.Direction != CursorNext && cursor.Direction != CursorPrev {
+		return Cursor{}, fmt.Errorf("invalid cursor direction: %s", cursor.Direction)
+	}
+
+	return cursor, nil
+}
+
+// KeysetFilter returns a PostgREST "or" filter selecting the rows after (or before)
+// the row with the given sort value and id. The id is used as a tie breaker,
+// so the listing must also be ordered by id.
+func KeysetFilter(column string, value string, id int8, after bool) string {
+	operator := "lt"
+	if after {
+		operator = "gt"
+	}
+
+	if column == "id" {
+		return fmt.Sprintf("id.%s.%d", operator, id)
+	}
+
+	quoted := QuotePostgrestValue(value)
+	return fmt.Sprintf("%s.%s.%s,and(%s.eq.%s,id.%s.%d)", column, operator, quoted, column, quoted, operator, id)
+}
+
+// QuotePostgrestValue quotes a value so it can be used inside PostgREST logical filters,
+// where reserved characters like commas and parentheses would otherwise break the filter
+func QuotePostgrestValue(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{SortBy: "name", Ascending: true, Value: "Bolt, M6 (steel)", Id: 12, Direction: CursorNext},
+		{SortBy: "purchase_price", Ascending: false, Value: "3.5", Id: -1, Direction: CursorPrev},
+		{SortBy: "id", Ascending: true, Id: 127, Direction: CursorNext},
+	}
+
+	for _, cursor := range cursors {
+		encoded := EncodeCursor(cursor)
+		decoded, err := DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) returned error: %v", encoded, err)
+		}
+		if decoded != cursor {
+			t.Fatalf("DecodeCursor(EncodeCursor(%+v)) = %+v", cursor, decoded)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not base64":        "%%%",
+		"not JSON":          base64.RawURLEncoding.EncodeToString([]byte("name")),
+		"unknown direction": base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name","d":"up"}`)),
+		"empty":             "",
+	}
+
+	for name, encoded := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeCursor(encoded); err == nil {
+				t.Fatalf("DecodeCursor(%q) returned no error", encoded)
+			}
+		})
+	}
+}
+
+func TestKeysetFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		value  string
+		id     int8
+		after  bool
+		want   string
+	}{
+		{name: "by id after", column: "id", id: 5, after: true, want: "id.gt.5"},
+		{name: "by id before", column: "id", id: 5, after: false, want: "id.lt.5"},
+		{name: "by name after", column: "name", value: "Bolt", id: 3, after: true,
+			want: `name.gt."Bolt",and(name.eq."Bolt",id.gt.3)`},
+		{name: "by price before", column: "purchase_price", value: "2.5", id: 9, after: false,
+			want: `purchase_price.lt."2.5",and(purchase_price.eq."2.5",id.lt.9)`},
+		{name: "reserved characters are quoted", column: "name", value: `a,b) "c" \d`, id: 1, after: true,
+			want: `name.gt."a,b) \"c\" \\d",and(name.eq."a,b) \"c\" \\d",id.gt.1)`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := KeysetFilter(test.column, test.value, test.id, test.after); got != test.want {
+				t.Fatalf("KeysetFilter() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
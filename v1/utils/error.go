@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"regexp"
@@ -19,42 +20,207 @@ func IsCustomError(err error) bool {
 	return false
 }
 
-func PostgresToHTTPError(err error) *int {
-	// Check if the error is a Postgres error
-	if strings.Contains(err.Error(), "PGRST") {
-		// Extract the error code from the error message
-		regex := regexp.MustCompile(`\(([^)]+)\)`)
-		codeMatches := regex.FindStringSubmatch(err.Error())
-		var errCode string
-		if len(codeMatches) > 1 {
-			errCode = codeMatches[1]
-		}
+// PostgresError is the error body returned by PostgREST.
+// The postgrest client only keeps the code and message, so Details and Hint are only filled when the raw body
+// is available (e.g. from RPC calls). Details can contain the values of the row, so it is only logged.
+type PostgresError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details"`
+	Hint    string `json:"hint"`
+}
+
+// postgrestErrorRegex matches the "(code) message" format used by the postgrest client
+var postgrestErrorRegex = regexp.MustCompile(`^\(([0-9A-Z]+)\)\s*(.*)$`)
+
+// sqlStateRegex matches a five character SQLSTATE code
+var sqlStateRegex = regexp.MustCompile(`^[0-9A-Z]{5}$`)
+
+// pgrstCodeRegex matches a PGRST code anywhere in an error message
+var pgrstCodeRegex = regexp.MustCompile(`\((PGRST[0-9]+)\)`)
 
-		// The code handling is based of the PostgREST documentation
-		// https://docs.postgrest.org/en/v12/references/errors.html
+// The code handling is based of the PostgREST documentation
+// https://docs.postgrest.org/en/v12/references/errors.html
 
-		postgresErrorMap := map[string]int{
-			"PGRST100": http.StatusBadRequest,
-			"PGRST102": http.StatusBadRequest,
-			"PGRST108": http.StatusBadRequest,
-			"PGRST116": http.StatusNotFound,
-			"PGRST121": http.StatusNotFound,
+// postgrestErrorMap maps PostgREST's own PGRST error codes to HTTP status codes
+var postgrestErrorMap = map[string]int{
+	"PGRST000": http.StatusServiceUnavailable,
+	"PGRST001": http.StatusServiceUnavailable,
+	"PGRST002": http.StatusServiceUnavailable,
+	"PGRST003": http.StatusGatewayTimeout,
+	"PGRST100": http.StatusBadRequest,
+	"PGRST101": http.StatusMethodNotAllowed,
+	"PGRST102": http.StatusBadRequest,
+	"PGRST103": http.StatusRequestedRangeNotSatisfiable,
+	"PGRST105": http.StatusMethodNotAllowed,
+	"PGRST106": http.StatusNotAcceptable,
+	"PGRST107": http.StatusUnsupportedMediaType,
+	"PGRST108": http.StatusBadRequest,
+	"PGRST111": http.StatusInternalServerError,
+	"PGRST112": http.StatusInternalServerError,
+	"PGRST114": http.StatusBadRequest,
+	"PGRST115": http.StatusBadRequest,
+	"PGRST116": http.StatusNotFound,
+	"PGRST117": http.StatusMethodNotAllowed,
+	"PGRST118": http.StatusBadRequest,
+	"PGRST120": http.StatusBadRequest,
+	"PGRST121": http.StatusNotFound,
+	"PGRST122": http.StatusBadRequest,
+	"PGRST200": http.StatusBadRequest,
+	"PGRST201": http.StatusMultipleChoices,
+	"PGRST202": http.StatusNotFound,
+	"PGRST203": http.StatusMultipleChoices,
+	"PGRST204": http.StatusBadRequest,
+	"PGRST205": http.StatusNotFound,
+	"PGRST300": http.StatusInternalServerError,
+	"PGRST301": http.StatusUnauthorized,
+	"PGRST302": http.StatusUnauthorized,
+}
+
+// sqlStateErrorMap maps specific Postgres SQLSTATE codes to HTTP status codes
+var sqlStateErrorMap = map[string]int{
+	"23502": http.StatusBadRequest,
+	"23503": http.StatusUnprocessableEntity,
+	"23505": http.StatusConflict,
+	"23514": http.StatusUnprocessableEntity,
+	"23P01": http.StatusConflict,
+	"25006": http.StatusMethodNotAllowed,
+	"42501": http.StatusForbidden,
+	"42703": http.StatusBadRequest,
+	"42883": http.StatusNotFound,
+	"42P01": http.StatusNotFound,
+	"P0001": http.StatusBadRequest,
+	"P0002": http.StatusNotFound,
+}
+
+// sqlStateClassMap maps Postgres SQLSTATE classes (the first two characters) to HTTP status codes
+var sqlStateClassMap = map[string]int{
+	"08": http.StatusServiceUnavailable,
+	"0L": http.StatusForbidden,
+	"0P": http.StatusForbidden,
+	"22": http.StatusBadRequest,
+	"23": http.StatusConflict,
+	"28": http.StatusForbidden,
+	"40": http.StatusConflict,
+	"42": http.StatusBadRequest,
+	"53": http.StatusServiceUnavailable,
+	"54": http.StatusRequestEntityTooLarge,
+	"57": http.StatusServiceUnavailable,
+}
+
+// sqlStateMessageMap contains user friendly messages for common SQLSTATE codes
+var sqlStateMessageMap = map[string]string{
+	"22001":    "A value is too long for its field",
+	"22003":    "A numeric value is out of range",
+	"22007":    "Invalid date or time format",
+	"22P02":    "A value has an invalid format",
+	"23502":    "A required field is missing",
+	"23503":    "The operation references a related record that does not exist or is still in use",
+	"23505":    "A record with the same unique value already exists",
+	"23514":    "A value does not satisfy the allowed constraints",
+	"23P01":    "The record conflicts with an existing record",
+	"40001":    "The operation conflicted with another operation, please try again",
+	"40P01":    "The operation conflicted with another operation, please try again",
+	"42501":    "Insufficient permissions for this operation",
+	"42703":    "Unknown field in request",
+	"PGRST103": "Requested range is not satisfiable",
+	"PGRST204": "Unknown field in request",
+}
+
+// ParsePostgresError extracts the PostgREST error from an error returned by the postgrest client.
+// Both the "(code) message" format of the client and raw JSON error bodies are supported.
+// Returns nil if the error is not a PostgREST error.
+func ParsePostgresError(err error) *PostgresError {
+	if err == nil {
+		return nil
+	}
+	errStr := strings.TrimSpace(err.Error())
+
+	// Raw JSON error body, as returned by RPC calls
+	if strings.HasPrefix(errStr, "{") {
+		var pgErr PostgresError
+		if json.Unmarshal([]byte(errStr), &pgErr) == nil && pgErr.Code != "" {
+			return &pgErr
 		}
+		return nil
+	}
 
-		// Check if the error code exists in the map
-		if errCode != "" {
-			if status, exists := postgresErrorMap[errCode]; exists {
-				return &status
-			} else {
-				slog.Error("Postgres error code not found in map", "error_code", errCode, "error", err.Error())
+	matches := postgrestErrorRegex.FindStringSubmatch(errStr)
+	if len(matches) < 3 {
+		// Fall back to the old behaviour of looking for a PGRST code anywhere in the message
+		if strings.Contains(errStr, "PGRST") {
+			codeMatches := pgrstCodeRegex.FindStringSubmatch(errStr)
+			if len(codeMatches) > 1 {
+				return &PostgresError{Code: codeMatches[1], Message: errStr}
 			}
-		} else {
-			slog.Error("Postgres error code not found in postgres error message", "error", err.Error())
+			return &PostgresError{Message: errStr}
 		}
+		return nil
+	}
 
-		status := http.StatusInternalServerError
-		return &status
+	code := matches[1]
+	if !strings.HasPrefix(code, "PGRST") && !sqlStateRegex.MatchString(code) {
+		return nil
 	}
 
-	return nil
+	return &PostgresError{
+		Code:    code,
+		Message: matches[2],
+	}
+}
+
+// Status returns the HTTP status code matching the Postgres error
+func (e *PostgresError) Status() int {
+	if strings.HasPrefix(e.Code, "PGRST") {
+		if status, exists := postgrestErrorMap[e.Code]; exists {
+			return status
+		}
+		slog.Error("Postgres error code not found in map", "error_code", e.Code, "error", e.Message)
+		return http.StatusInternalServerError
+	}
+
+	if status, exists := sqlStateErrorMap[e.Code]; exists {
+		return status
+	}
+
+	if len(e.Code) == 5 {
+		if status, exists := sqlStateClassMap[e.Code[:2]]; exists {
+			return status
+		}
+		slog.Error("Postgres error code not found in map", "error_code", e.Code, "error", e.Message)
+	} else {
+		slog.Error("Postgres error code not found in postgres error message", "error", e.Message)
+	}
+
+	return http.StatusInternalServerError
+}
+
+// UserMessage returns a message that is safe to show to the user, or an empty string
+// if there is no specific message for the error code
+func (e *PostgresError) UserMessage() string {
+	return sqlStateMessageMap[e.Code]
+}
+
+func PostgresToHTTPError(err error) *int {
+	pgErr := ParsePostgresError(err)
+	if pgErr == nil {
+		return nil
+	}
+
+	status := pgErr.Status()
+	return &status
+}
+
+// PostgresErrorMessage returns a user friendly message for the Postgres error,
+// or defaultMessage if there is none
+func PostgresErrorMessage(err error, defaultMessage string) string {
+	pgErr := ParsePostgresError(err)
+	if pgErr == nil {
+		return defaultMessage
+	}
+
+	if message := pgErr.UserMessage(); message != "" {
+		return message
+	}
+	return defaultMessage
 }
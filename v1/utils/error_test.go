@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestParsePostgresError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    *PostgresError
+		wantNil bool
+	}{
+		{name: "nil", err: nil, wantNil: true},
+		{name: "not a postgres error", err: errors.New("connection refused"), wantNil: true},
+		{name: "client format with SQLSTATE", err: errors.New("(23505) duplicate key value violates unique constraint"),
+			want: &PostgresError{Code: "23505", Message: "duplicate key value violates unique constraint"}},
+		{name: "client format with PGRST code", err: errors.New("(PGRST116) JSON object requested, multiple (or no) rows returned"),
+			want: &PostgresError{Code: "PGRST116", Message: "JSON object requested, multiple (or no) rows returned"}},
+		{name: "client format with invalid code", err: errors.New("(abc) something"), wantNil: true},
+		{name: "raw JSON body", err: errors.New(`{"code":"23503","message":"violates foreign key","details":"Key (item_id)=(5) is still referenced"}`),
+			want: &PostgresError{Code: "23503", Message: "violates foreign key", Details: "Key (item_id)=(5) is still referenced"}},
+		{name: "raw JSON body with hint", err: errors.New(`{"code":"42883","message":"function search_items() does not exist","details":null,"hint":"No function matches the given name and argument types."}`),
+			want: &PostgresError{Code: "42883", Message: "function search_items() does not exist", Hint: "No function matches the given name and argument types."}},
+		{name: "raw JSON body without code", err: errors.New(`{"message":"no code"}`), wantNil: true},
+		{name: "PGRST code inside the message", err: errors.New("request failed: (PGRST301) JWT expired"),
+			want: &PostgresError{Code: "PGRST301", Message: "request failed: (PGRST301) JWT expired"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ParsePostgresError(test.err)
+			if test.wantNil {
+				if got != nil {
+					t.Fatalf("ParsePostgresError() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *test.want {
+				t.Fatalf("ParsePostgresError() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPostgresErrorStatus(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{code: "PGRST116", want: http.StatusNotFound},
+		{code: "PGRST301", want: http.StatusUnauthorized},
+		{code: "PGRST999", want: http.StatusInternalServerError},
+		{code: "23505", want: http.StatusConflict},
+		{code: "23503", want: http.StatusUnprocessableEntity},
+		{code: "P0002", want: http.StatusNotFound},
+		// Codes without their own entry use their class
+		{code: "22012", want: http.StatusBadRequest},
+		{code: "40001", want: http.StatusConflict},
+		{code: "53300", want: http.StatusServiceUnavailable},
+		{code: "XX000", want: http.StatusInternalServerError},
+		{code: "", want: http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			pgErr := &PostgresError{Code: test.code}
+			if got := pgErr.Status(); got != test.want {
+				t.Fatalf("Status() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPostgresErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "not a postgres error", err: errors.New("boom"), want: "default"},
+		{name: "mapped code", err: errors.New("(23505) duplicate key"), want: "A record with the same unique value already exists"},
+		{name: "unmapped code", err: errors.New("(XX000) internal"), want: "default"},
+		{name: "details are not shown", err: errors.New(`{"code":"23505","message":"duplicate key","details":"Key (sku)=(SECRET) already exists."}`),
+			want: "A record with the same unique value already exists"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PostgresErrorMessage(test.err, "default"); got != test.want {
+				t.Fatalf("PostgresErrorMessage() = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if status := PostgresToHTTPError(errors.New("boom")); status != nil {
+		t.Fatalf("PostgresToHTTPError() = %d, want nil", *status)
+	}
+}
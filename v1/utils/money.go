@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"math"
+	"os"
+	"strings"
+)
+
+// DefaultBaseCurrency is used when BASE_CURRENCY is not set
+const DefaultBaseCurrency = "EUR"
+
+// GetBaseCurrency returns the currency that reports are converted into
+func GetBaseCurrency() string {
+	if currency := strings.ToUpper(strings.TrimSpace(os.Getenv("BASE_CURRENCY"))); currency != "" {
+		return currency
+	}
+	return DefaultBaseCurrency
+}
+
+// MajorToMinor converts an amount like 12.34 to minor units like 1234, given the number of decimals of the currency
+func MajorToMinor(amount float64, minorUnit int) int64 {
+	return int64(math.Round(amount * math.Pow10(minorUnit)))
+}
+
+// MinorToMajor converts an amount in minor units like 1234 to an amount like 12.34
+func MinorToMajor(amountMinor int64, minorUnit int) float64 {
+	return float64(amountMinor) / math.Pow10(minorUnit)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 
@@ -36,6 +37,24 @@ func CheckRequiredFields(itemMap map[string]interface{}, requiredFields []string
 	return nil
 }
 
+// maxJSONId is the highest ID that a JSON number holds exactly
+const maxJSONId = 1 << 53
+
+// ParseJSONId returns the 64 bit ID in a value decoded from JSON. It is false unless the value is a whole
+// number of at least 1, so an ID is never rounded or truncated to another ID.
+func ParseJSONId(value interface{}) (int64, bool) {
+	id, ok := value.(float64)
+	if !ok || id < 1 || id > maxJSONId || id != math.Trunc(id) {
+		return 0, false
+	}
+	return int64(id), true
+}
+
 func InRange(value, min, max int) bool {
 	return value >= min && value <= max
 }
+
+// GetCurrentISODay returns the current UTC date as YYYY-MM-DD
+func GetCurrentISODay() string {
+	return time.Now().UTC().Format(time.DateOnly)
+}
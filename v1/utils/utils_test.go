@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseJSONId(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  int64
+		valid bool
+	}{
+		{value: float64(1), want: 1, valid: true},
+		{value: float64(300), want: 300, valid: true},
+		{value: float64(1 << 53), want: 1 << 53, valid: true},
+		{value: float64(0), valid: false},
+		{value: float64(-4), valid: false},
+		{value: 2.5, valid: false},
+		{value: float64(1 << 60), valid: false},
+		{value: "12", valid: false},
+		{value: nil, valid: false},
+	}
+
+	for _, test := range tests {
+		got, valid := ParseJSONId(test.value)
+		if valid != test.valid || got != test.want {
+			t.Errorf("ParseJSONId(%v) = %d, %v, want %d, %v", test.value, got, valid, test.want, test.valid)
+		}
+	}
+}